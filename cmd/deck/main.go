@@ -515,7 +515,19 @@ type podLogClient struct {
 }
 
 func (c *podLogClient) GetLogs(name, container string) ([]byte, error) {
-	reader, err := c.client.GetLogs(name, &coreapi.PodLogOptions{Container: container}).Stream(context.TODO())
+	return c.getLogs(name, &coreapi.PodLogOptions{Container: container})
+}
+
+func (c *podLogClient) GetLogsStream(ctx context.Context, name, container string) (stdio.ReadCloser, error) {
+	return c.client.GetLogs(name, &coreapi.PodLogOptions{Container: container}).Stream(ctx)
+}
+
+func (c *podLogClient) GetPreviousLogs(name, container string) ([]byte, error) {
+	return c.getLogs(name, &coreapi.PodLogOptions{Container: container, Previous: true})
+}
+
+func (c *podLogClient) getLogs(name string, opts *coreapi.PodLogOptions) ([]byte, error) {
+	reader, err := c.client.GetLogs(name, opts).Stream(context.TODO())
 	if err != nil {
 		return nil, err
 	}
@@ -81,6 +81,18 @@ type PodLogClient interface {
 	GetLogs(name, container string) ([]byte, error)
 }
 
+// PodLogStreamer is implemented by PodLogClients that can stream pod logs
+// instead of returning them in a single buffer.
+type PodLogStreamer interface {
+	GetLogsStream(ctx context.Context, name, container string) (stdio.ReadCloser, error)
+}
+
+// PreviousPodLogClient is implemented by PodLogClients that can get the logs of
+// the previous instance of a container, e.g. after the container restarted.
+type PreviousPodLogClient interface {
+	GetPreviousLogs(name, container string) ([]byte, error)
+}
+
 // PJListingClient is an interface to list ProwJobs
 type PJListingClient interface {
 	List(context.Context, *prowapi.ProwJobList, ...ctrlruntimeclient.ListOption) error
@@ -243,12 +255,80 @@ func (ja *JobAgent) GetJobLog(job, id string, container string) ([]byte, error)
 		return nil, fmt.Errorf("error getting prowjob: %w", err)
 	}
 	if j.Spec.Agent == prowapi.KubernetesAgent {
-		client, ok := ja.pkcs[j.ClusterAlias()]
-		if !ok {
-			return nil, fmt.Errorf("cannot get logs for prowjob %q with agent %q: unknown cluster alias %q", j.ObjectMeta.Name, j.Spec.Agent, j.ClusterAlias())
+		client, err := ja.podLogClient(j)
+		if err != nil {
+			return nil, err
 		}
 		return client.GetLogs(j.Status.PodName, container)
 	}
+	body, err := ja.getExternalAgentLog(context.Background(), j)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return stdio.ReadAll(body)
+}
+
+// GetJobLogStream returns a reader of the job logs, which are streamed if the
+// agent supports it. The stream is aborted once ctx is done. The caller must
+// close the reader.
+func (ja *JobAgent) GetJobLogStream(ctx context.Context, job, id string, container string) (stdio.ReadCloser, error) {
+	j, err := ja.GetProwJob(job, id)
+	if err != nil {
+		return nil, fmt.Errorf("error getting prowjob: %w", err)
+	}
+	if j.Spec.Agent != prowapi.KubernetesAgent {
+		return ja.getExternalAgentLog(ctx, j)
+	}
+	client, err := ja.podLogClient(j)
+	if err != nil {
+		return nil, err
+	}
+	if streamer, ok := client.(PodLogStreamer); ok {
+		return streamer.GetLogsStream(ctx, j.Status.PodName, container)
+	}
+	log, err := client.GetLogs(j.Status.PodName, container)
+	if err != nil {
+		return nil, err
+	}
+	return stdio.NopCloser(bytes.NewReader(log)), nil
+}
+
+// GetPreviousJobLog returns the logs of the previous instance of the container
+// of a job, e.g. after the container restarted. Only the kubernetes agent
+// keeps the logs of previous containers.
+func (ja *JobAgent) GetPreviousJobLog(job, id string, container string) ([]byte, error) {
+	j, err := ja.GetProwJob(job, id)
+	if err != nil {
+		return nil, fmt.Errorf("error getting prowjob: %w", err)
+	}
+	if j.Spec.Agent != prowapi.KubernetesAgent {
+		return nil, fmt.Errorf("cannot get logs of previous containers for prowjob %q with agent %q", j.ObjectMeta.Name, j.Spec.Agent)
+	}
+	client, err := ja.podLogClient(j)
+	if err != nil {
+		return nil, err
+	}
+	previous, ok := client.(PreviousPodLogClient)
+	if !ok {
+		return nil, fmt.Errorf("cannot get logs of previous containers for prowjob %q: unsupported by the client of cluster alias %q", j.ObjectMeta.Name, j.ClusterAlias())
+	}
+	return previous.GetPreviousLogs(j.Status.PodName, container)
+}
+
+// podLogClient returns the client for the pod logs of the build cluster of a
+// prowjob with the kubernetes agent.
+func (ja *JobAgent) podLogClient(j prowapi.ProwJob) (PodLogClient, error) {
+	client, ok := ja.pkcs[j.ClusterAlias()]
+	if !ok {
+		return nil, fmt.Errorf("cannot get logs for prowjob %q with agent %q: unknown cluster alias %q", j.ObjectMeta.Name, j.Spec.Agent, j.ClusterAlias())
+	}
+	return client, nil
+}
+
+// getExternalAgentLog returns the body of the response with the logs of a
+// prowjob with an external agent. The caller must close the body.
+func (ja *JobAgent) getExternalAgentLog(ctx context.Context, j prowapi.ProwJob) (stdio.ReadCloser, error) {
 	for _, agentToTmpl := range ja.config().Deck.ExternalAgentLogs {
 		if agentToTmpl.Agent != string(j.Spec.Agent) {
 			continue
@@ -260,13 +340,15 @@ func (ja *JobAgent) GetJobLog(job, id string, container string) ([]byte, error)
 		if err := agentToTmpl.URLTemplate.Execute(&b, &j); err != nil {
 			return nil, fmt.Errorf("cannot execute URL template for prowjob %q with agent %q: %w", j.ObjectMeta.Name, j.Spec.Agent, err)
 		}
-		resp, err := http.Get(b.String())
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, b.String(), nil)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create request for the logs of prowjob %q with agent %q: %w", j.ObjectMeta.Name, j.Spec.Agent, err)
+		}
+		resp, err := http.DefaultClient.Do(req)
 		if err != nil {
 			return nil, err
 		}
-		defer resp.Body.Close()
-		return stdio.ReadAll(resp.Body)
-
+		return resp.Body, nil
 	}
 	return nil, fmt.Errorf("cannot get logs for prowjob %q with agent %q: the agent is missing from the prow config file", j.ObjectMeta.Name, j.Spec.Agent)
 }
@@ -17,9 +17,11 @@ limitations under the License.
 package jobs
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"sort"
 	"testing"
 	"time"
@@ -108,6 +110,114 @@ func TestGetLog(t *testing.T) {
 	}
 }
 
+// fspkc streams pod logs and gets the logs of previous containers.
+type fspkc struct {
+	fpkc
+	streamed bool
+}
+
+func (f *fspkc) GetLogsStream(_ context.Context, name, container string) (io.ReadCloser, error) {
+	log, err := f.GetLogs(name, container)
+	if err != nil {
+		return nil, err
+	}
+	f.streamed = true
+	return io.NopCloser(bytes.NewReader(log)), nil
+}
+
+func (f *fspkc) GetPreviousLogs(name, container string) ([]byte, error) {
+	log, err := f.GetLogs(name, container)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte("previous."), log...), nil
+}
+
+func TestGetLogStreamAndPrevious(t *testing.T) {
+	kc := fkc{
+		prowapi.ProwJob{
+			Spec: prowapi.ProwJobSpec{
+				Agent: prowapi.KubernetesAgent,
+				Job:   "job",
+			},
+			Status: prowapi.ProwJobStatus{
+				PodName: "wowowow",
+				BuildID: "123",
+			},
+		},
+		prowapi.ProwJob{
+			Spec: prowapi.ProwJobSpec{
+				Agent:   prowapi.KubernetesAgent,
+				Job:     "jib",
+				Cluster: "trusted",
+			},
+			Status: prowapi.ProwJobStatus{
+				PodName: "powowow",
+				BuildID: "123",
+			},
+		},
+	}
+	streaming := &fspkc{fpkc: fpkc("clusterA")}
+	ja := &JobAgent{
+		kc:   kc,
+		pkcs: map[string]PodLogClient{kube.DefaultClusterAlias: streaming, "trusted": fpkc("clusterB")},
+	}
+	if err := ja.update(); err != nil {
+		t.Fatalf("Updating: %v", err)
+	}
+
+	testCases := []struct {
+		name     string
+		job      string
+		expected string
+		streamed bool
+	}{
+		{
+			name:     "client that streams",
+			job:      "job",
+			expected: fmt.Sprintf("clusterA.%s", kube.TestContainerName),
+			streamed: true,
+		},
+		{
+			name:     "client that does not stream",
+			job:      "jib",
+			expected: fmt.Sprintf("clusterB.%s", kube.TestContainerName),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			streaming.streamed = false
+			rc, err := ja.GetJobLogStream(context.Background(), tc.job, "123", kube.TestContainerName)
+			if err != nil {
+				t.Fatalf("Failed to get log stream: %v", err)
+			}
+			defer rc.Close()
+			res, err := io.ReadAll(rc)
+			if err != nil {
+				t.Fatalf("Failed to read log stream: %v", err)
+			}
+			if got := string(res); got != tc.expected {
+				t.Errorf("Expected %q, but got %q.", tc.expected, got)
+			}
+			if streaming.streamed != tc.streamed {
+				t.Errorf("Expected streamed: %t, but got %t.", tc.streamed, streaming.streamed)
+			}
+		})
+	}
+
+	if res, err := ja.GetPreviousJobLog("job", "123", kube.TestContainerName); err != nil {
+		t.Fatalf("Failed to get previous log: %v", err)
+	} else if got, expect := string(res), fmt.Sprintf("previous.clusterA.%s", kube.TestContainerName); got != expect {
+		t.Errorf("Unexpected previous log. Expected %q, but got %q.", expect, got)
+	}
+	if _, err := ja.GetPreviousJobLog("jib", "123", kube.TestContainerName); err == nil {
+		t.Error("Expected an error getting the previous log from a client that does not support it.")
+	}
+	if _, err := ja.GetJobLogStream(context.Background(), "missing", "123", kube.TestContainerName); !IsErrProwJobNotFound(errors.Unwrap(err)) {
+		t.Errorf("Expected a missing prowjob error, but got %v.", err)
+	}
+}
+
 func TestProwJobs(t *testing.T) {
 	kc := fkc{
 		prowapi.ProwJob{
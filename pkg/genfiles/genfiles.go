@@ -68,6 +68,8 @@ type Group struct {
 
 // NewGroup reads the .generated_files file in the root of the repository
 // and any referenced path files (from "path-from-repo" commands).
+// If the file contains an invalid line, the group of the statements before
+// that line is returned along with a *ParseError.
 func NewGroup(gc ghFileClient, owner, repo, sha string) (*Group, error) {
 	g := &Group{
 		Paths:        make(map[string]bool),
@@ -86,9 +88,11 @@ func NewGroup(gc ghFileClient, owner, repo, sha string) (*Group, error) {
 		}
 	}
 
-	repoFiles, err := g.load(bytes.NewBuffer(bs))
-	if err != nil {
-		return nil, err
+	repoFiles, parseErr := g.load(bytes.NewBuffer(bs))
+	if parseErr != nil {
+		if _, ok := parseErr.(*ParseError); !ok {
+			return nil, parseErr
+		}
 	}
 	for _, f := range repoFiles {
 		bs, err = gc.GetFile(owner, repo, f, sha)
@@ -100,7 +104,7 @@ func NewGroup(gc ghFileClient, owner, repo, sha string) (*Group, error) {
 		}
 	}
 
-	return g, nil
+	return g, parseErr
 }
 
 // Use load to read a generated files config file, and populate g with the commands.
@@ -152,6 +152,7 @@ type CommitClient interface {
 	CreateStatusWithContext(ctx context.Context, org, repo, SHA string, s Status) error
 	ListStatuses(org, repo, ref string) ([]Status, error)
 	GetSingleCommit(org, repo, SHA string) (RepositoryCommit, error)
+	GetMergeBase(org, repo, base, head string) (string, error)
 	GetCombinedStatus(org, repo, ref string) (*CombinedStatus, error)
 	ListCheckRuns(org, repo, ref string) (*CheckRunList, error)
 	GetRef(org, repo, ref string) (string, error)
@@ -2575,6 +2576,33 @@ func (c *client) GetSingleCommit(org, repo, SHA string) (RepositoryCommit, error
 	return commit, err
 }
 
+// GetMergeBase returns the SHA of the merge base of the base and head
+// commits, i.e. the commit that the changes of head are relative to.
+//
+// See https://docs.github.com/en/rest/commits/commits#compare-two-commits
+func (c *client) GetMergeBase(org, repo, base, head string) (string, error) {
+	durationLogger := c.log("GetMergeBase", org, repo, base, head)
+	defer durationLogger()
+
+	var comparison struct {
+		MergeBaseCommit RepositoryCommit `json:"merge_base_commit"`
+	}
+	_, err := c.request(&request{
+		method: http.MethodGet,
+		// Only the merge base is needed, so the list of commits is kept short.
+		path:      fmt.Sprintf("/repos/%s/%s/compare/%s...%s?per_page=1", org, repo, base, head),
+		org:       org,
+		exitCodes: []int{200},
+	}, &comparison)
+	if err != nil {
+		return "", err
+	}
+	if comparison.MergeBaseCommit.SHA == "" {
+		return "", fmt.Errorf("no merge base of %s and %s in %s/%s", base, head, org, repo)
+	}
+	return comparison.MergeBaseCommit.SHA, nil
+}
+
 // GetBranches returns all branches in the repo.
 //
 // If onlyProtected is true it will only return repos with protection enabled,
@@ -762,6 +762,26 @@ func TestGetSingleCommit(t *testing.T) {
 	}
 }
 
+func TestGetMergeBase(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("Bad method: %s", r.Method)
+		}
+		if r.URL.Path != "/repos/octocat/Hello-World/compare/abcd...efgh" {
+			t.Errorf("Bad request path: %s", r.URL.Path)
+		}
+		fmt.Fprint(w, `{"merge_base_commit": {"sha": "1234"}, "commits": []}`)
+	}))
+	defer ts.Close()
+	c := getClient(ts.URL)
+	mergeBase, err := c.GetMergeBase("octocat", "Hello-World", "abcd", "efgh")
+	if err != nil {
+		t.Errorf("Didn't expect error: %v", err)
+	} else if mergeBase != "1234" {
+		t.Errorf("Wrong merge base: %s", mergeBase)
+	}
+}
+
 func TestCreateStatus(t *testing.T) {
 	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
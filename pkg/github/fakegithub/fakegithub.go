@@ -60,6 +60,9 @@ type FakeClient struct {
 	CreatedStatuses            map[string][]github.Status
 	IssueEvents                map[int][]github.ListedIssueEvent
 	Commits                    map[string]github.RepositoryCommit
+	// MergeBases maps "base...head" to the merge base of the commits. The
+	// merge base of unlisted commits is base.
+	MergeBases map[string]string
 
 	// All Labels That Exist In The Repo
 	RepoLabelsExisting []string
@@ -504,6 +507,16 @@ func (f *FakeClient) GetSingleCommit(org, repo, SHA string) (github.RepositoryCo
 	return f.Commits[SHA], nil
 }
 
+// GetMergeBase returns the merge base of the base and head commits.
+func (f *FakeClient) GetMergeBase(org, repo, base, head string) (string, error) {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+	if mergeBase, ok := f.MergeBases[base+"..."+head]; ok {
+		return mergeBase, nil
+	}
+	return base, nil
+}
+
 // CreateStatus adds a status context to a commit.
 func (f *FakeClient) CreateStatus(owner, repo, SHA string, s github.Status) error {
 	return f.CreateStatusWithContext(context.Background(), owner, repo, SHA, s)
@@ -762,10 +762,22 @@ type IssueEvent struct {
 	Label  Label `json:"label"`
 	Sender User  `json:"sender"`
 
+	// Changes holds raw change data, which we must inspect
+	// and deserialize later as this is a polymorphic field
+	Changes json.RawMessage `json:"changes"`
+
 	// GUID is included in the header of the request received by GitHub.
 	GUID string
 }
 
+// IssueTransferChanges holds the change data of IssueActionTransferred events.
+type IssueTransferChanges struct {
+	// NewIssue is the issue in the repo it was transferred to.
+	NewIssue Issue `json:"new_issue"`
+	// NewRepo is the repo the issue was transferred to.
+	NewRepo Repo `json:"new_repository"`
+}
+
 // ListedIssueEvent represents an issue event from the events API (not from a webhook payload).
 // https://developer.github.com/v3/issues/events/
 type ListedIssueEvent struct {
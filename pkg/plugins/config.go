@@ -91,6 +91,7 @@ type Configuration struct {
 	Slack                Slack                        `json:"slack,omitempty"`
 	SigMention           SigMention                   `json:"sigmention,omitempty"`
 	Size                 Size                         `json:"size,omitempty"`
+	SizeOverrides        map[string]Size              `json:"size_overrides,omitempty"`
 	Triggers             []Trigger                    `json:"triggers,omitempty"`
 	Welcome              []Welcome                    `json:"welcome,omitempty"`
 	Override             Override                     `json:"override,omitempty"`
@@ -277,12 +278,275 @@ type SigMention struct {
 
 // Size specifies configuration for the size plugin, defining lower bounds (in # lines changed) for each size label.
 // XS is assumed to be zero.
+// Orgs and repos can override individual fields in size_overrides, which is a
+// map of orgs or repos (eg "k/k") to the fields they override.
 type Size struct {
 	S   int `json:"s"`
 	M   int `json:"m"`
 	L   int `json:"l"`
 	Xl  int `json:"xl"`
 	Xxl int `json:"xxl"`
+
+	// StatusContext is the context of a commit status reported with the size
+	// of the PR in its description. The status is purely informational and
+	// always succeeds unless StatusFailXXL is set.
+	// This field is optional. If unspecified, no status is reported.
+	StatusContext string `json:"status_context,omitempty"`
+	// StatusFailXXL makes the status reported for StatusContext fail for
+	// size/XXL PRs, so that branch protection can require PRs to be split.
+	StatusFailXXL *bool `json:"status_fail_xxl,omitempty"`
+
+	// CheckRunName is the name of a check run created on the head of every
+	// sized PR, with annotations on its largest changed files by counted
+	// lines, which helps reviewers prioritize. Check runs can only be created
+	// when authenticating as a GitHub App.
+	// This field is optional. If unspecified, no check run is created.
+	CheckRunName string `json:"check_run_name,omitempty"`
+	// CheckRunAnnotations is the number of largest files annotated in the
+	// check run of CheckRunName, which is at most 50.
+	// Defaults to 5.
+	CheckRunAnnotations int `json:"check_run_annotations,omitempty"`
+
+	// MaxFileLines caps the number of changed lines counted for any single file.
+	// This field is optional. If unspecified, the lines of a file are not capped.
+	MaxFileLines int `json:"max_file_lines,omitempty"`
+	// MaxFilePercent caps the number of changed lines counted for any single file
+	// at this percentage of the total number of changed lines, so that a single
+	// large file does not dominate the size of the PR. The cap is never below
+	// an even share of the total, e.g. 50% for a PR with two counted files, so
+	// that PRs with few files, in particular with a single file, are not shrunk.
+	// This field is optional. If unspecified, the lines of a file are not capped.
+	MaxFilePercent int `json:"max_file_percent,omitempty"`
+
+	// ForceXXLGlobs are globs matching the paths of files that always demand a
+	// thorough review. If a PR changes any matching file, it is labeled
+	// size/XXL regardless of the number of changed lines.
+	// This field is optional.
+	ForceXXLGlobs []string `json:"force_xxl_globs,omitempty"`
+	// ForceXXLComment is the comment to post when a PR is labeled size/XXL
+	// because it changes files matching ForceXXLGlobs. The matching files are
+	// listed below the comment.
+	// This field is optional. If unspecified, no comment is created.
+	ForceXXLComment string `json:"force_xxl_comment,omitempty"`
+
+	// GoSemantic enables counting the changed top-level declarations of Go
+	// files instead of their changed lines, i.e. every added, removed or
+	// modified func, type, const or var counts as a single changed line.
+	// This requires fetching and parsing the changed Go files at the merge base
+	// and head of the PR. Go files that cannot be parsed and other files are
+	// still counted by their changed lines.
+	// This field is optional. If unspecified, all files are counted by lines.
+	GoSemantic *bool `json:"go_semantic,omitempty"`
+
+	// CommentMinAge is the minimum age of a PR before comments are posted on
+	// it, e.g. '10m'. Comments on younger PRs are deferred until an event is
+	// received for the PR after it reached this age, so that transient changes
+	// while a PR is actively pushed to do not spam. Labels are not deferred.
+	// This field is optional. If unspecified, comments are not deferred.
+	CommentMinAge         string        `json:"comment_min_age,omitempty"`
+	CommentMinAgeDuration time.Duration `json:"-"`
+
+	// GeneratedLabel is a label, e.g. 'generated/large', that is applied in
+	// addition to the size label if the changed lines of generated files, which
+	// are not counted for the size label, exceed GeneratedLabelThreshold.
+	// This field is optional. If unspecified, no such label is applied.
+	GeneratedLabel string `json:"generated_label,omitempty"`
+	// GeneratedLabelThreshold is the number of changed lines of generated
+	// files that must be exceeded for GeneratedLabel to be applied.
+	GeneratedLabelThreshold int `json:"generated_label_threshold,omitempty"`
+
+	// EffortLabels maps labels to the number of size classes that PRs with
+	// the label are bumped up after they are bucketed by their changed lines,
+	// e.g. 'area/security: 1' labels a PR that would be size/M as size/L, as
+	// changes to some areas need more scrutiny. The bumps of several labels
+	// add up, but never exceed size/XXL.
+	// This field is optional.
+	EffortLabels map[string]int `json:"effort_labels,omitempty"`
+
+	// IgnoreFile is the path of a file in the repo, e.g. '.size-ignore', that
+	// lists the files that are not counted for the size of PRs in gitignore
+	// syntax, e.g. paths with little review value. The file is read at the
+	// base of the PR, so that PRs cannot exclude their own changes.
+	// This field is optional. If unspecified, no such file is read.
+	IgnoreFile string `json:"ignore_file,omitempty"`
+
+	// StackedPRs enables counting the changes of stacked PRs relative to the
+	// head of the PR they are stacked on, rather than the branch both target,
+	// so that the size reflects only the incremental change. A PR references
+	// the PR it is stacked on with a line like 'Stacked on #123' or
+	// 'Depends on: #123' in its description. The parent is only considered
+	// while it is open and targets the same branch.
+	// This field is optional. If unspecified, all changes are counted
+	// relative to the target branch.
+	StackedPRs *bool `json:"stacked_prs,omitempty"`
+
+	// HistoryWindow enables sizing PRs relative to the norms of their repo
+	// rather than by the absolute thresholds: PRs are labeled
+	// 'size/below-normal', 'size/normal' or 'size/above-normal' if they change
+	// less than half, up to twice or more than twice the median number of
+	// changed lines of the last HistoryWindow merged PRs of the repo.
+	// The history is kept in memory, so the absolute thresholds apply until
+	// at least half of the window was merged, e.g. after a restart. They also
+	// apply to PRs whose size is forced, and EffortLabels are not applied to
+	// relative labels.
+	// This field is optional. If unspecified, the absolute thresholds apply.
+	HistoryWindow int `json:"history_window,omitempty"`
+
+	// Summary enables emitting a JSON summary of the size computation of every
+	// PR that is labeled, with the counted lines and skip reason of every
+	// changed file, the totals and the resulting label, so that data
+	// pipelines can analyze size decisions. The summary is passed to the
+	// summary sink of the plugin, which logs it in the 'size_summary' field
+	// unless the binary running the plugin uploads it elsewhere.
+	// This field is optional. If unspecified, no summary is emitted.
+	Summary *bool `json:"summary,omitempty"`
+
+	// SkipAuthors are the logins of bots opening automated PRs, e.g.
+	// 'dependabot[bot]' or 'renovate[bot]', whose size is uninformative.
+	// Their PRs are not sized, any size label is removed from them, and they
+	// are not recorded for the HistoryWindow.
+	// This field is optional.
+	SkipAuthors []string `json:"skip_authors,omitempty"`
+	// SkipLabels are labels marking automated PRs, e.g. 'dependencies', that
+	// are skipped like the PRs of SkipAuthors.
+	// This field is optional.
+	SkipLabels []string `json:"skip_labels,omitempty"`
+
+	// TestFilePatterns are globs matching the paths of test files, e.g.
+	// '**/*_test.go'. If specified, PRs that change source files but no test
+	// files are bumped up by one size class, as untested changes warrant more
+	// scrutiny. PRs that only change documentation or tests are not bumped.
+	// Like EffortLabels, the bump does not apply to relative labels.
+	// This field is optional. If unspecified, PRs are not bumped.
+	TestFilePatterns []string `json:"test_file_patterns,omitempty"`
+	// SourceFilePatterns are globs matching the paths of source files for
+	// TestFilePatterns, e.g. '**/*.go'. Files that are not counted for the
+	// size, e.g. generated files, are never source files.
+	// This field is optional. If unspecified, all counted files other than
+	// documentation, i.e. Markdown, reStructuredText, AsciiDoc and text
+	// files, are source files.
+	SourceFilePatterns []string `json:"source_file_patterns,omitempty"`
+
+	// TeamPaths is an experimental mapping of the slugs of teams of the org to
+	// globs matching the paths they own, e.g. 'sig-node: [pkg/kubelet/**]'.
+	// If specified, PRs are additionally labeled 'team-size/XS' to
+	// 'team-size/XXL' by the counted lines of the files in the paths owned by
+	// the teams of their author, which is the scope their reviewers care
+	// about. The label is not bumped or forced. PRs by authors in none of the
+	// teams are not labeled.
+	// This field is optional.
+	TeamPaths map[string][]string `json:"team_paths,omitempty"`
+}
+
+// IsStatusFailXXL returns true if the status reported for StatusContext
+// fails for size/XXL PRs.
+func (s Size) IsStatusFailXXL() bool {
+	return s.StatusFailXXL != nil && *s.StatusFailXXL
+}
+
+// IsGoSemantic returns true if Go files are counted by their changed
+// declarations.
+func (s Size) IsGoSemantic() bool {
+	return s.GoSemantic != nil && *s.GoSemantic
+}
+
+// IsStackedPRs returns true if stacked PRs are counted relative to the PR
+// they are stacked on.
+func (s Size) IsStackedPRs() bool {
+	return s.StackedPRs != nil && *s.StackedPRs
+}
+
+// IsSummary returns true if a summary of the size computation is emitted.
+func (s Size) IsSummary() bool {
+	return s.Summary != nil && *s.Summary
+}
+
+// mergeFrom returns a copy of the config with every field that is set in the
+// override replaced by the value of the override.
+func (s Size) mergeFrom(override Size) Size {
+	if override.S != 0 {
+		s.S = override.S
+	}
+	if override.M != 0 {
+		s.M = override.M
+	}
+	if override.L != 0 {
+		s.L = override.L
+	}
+	if override.Xl != 0 {
+		s.Xl = override.Xl
+	}
+	if override.Xxl != 0 {
+		s.Xxl = override.Xxl
+	}
+	if override.StatusContext != "" {
+		s.StatusContext = override.StatusContext
+	}
+	if override.StatusFailXXL != nil {
+		s.StatusFailXXL = override.StatusFailXXL
+	}
+	if override.CheckRunName != "" {
+		s.CheckRunName = override.CheckRunName
+	}
+	if override.CheckRunAnnotations != 0 {
+		s.CheckRunAnnotations = override.CheckRunAnnotations
+	}
+	if override.MaxFileLines != 0 {
+		s.MaxFileLines = override.MaxFileLines
+	}
+	if override.MaxFilePercent != 0 {
+		s.MaxFilePercent = override.MaxFilePercent
+	}
+	if override.ForceXXLGlobs != nil {
+		s.ForceXXLGlobs = override.ForceXXLGlobs
+	}
+	if override.ForceXXLComment != "" {
+		s.ForceXXLComment = override.ForceXXLComment
+	}
+	if override.GoSemantic != nil {
+		s.GoSemantic = override.GoSemantic
+	}
+	if override.CommentMinAge != "" {
+		s.CommentMinAge = override.CommentMinAge
+		s.CommentMinAgeDuration = override.CommentMinAgeDuration
+	}
+	if override.GeneratedLabel != "" {
+		s.GeneratedLabel = override.GeneratedLabel
+	}
+	if override.GeneratedLabelThreshold != 0 {
+		s.GeneratedLabelThreshold = override.GeneratedLabelThreshold
+	}
+	if override.EffortLabels != nil {
+		s.EffortLabels = override.EffortLabels
+	}
+	if override.IgnoreFile != "" {
+		s.IgnoreFile = override.IgnoreFile
+	}
+	if override.StackedPRs != nil {
+		s.StackedPRs = override.StackedPRs
+	}
+	if override.HistoryWindow != 0 {
+		s.HistoryWindow = override.HistoryWindow
+	}
+	if override.Summary != nil {
+		s.Summary = override.Summary
+	}
+	if override.SkipAuthors != nil {
+		s.SkipAuthors = override.SkipAuthors
+	}
+	if override.SkipLabels != nil {
+		s.SkipLabels = override.SkipLabels
+	}
+	if override.TestFilePatterns != nil {
+		s.TestFilePatterns = override.TestFilePatterns
+	}
+	if override.SourceFilePatterns != nil {
+		s.SourceFilePatterns = override.SourceFilePatterns
+	}
+	if override.TeamPaths != nil {
+		s.TeamPaths = override.TeamPaths
+	}
+	return s
 }
 
 // Blockade specifies a configuration for a single blockade.
@@ -742,6 +1006,20 @@ type CherryPickUnapproved struct {
 
 // RequireMatchingLabel is the config for the require-matching-label plugin.
 type RequireMatchingLabel struct {
+	// Name identifies this config so that other configs can inherit from it.
+	// A named config without an Org only serves as a base for other configs
+	// and is not applied itself.
+	Name string `json:"name,omitempty"`
+	// Inherit is the Name of a config whose fields are used for all fields
+	// that are unset in this config. This allows a shared base config, e.g.
+	// in the main plugin config, to be extended by repo specific configs, e.g.
+	// in supplemental plugin configs.
+	Inherit string `json:"inherit,omitempty"`
+	// Enabled allows staging a config without applying it. A disabled config
+	// only removes its MissingLabel from the issues and PRs it applies to.
+	// Defaults to true.
+	Enabled *bool `json:"enabled,omitempty"`
+
 	// Org is the GitHub organization that this config applies to.
 	Org string `json:"org,omitempty"`
 	// Repo is the GitHub repository within Org that this config applies to.
@@ -752,15 +1030,101 @@ type RequireMatchingLabel struct {
 	// config across all branches in the repo or org.
 	Branch string `json:"branch,omitempty"`
 	// PRs is a bool indicating if this config applies to PRs.
-	PRs bool `json:"prs,omitempty"`
+	PRs *bool `json:"prs,omitempty"`
 	// Issues is a bool indicating if this config applies to issues.
-	Issues bool `json:"issues,omitempty"`
+	Issues *bool `json:"issues,omitempty"`
 
 	// Regexp is the string specifying the regular expression used to look for
 	// matching labels.
 	Regexp string `json:"regexp,omitempty"`
 	// Re is the compiled version of Regexp. It should not be specified in config.
 	Re *regexp.Regexp `json:"-"`
+	// RequiredFamily is an explicit list of labels to look for instead of
+	// labels matching Regexp, e.g. the labels of a closed taxonomy like
+	// 'sig/network' and 'sig/node'. The requirement is satisfied if any of
+	// these labels is present.
+	// This field is mutually exclusive with Regexp.
+	RequiredFamily []string `json:"required_family,omitempty"`
+	// Families are regular expressions that each match the labels of a family,
+	// e.g. '^sig/', '^kind/' and '^priority/', for requiring labels of several
+	// families at once. The requirement is satisfied if the labels match at
+	// least MinFamilies distinct families.
+	// This field is mutually exclusive with Regexp and RequiredFamily.
+	Families []string `json:"families,omitempty"`
+	// FamilyRes are the compiled versions of Families. They should not be specified in config.
+	FamilyRes []*regexp.Regexp `json:"-"`
+	// MinFamilies is the minimum number of distinct Families that the labels
+	// must match, e.g. 2 for labels of at least two of the families.
+	// This field is only valid along with Families.
+	// Defaults to the number of Families, i.e. a label of every family is required.
+	MinFamilies *int `json:"min_families,omitempty"`
+	// OrAssignees is an optional list of GitHub logins. The requirement is
+	// considered satisfied if a label matches Regexp OR any of these users is
+	// assigned to the issue or PR.
+	// This field is optional. If unspecified, only labels are considered.
+	OrAssignees []string `json:"or_assignees,omitempty"`
+	// Project is the name of a project of the repo or org, e.g. 'Triage',
+	// that tracks triage instead of labels. The requirement is considered
+	// satisfied if a label matches Regexp OR the issue or PR has a card in
+	// this project. If the project cannot be fetched, only labels are
+	// considered. Changes to the project are only considered once the issue
+	// or PR is re-checked.
+	// This field is optional. If unspecified, projects are not considered.
+	Project string `json:"project,omitempty"`
+	// ProjectColumns restricts the cards that satisfy the requirement to
+	// those in the columns of Project with these names, e.g. 'Triaged'.
+	// This field is only valid along with Project.
+	// This field is optional. If unspecified, cards in any column are considered.
+	ProjectColumns []string `json:"project_columns,omitempty"`
+	// MaxChangedFiles is the maximum number of changed files of a PR, not counting
+	// generated files, for the requirement to be considered satisfied without
+	// a label matching Regexp. This is useful to apply e.g. a 'needs-split'
+	// label to PRs that are too large, unless an override label is present.
+	// This field is only valid if `prs: true` and `issues: false`.
+	// This field is optional. If unspecified, the number of changed files is not considered.
+	MaxChangedFiles *int `json:"max_changed_files,omitempty"`
+	// MaxLabelAge is the maximum time since a label matching Regexp was added
+	// for it to be considered, e.g. '2160h' to require re-triage every release.
+	// The time a label was added is taken from the issue's event history, and
+	// labels without a known addition time are always considered. Labels are
+	// only re-checked when an event is received for the issue or PR.
+	// This field is optional. If unspecified, labels are considered regardless of their age.
+	MaxLabelAge         string        `json:"max_label_age,omitempty"`
+	MaxLabelAgeDuration time.Duration `json:"-"`
+	// ReviewRequests is a bool indicating if the requirement is re-checked when
+	// reviewers are requested for or removed from a PR. This catches PRs that
+	// were not checked at an earlier point in their lifecycle.
+	// This field is only valid if `prs: true`.
+	ReviewRequests *bool `json:"review_requests,omitempty"`
+	// Reviews is a bool indicating if the requirement is re-checked when a
+	// review is submitted for a PR. This ties decision labels like
+	// 'needs-changes' or 'approved' to the review lifecycle.
+	// This field is only valid if `prs: true`.
+	Reviews *bool `json:"reviews,omitempty"`
+	// LinkedIssues is a bool indicating if the labels of the issues that a PR
+	// closes, e.g. with 'Fixes #123' in its description, are also considered
+	// when looking for labels matching Regexp.
+	// This field is only valid if `prs: true`.
+	LinkedIssues *bool `json:"linked_issues,omitempty"`
+	// ParentMarker is the marker that references the parent issue, e.g. an
+	// epic or tracking issue, in the description of an issue or PR, e.g.
+	// 'Parent:' for a line like 'Parent: #123'. The labels of the parent are
+	// also considered when looking for labels matching Regexp, so that
+	// sub-issues inherit the triage of their parent. Changes to the labels of
+	// the parent are only considered once the issue or PR is re-checked.
+	// This field is optional. If unspecified, only the own labels are considered.
+	ParentMarker string `json:"parent_marker,omitempty"`
+	// IgnoredLabelers is an optional list of GitHub logins, typically of
+	// automation, whose label additions are ignored when looking for labels
+	// matching Regexp. The login that added a label is taken from the label
+	// event, or the issue's event history if available.
+	// This field is optional. If unspecified, all labels are considered.
+	IgnoredLabelers []string `json:"ignored_labelers,omitempty"`
+	// LabelAliases maps labels to their aliases, e.g. 'sig-network: sig/network'
+	// while a taxonomy is migrated. A label and its aliases are treated as
+	// equivalent when looking for labels matching Regexp and for MissingLabel.
+	// This field is optional.
+	LabelAliases map[string]string `json:"label_aliases,omitempty"`
 
 	// MissingLabel is the label to apply if an issue does not have any label
 	// matching the Regexp.
@@ -770,6 +1134,64 @@ type RequireMatchingLabel struct {
 	// how to move forward.
 	// This field is optional. If unspecified, no comment is created when labeling.
 	MissingComment string `json:"missing_comment,omitempty"`
+	// SatisfiedComment is the comment to post when we remove the MissingLabel
+	// from an issue because the requirement became satisfied. This is typically
+	// used to confirm the triage to the author.
+	// This field is optional. If unspecified, no comment is created when unlabeling.
+	SatisfiedComment string `json:"satisfied_comment,omitempty"`
+	// RenotifyAfter is the duration of continued non-compliance, e.g. '168h',
+	// after which the MissingComment is posted again as a reminder. It is
+	// measured since the MissingComment was last posted, or since the issue or
+	// PR was created if it is not present. Issues and PRs are only re-checked
+	// when an event is received for them.
+	// This field is only valid along with MissingComment or its overrides.
+	// This field is optional. If unspecified, the MissingComment is posted once.
+	RenotifyAfter         string        `json:"renotify_after,omitempty"`
+	RenotifyAfterDuration time.Duration `json:"-"`
+	// MaxRenotifications is the maximum number of times the MissingComment is
+	// posted again after RenotifyAfter.
+	// Defaults to 1 if RenotifyAfter is specified.
+	MaxRenotifications *int `json:"max_renotifications,omitempty"`
+	// IssueMissingLabel overrides MissingLabel for issues, so that a single
+	// config can apply different labels to issues and PRs.
+	// This field is optional. If unspecified, MissingLabel is applied to issues.
+	IssueMissingLabel string `json:"issue_missing_label,omitempty"`
+	// PRMissingLabel overrides MissingLabel for PRs, so that a single config
+	// can apply different labels to issues and PRs.
+	// This field is optional. If unspecified, MissingLabel is applied to PRs.
+	PRMissingLabel string `json:"pr_missing_label,omitempty"`
+	// Severity is the severity of the requirement, e.g. 'blocking', which
+	// namespaces the applied MissingLabel and its overrides, e.g. 'blocking/needs-sig'
+	// instead of 'needs-sig', so that orgs can encode urgency in their taxonomy of
+	// missing labels. The colors of the namespaced labels can be managed with
+	// label_sync like those of any other label. The label applied with a previous
+	// severity is not removed when the severity is changed.
+	// This field is optional. If unspecified, MissingLabel is applied as is.
+	Severity string `json:"severity,omitempty"`
+	// IssueMissingComment overrides MissingComment for issues.
+	// This field is optional. If unspecified, MissingComment is posted on issues.
+	IssueMissingComment string `json:"issue_missing_comment,omitempty"`
+	// PRMissingComment overrides MissingComment for PRs.
+	// This field is optional. If unspecified, MissingComment is posted on PRs.
+	PRMissingComment string `json:"pr_missing_comment,omitempty"`
+	// OnNoLabels is a label, e.g. 'needs-triage', to apply to issues and PRs
+	// that have no labels at all, regardless of Regexp. It is removed once any
+	// other label is added. MissingLabel does not count as a label for this.
+	// This field is optional. If unspecified, no such label is applied.
+	OnNoLabels string `json:"on_no_labels,omitempty"`
+	// MutuallyExclusive is an optional list of groups of labels of which at
+	// most one may be present at a time, e.g. 'lifecycle/frozen' and
+	// 'lifecycle/rotten'. Issues and PRs that have more than one label of any
+	// group are labeled with ConflictLabel until the contradiction is resolved.
+	MutuallyExclusive [][]string `json:"mutually_exclusive,omitempty"`
+	// ConflictLabel is the label to apply to issues and PRs with more than one
+	// label of any group of MutuallyExclusive.
+	// Defaults to 'needs-label-cleanup' if MutuallyExclusive is specified.
+	ConflictLabel string `json:"conflict_label,omitempty"`
+	// ConflictComment is the comment to post when we add the ConflictLabel,
+	// e.g. to explain which labels contradict each other.
+	// This field is optional. If unspecified, no comment is created.
+	ConflictComment string `json:"conflict_comment,omitempty"`
 
 	// GracePeriod is the amount of time to wait before processing newly opened
 	// or reopened issues and PRs. This delay allows other automation to apply
@@ -779,12 +1201,330 @@ type RequireMatchingLabel struct {
 	GracePeriodDuration time.Duration `json:"-"`
 }
 
+// GetPRs returns true if the config applies to PRs.
+func (r RequireMatchingLabel) GetPRs() bool {
+	return r.PRs != nil && *r.PRs
+}
+
+// GetIssues returns true if the config applies to issues.
+func (r RequireMatchingLabel) GetIssues() bool {
+	return r.Issues != nil && *r.Issues
+}
+
+// GetReviewRequests returns true if the requirement is re-checked when
+// reviewers are requested for or removed from a PR.
+func (r RequireMatchingLabel) GetReviewRequests() bool {
+	return r.ReviewRequests != nil && *r.ReviewRequests
+}
+
+// GetReviews returns true if the requirement is re-checked when a review is
+// submitted for a PR.
+func (r RequireMatchingLabel) GetReviews() bool {
+	return r.Reviews != nil && *r.Reviews
+}
+
+// GetLinkedIssues returns true if the labels of the issues that a PR closes
+// are considered.
+func (r RequireMatchingLabel) GetLinkedIssues() bool {
+	return r.LinkedIssues != nil && *r.LinkedIssues
+}
+
+// GetMinFamilies returns the minimum number of distinct Families that the
+// labels must match, or 0 if unset.
+func (r RequireMatchingLabel) GetMinFamilies() int {
+	if r.MinFamilies == nil {
+		return 0
+	}
+	return *r.MinFamilies
+}
+
+// GetMaxChangedFiles returns the maximum number of changed files of a PR for
+// the requirement to be satisfied without a label, or 0 if unset.
+func (r RequireMatchingLabel) GetMaxChangedFiles() int {
+	if r.MaxChangedFiles == nil {
+		return 0
+	}
+	return *r.MaxChangedFiles
+}
+
+// GetMaxRenotifications returns the maximum number of times the
+// MissingComment is posted again, or 0 if unset.
+func (r RequireMatchingLabel) GetMaxRenotifications() int {
+	if r.MaxRenotifications == nil {
+		return 0
+	}
+	return *r.MaxRenotifications
+}
+
+// IsEnabled returns true unless the config is explicitly disabled.
+func (r RequireMatchingLabel) IsEnabled() bool {
+	if r.Enabled != nil {
+		return *r.Enabled
+	}
+	return true
+}
+
+// equivalentLabels returns the label and its aliases according to LabelAliases.
+func (r RequireMatchingLabel) equivalentLabels(label string) []string {
+	labels := []string{label}
+	for from, to := range r.LabelAliases {
+		if from == label {
+			labels = append(labels, to)
+		} else if to == label {
+			labels = append(labels, from)
+		}
+	}
+	return labels
+}
+
+// Matches returns true if the label or any of its aliases matches Regexp,
+// is in the RequiredFamily or matches any of the Families.
+func (r RequireMatchingLabel) Matches(label string) bool {
+	for _, l := range r.equivalentLabels(label) {
+		if len(r.FamilyRes) > 0 {
+			for _, re := range r.FamilyRes {
+				if re.MatchString(l) {
+					return true
+				}
+			}
+		} else if len(r.RequiredFamily) > 0 {
+			for _, member := range r.RequiredFamily {
+				if l == member {
+					return true
+				}
+			}
+		} else if r.Re.MatchString(l) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchedFamilies returns the number of distinct Families matched by the
+// labels or any of their aliases.
+func (r RequireMatchingLabel) MatchedFamilies(labels []string) int {
+	var matched int
+	for _, re := range r.FamilyRes {
+	labels:
+		for _, label := range labels {
+			for _, l := range r.equivalentLabels(label) {
+				if re.MatchString(l) {
+					matched++
+					break labels
+				}
+			}
+		}
+	}
+	return matched
+}
+
+// IsMissingLabel returns true if the label or any of its aliases is MissingLabel.
+func (r RequireMatchingLabel) IsMissingLabel(label string) bool {
+	for _, l := range r.equivalentLabels(label) {
+		if l == r.MissingLabel {
+			return true
+		}
+	}
+	return false
+}
+
+// ForKind returns a copy of r in which MissingLabel and MissingComment are
+// replaced by the fields specific to PRs or issues, if they are set, and
+// MissingLabel is namespaced by the Severity.
+func (r RequireMatchingLabel) ForKind(isPR bool) RequireMatchingLabel {
+	label, comment := r.IssueMissingLabel, r.IssueMissingComment
+	if isPR {
+		label, comment = r.PRMissingLabel, r.PRMissingComment
+	}
+	if label != "" {
+		r.MissingLabel = label
+	}
+	if comment != "" {
+		r.MissingComment = comment
+	}
+	if r.Severity != "" && r.MissingLabel != "" {
+		r.MissingLabel = r.Severity + "/" + r.MissingLabel
+		// The severity must not be applied again to the copy.
+		r.Severity = ""
+	}
+	return r
+}
+
+// inheritFrom returns a copy of r in which all unset fields are taken from base.
+func (r RequireMatchingLabel) inheritFrom(base RequireMatchingLabel) RequireMatchingLabel {
+	if r.Enabled == nil {
+		r.Enabled = base.Enabled
+	}
+	if r.Org == "" {
+		r.Org = base.Org
+	}
+	if r.Repo == "" {
+		r.Repo = base.Repo
+	}
+	if r.Branch == "" {
+		r.Branch = base.Branch
+	}
+	if r.PRs == nil {
+		r.PRs = base.PRs
+	}
+	if r.Issues == nil {
+		r.Issues = base.Issues
+	}
+	if r.ReviewRequests == nil {
+		r.ReviewRequests = base.ReviewRequests
+	}
+	if r.Reviews == nil {
+		r.Reviews = base.Reviews
+	}
+	if r.LinkedIssues == nil {
+		r.LinkedIssues = base.LinkedIssues
+	}
+	if r.ParentMarker == "" {
+		r.ParentMarker = base.ParentMarker
+	}
+	// Regexp, RequiredFamily and Families are mutually exclusive, so any of
+	// them is only inherited if none is set.
+	if r.Regexp == "" && r.RequiredFamily == nil && r.Families == nil {
+		r.Regexp = base.Regexp
+		r.RequiredFamily = base.RequiredFamily
+		r.Families = base.Families
+	}
+	if r.MinFamilies == nil {
+		r.MinFamilies = base.MinFamilies
+	}
+	if r.OrAssignees == nil {
+		r.OrAssignees = base.OrAssignees
+	}
+	if r.MaxChangedFiles == nil {
+		r.MaxChangedFiles = base.MaxChangedFiles
+	}
+	if r.IgnoredLabelers == nil {
+		r.IgnoredLabelers = base.IgnoredLabelers
+	}
+	if r.LabelAliases == nil {
+		r.LabelAliases = base.LabelAliases
+	}
+	if r.MaxLabelAge == "" {
+		r.MaxLabelAge = base.MaxLabelAge
+	}
+	if r.Project == "" {
+		r.Project = base.Project
+	}
+	if r.ProjectColumns == nil {
+		r.ProjectColumns = base.ProjectColumns
+	}
+	if r.MissingLabel == "" {
+		r.MissingLabel = base.MissingLabel
+	}
+	if r.MissingComment == "" {
+		r.MissingComment = base.MissingComment
+	}
+	if r.SatisfiedComment == "" {
+		r.SatisfiedComment = base.SatisfiedComment
+	}
+	if r.RenotifyAfter == "" {
+		r.RenotifyAfter = base.RenotifyAfter
+	}
+	if r.MaxRenotifications == nil {
+		r.MaxRenotifications = base.MaxRenotifications
+	}
+	if r.IssueMissingLabel == "" {
+		r.IssueMissingLabel = base.IssueMissingLabel
+	}
+	if r.PRMissingLabel == "" {
+		r.PRMissingLabel = base.PRMissingLabel
+	}
+	if r.IssueMissingComment == "" {
+		r.IssueMissingComment = base.IssueMissingComment
+	}
+	if r.PRMissingComment == "" {
+		r.PRMissingComment = base.PRMissingComment
+	}
+	if r.OnNoLabels == "" {
+		r.OnNoLabels = base.OnNoLabels
+	}
+	if r.MutuallyExclusive == nil {
+		r.MutuallyExclusive = base.MutuallyExclusive
+	}
+	if r.ConflictLabel == "" {
+		r.ConflictLabel = base.ConflictLabel
+	}
+	if r.ConflictComment == "" {
+		r.ConflictComment = base.ConflictComment
+	}
+	if r.GracePeriod == "" {
+		r.GracePeriod = base.GracePeriod
+	}
+	if r.Severity == "" {
+		r.Severity = base.Severity
+	}
+	r.Inherit = base.Inherit
+	return r
+}
+
+// resolveRequireMatchingLabelInheritance merges every config that inherits from
+// another config with its (transitive) base configs. Configs that only serve as
+// a base are dropped from the result.
+func resolveRequireMatchingLabelInheritance(rs []RequireMatchingLabel) ([]RequireMatchingLabel, error) {
+	bases := map[string]RequireMatchingLabel{}
+	for _, r := range rs {
+		if r.Name == "" {
+			continue
+		}
+		if _, ok := bases[r.Name]; ok {
+			return nil, fmt.Errorf("duplicate require_matching_label name %q", r.Name)
+		}
+		bases[r.Name] = r
+	}
+
+	var resolved []RequireMatchingLabel
+	for _, r := range rs {
+		seen := sets.New[string]()
+		if r.Name != "" {
+			seen.Insert(r.Name)
+		}
+		for r.Inherit != "" {
+			base, ok := bases[r.Inherit]
+			if !ok {
+				return nil, fmt.Errorf("require_matching_label config inherits from unknown config %q", r.Inherit)
+			}
+			if seen.Has(base.Name) {
+				return nil, fmt.Errorf("require_matching_label config %q inherits from itself", base.Name)
+			}
+			seen.Insert(base.Name)
+			r = r.inheritFrom(base)
+		}
+		if r.Name != "" && r.Org == "" {
+			continue
+		}
+		resolved = append(resolved, r)
+	}
+	return resolved, nil
+}
+
 // validate checks the following properties:
-// - Org, Regexp, MissingLabel, and GracePeriod must be non-empty.
+// - Org and GracePeriod must be non-empty.
+// - Exactly one of Regexp, RequiredFamily and Families must be specified.
+// - RequiredFamily must not contain empty labels and Families must not contain empty regexps.
+// - MinFamilies must be between 1 and the number of Families and only specified along with Families.
+// - MissingLabel must be non-empty, unless overridden for issues and PRs.
 // - Repo does not contain a '/' (should use Org+Repo).
 // - At least one of PRs or Issues must be true.
 // - Branch only specified if 'prs: true'
-// - MissingLabel must not match Regexp.
+// - Severity must not contain '/' or spaces.
+// - MissingLabel and its overrides, namespaced by Severity, must not match Regexp.
+// - Issue and PR overrides only specified if 'issues: true' and 'prs: true' respectively.
+// - OnNoLabels must not match Regexp or be any of the missing labels.
+// - Every group of MutuallyExclusive must contain at least two non-empty labels.
+// - ConflictLabel must not be part of any group of MutuallyExclusive or match Regexp.
+// - ConflictLabel and ConflictComment only specified along with MutuallyExclusive.
+// - RenotifyAfter only specified along with a missing comment.
+// - MaxRenotifications must not be negative and only specified along with RenotifyAfter.
+// - OrAssignees and IgnoredLabelers must not contain empty logins.
+// - ProjectColumns only specified along with Project and must not contain empty names.
+// - LabelAliases must not contain empty labels or map a label to itself.
+// - MaxChangedFiles must not be negative and only specified for PRs.
+// - ReviewRequests, Reviews and LinkedIssues only specified if 'prs: true'.
 func (r RequireMatchingLabel) validate() error {
 	if r.Org == "" {
 		return errors.New("must specify 'org'")
@@ -792,23 +1532,139 @@ func (r RequireMatchingLabel) validate() error {
 	if strings.Contains(r.Repo, "/") {
 		return errors.New("'repo' may not contain '/'; specify the organization with 'org'")
 	}
-	if r.Regexp == "" {
-		return errors.New("must specify 'regexp'")
+	var specified int
+	for _, set := range []bool{r.Regexp != "", len(r.RequiredFamily) > 0, len(r.Families) > 0} {
+		if set {
+			specified++
+		}
 	}
-	if r.MissingLabel == "" {
+	if specified == 0 {
+		return errors.New("must specify 'regexp', 'required_family' or 'families'")
+	}
+	if specified > 1 {
+		return errors.New("'regexp', 'required_family' and 'families' are mutually exclusive")
+	}
+	for _, label := range r.RequiredFamily {
+		if label == "" {
+			return errors.New("'required_family' must not contain empty labels")
+		}
+	}
+	for _, family := range r.Families {
+		if family == "" {
+			return errors.New("'families' must not contain empty regexps")
+		}
+	}
+	if len(r.Families) == 0 && r.GetMinFamilies() != 0 {
+		return errors.New("'min_families' cannot be specified without 'families'")
+	}
+	if len(r.Families) > 0 && (r.GetMinFamilies() < 1 || r.GetMinFamilies() > len(r.Families)) {
+		return fmt.Errorf("'min_families' must be between 1 and the number of 'families' (%d)", len(r.Families))
+	}
+	if (r.GetIssues() && r.ForKind(false).MissingLabel == "") || (r.GetPRs() && r.ForKind(true).MissingLabel == "") {
 		return errors.New("must specify 'missing_label'")
 	}
 	if r.GracePeriod == "" {
 		return errors.New("must specify 'grace_period'")
 	}
-	if !r.PRs && !r.Issues {
+	if !r.GetPRs() && !r.GetIssues() {
 		return errors.New("must specify 'prs: true' and/or 'issues: true'")
 	}
-	if !r.PRs && r.Branch != "" {
+	if !r.GetPRs() && r.Branch != "" {
 		return errors.New("branch cannot be specified without `prs: true'")
 	}
-	if r.Re.MatchString(r.MissingLabel) {
-		return errors.New("'regexp' must not match 'missing_label'")
+	if strings.ContainsAny(r.Severity, "/ ") {
+		return fmt.Errorf("'severity' %q must not contain '/' or spaces", r.Severity)
+	}
+	for _, label := range []string{r.MissingLabel, r.IssueMissingLabel, r.PRMissingLabel} {
+		if label != "" && r.Severity != "" {
+			label = r.Severity + "/" + label
+		}
+		if label != "" && r.Matches(label) {
+			return fmt.Errorf("'regexp' must not match missing label %q", label)
+		}
+	}
+	if r.OnNoLabels != "" {
+		if r.Matches(r.OnNoLabels) {
+			return fmt.Errorf("'regexp' must not match 'on_no_labels' label %q", r.OnNoLabels)
+		}
+		if r.ForKind(false).IsMissingLabel(r.OnNoLabels) || r.ForKind(true).IsMissingLabel(r.OnNoLabels) {
+			return fmt.Errorf("'on_no_labels' label %q must not be a missing label", r.OnNoLabels)
+		}
+	}
+	for _, group := range r.MutuallyExclusive {
+		if len(group) < 2 {
+			return errors.New("every group of 'mutually_exclusive' must contain at least two labels")
+		}
+		for _, label := range group {
+			if label == "" {
+				return errors.New("'mutually_exclusive' must not contain empty labels")
+			}
+			if label == r.ConflictLabel {
+				return fmt.Errorf("'conflict_label' %q must not be part of a group of 'mutually_exclusive'", label)
+			}
+		}
+	}
+	if len(r.MutuallyExclusive) == 0 && (r.ConflictLabel != "" || r.ConflictComment != "") {
+		return errors.New("'conflict_label' and 'conflict_comment' cannot be specified without 'mutually_exclusive'")
+	}
+	if r.ConflictLabel != "" && r.Matches(r.ConflictLabel) {
+		return fmt.Errorf("'regexp' must not match 'conflict_label' %q", r.ConflictLabel)
+	}
+	if r.RenotifyAfter != "" && r.ForKind(false).MissingComment == "" && r.ForKind(true).MissingComment == "" {
+		return errors.New("'renotify_after' cannot be specified without 'missing_comment'")
+	}
+	if r.GetMaxRenotifications() < 0 {
+		return errors.New("'max_renotifications' must not be negative")
+	}
+	if r.RenotifyAfter == "" && r.GetMaxRenotifications() != 0 {
+		return errors.New("'max_renotifications' cannot be specified without 'renotify_after'")
+	}
+	if !r.GetIssues() && (r.IssueMissingLabel != "" || r.IssueMissingComment != "") {
+		return errors.New("'issue_missing_label' and 'issue_missing_comment' cannot be specified without `issues: true'")
+	}
+	if !r.GetPRs() && (r.PRMissingLabel != "" || r.PRMissingComment != "") {
+		return errors.New("'pr_missing_label' and 'pr_missing_comment' cannot be specified without `prs: true'")
+	}
+	for _, assignee := range r.OrAssignees {
+		if assignee == "" {
+			return errors.New("'or_assignees' must not contain empty logins")
+		}
+	}
+	if r.Project == "" && len(r.ProjectColumns) > 0 {
+		return errors.New("'project_columns' cannot be specified without 'project'")
+	}
+	for _, column := range r.ProjectColumns {
+		if column == "" {
+			return errors.New("'project_columns' must not contain empty names")
+		}
+	}
+	for _, labeler := range r.IgnoredLabelers {
+		if labeler == "" {
+			return errors.New("'ignored_labelers' must not contain empty logins")
+		}
+	}
+	for from, to := range r.LabelAliases {
+		if from == "" || to == "" {
+			return errors.New("'label_aliases' must not contain empty labels")
+		}
+		if from == to {
+			return fmt.Errorf("'label_aliases' must not map %q to itself", from)
+		}
+	}
+	if r.GetMaxChangedFiles() < 0 {
+		return errors.New("'max_changed_files' must not be negative")
+	}
+	if r.GetMaxChangedFiles() > 0 && (!r.GetPRs() || r.GetIssues()) {
+		return errors.New("'max_changed_files' can only be specified with `prs: true' and `issues: false'")
+	}
+	if !r.GetPRs() && r.GetReviewRequests() {
+		return errors.New("'review_requests' cannot be specified without `prs: true'")
+	}
+	if !r.GetPRs() && r.GetReviews() {
+		return errors.New("'reviews' cannot be specified without `prs: true'")
+	}
+	if !r.GetPRs() && r.GetLinkedIssues() {
+		return errors.New("'linked_issues' cannot be specified without `prs: true'")
 	}
 	return nil
 }
@@ -817,20 +1673,28 @@ func (r RequireMatchingLabel) validate() error {
 // configuration specifies.
 func (r RequireMatchingLabel) Describe() string {
 	str := &strings.Builder{}
-	fmt.Fprintf(str, "Applies the '%s' label ", r.MissingLabel)
-	if r.MissingComment == "" {
-		fmt.Fprint(str, "to ")
+	issues, prs := r.ForKind(false), r.ForKind(true)
+	if r.GetIssues() && r.GetPRs() && issues.MissingLabel != prs.MissingLabel {
+		fmt.Fprintf(str, "Applies the '%s' label to Issues and the '%s' label to ", issues.MissingLabel, prs.MissingLabel)
 	} else {
-		fmt.Fprint(str, "and comments on ")
-	}
-
-	if r.Issues {
-		fmt.Fprint(str, "Issues ")
-		if r.PRs {
-			fmt.Fprint(str, "and ")
+		applied := issues
+		if !r.GetIssues() {
+			applied = prs
+		}
+		fmt.Fprintf(str, "Applies the '%s' label ", applied.MissingLabel)
+		if applied.MissingComment == "" {
+			fmt.Fprint(str, "to ")
+		} else {
+			fmt.Fprint(str, "and comments on ")
+		}
+		if r.GetIssues() {
+			fmt.Fprint(str, "Issues ")
+			if r.GetPRs() {
+				fmt.Fprint(str, "and ")
+			}
 		}
 	}
-	if r.PRs {
+	if r.GetPRs() {
 		if r.Branch != "" {
 			fmt.Fprintf(str, "'%s' branch ", r.Branch)
 		}
@@ -842,7 +1706,49 @@ func (r RequireMatchingLabel) Describe() string {
 	} else {
 		fmt.Fprintf(str, "in the '%s/%s' GitHub repo ", r.Org, r.Repo)
 	}
-	fmt.Fprintf(str, "that have no labels matching the regular expression '%s'.", r.Regexp)
+	if r.GetMaxChangedFiles() > 0 {
+		fmt.Fprintf(str, "that change more than %d files and ", r.GetMaxChangedFiles())
+	}
+	if len(r.Families) > 0 {
+		fmt.Fprintf(str, "that have labels of fewer than %d of the families matching '%s'", r.GetMinFamilies(), strings.Join(r.Families, "', '"))
+	} else if len(r.RequiredFamily) > 0 {
+		fmt.Fprintf(str, "that have none of the labels '%s'", strings.Join(r.RequiredFamily, "', '"))
+	} else {
+		fmt.Fprintf(str, "that have no labels matching the regular expression '%s'", r.Regexp)
+	}
+	if r.GetLinkedIssues() {
+		fmt.Fprint(str, ", including the labels of the issues they close,")
+	}
+	if r.ParentMarker != "" {
+		fmt.Fprintf(str, ", including the labels of the parent issue referenced with '%s #<number>',", r.ParentMarker)
+	}
+	if len(r.OrAssignees) > 0 {
+		fmt.Fprintf(str, " and are not assigned to any of %s", strings.Join(r.OrAssignees, ", "))
+	}
+	if r.Project != "" {
+		if len(r.ProjectColumns) > 0 {
+			fmt.Fprintf(str, " and are not in the '%s' columns of the '%s' project", strings.Join(r.ProjectColumns, "', '"), r.Project)
+		} else {
+			fmt.Fprintf(str, " and are not in the '%s' project", r.Project)
+		}
+	}
+	fmt.Fprint(str, ".")
+	if r.RenotifyAfter != "" {
+		fmt.Fprintf(str, " Comments again after %s of continued non-compliance, up to %d times.", r.RenotifyAfter, r.GetMaxRenotifications())
+	}
+	if r.OnNoLabels != "" {
+		fmt.Fprintf(str, " Applies the '%s' label to those that have no labels at all.", r.OnNoLabels)
+	}
+	if len(r.MutuallyExclusive) > 0 {
+		groups := make([]string, 0, len(r.MutuallyExclusive))
+		for _, group := range r.MutuallyExclusive {
+			groups = append(groups, fmt.Sprintf("'%s'", strings.Join(group, "', '")))
+		}
+		fmt.Fprintf(str, " Applies the '%s' label to those that have more than one of the labels %s.", r.ConflictLabel, strings.Join(groups, " or "))
+	}
+	if !r.IsEnabled() {
+		fmt.Fprint(str, " This configuration is disabled.")
+	}
 	return str.String()
 }
 
@@ -949,6 +1855,21 @@ func (c *Configuration) DcoFor(org, repo string) *Dco {
 	return &Dco{}
 }
 
+// SizeFor finds the size config for a repo. The global config is merged field
+// by field with the config of the org and then with the config of the repo
+// from SizeOverrides, so that an org or repo only needs to set the fields it
+// overrides.
+func (c *Configuration) SizeFor(org, repo string) Size {
+	size := c.Size
+	if override, ok := c.SizeOverrides[org]; ok {
+		size = size.mergeFrom(override)
+	}
+	if override, ok := c.SizeOverrides[fmt.Sprintf("%s/%s", org, repo)]; ok {
+		size = size.mergeFrom(override)
+	}
+	return size
+}
+
 func OldToNewPlugins(oldPlugins map[string][]string) Plugins {
 	newPlugins := make(Plugins)
 	for repo, plugins := range oldPlugins {
@@ -1098,6 +2019,17 @@ func (c *Configuration) setDefaults() {
 		if rml.GracePeriod == "" {
 			c.RequireMatchingLabel[i].GracePeriod = "5s"
 		}
+		if len(rml.MutuallyExclusive) > 0 && rml.ConflictLabel == "" {
+			c.RequireMatchingLabel[i].ConflictLabel = "needs-label-cleanup"
+		}
+		if rml.RenotifyAfter != "" && rml.MaxRenotifications == nil {
+			maxRenotifications := 1
+			c.RequireMatchingLabel[i].MaxRenotifications = &maxRenotifications
+		}
+		if len(rml.Families) > 0 && rml.MinFamilies == nil {
+			minFamilies := len(rml.Families)
+			c.RequireMatchingLabel[i].MinFamilies = &minFamilies
+		}
 	}
 }
 
@@ -1135,10 +2067,53 @@ func validateSizes(size Size) error {
 	if size.S > size.M || size.M > size.L || size.L > size.Xl || size.Xl > size.Xxl {
 		return errors.New("invalid size plugin configuration - one of the smaller sizes is bigger than a larger one")
 	}
+	if size.MaxFileLines < 0 {
+		return errors.New("invalid size plugin configuration - max_file_lines must not be negative")
+	}
+	if size.MaxFilePercent < 0 || size.MaxFilePercent > 100 {
+		return errors.New("invalid size plugin configuration - max_file_percent must be between 0 and 100")
+	}
+	if strings.HasPrefix(size.GeneratedLabel, "size/") {
+		return errors.New("invalid size plugin configuration - generated_label must not start with 'size/'")
+	}
+	if size.GeneratedLabelThreshold < 0 {
+		return errors.New("invalid size plugin configuration - generated_label_threshold must not be negative")
+	}
+	if size.HistoryWindow < 0 {
+		return errors.New("invalid size plugin configuration - history_window must not be negative")
+	}
+	if size.CheckRunAnnotations < 0 || size.CheckRunAnnotations > 50 {
+		return errors.New("invalid size plugin configuration - check_run_annotations must be between 0 and 50")
+	}
+	for label, bump := range size.EffortLabels {
+		if strings.HasPrefix(label, "size/") {
+			return fmt.Errorf("invalid size plugin configuration - effort label %q must not start with 'size/'", label)
+		}
+		if bump <= 0 {
+			return fmt.Errorf("invalid size plugin configuration - the bump of effort label %q must be positive", label)
+		}
+	}
 
 	return nil
 }
 
+// validateSizeOverrides validates the size config that results from each
+// org or repo override.
+func validateSizeOverrides(c *Configuration) error {
+	var errs []error
+	for orgOrRepo := range c.SizeOverrides {
+		org, repo, _ := strings.Cut(orgOrRepo, "/")
+		if org == "" {
+			errs = append(errs, fmt.Errorf("invalid size_overrides key %q - must be an org or org/repo", orgOrRepo))
+			continue
+		}
+		if err := validateSizes(c.SizeFor(org, repo)); err != nil {
+			errs = append(errs, fmt.Errorf("size_overrides for %s: %w", orgOrRepo, err))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
 func findDuplicatedPluginConfig(repoConfig, orgConfig []string) []string {
 	var dupes []string
 	for _, repoPlugin := range repoConfig {
@@ -1365,20 +2340,65 @@ func compileRegexpsAndDurations(pc *Configuration) error {
 	}
 	pc.Heart.CommentRe = commentRe
 
+	if pc.Size.CommentMinAge != "" {
+		dur, err := time.ParseDuration(pc.Size.CommentMinAge)
+		if err != nil {
+			return fmt.Errorf("failed to compile size comment min age duration: %q, error: %w", pc.Size.CommentMinAge, err)
+		}
+		pc.Size.CommentMinAgeDuration = dur
+	}
+	for orgOrRepo, size := range pc.SizeOverrides {
+		if size.CommentMinAge == "" {
+			continue
+		}
+		dur, err := time.ParseDuration(size.CommentMinAge)
+		if err != nil {
+			return fmt.Errorf("failed to compile size comment min age duration for %s: %q, error: %w", orgOrRepo, size.CommentMinAge, err)
+		}
+		size.CommentMinAgeDuration = dur
+		pc.SizeOverrides[orgOrRepo] = size
+	}
+
 	rs := pc.RequireMatchingLabel
 	for i := range rs {
-		re, err := regexp.Compile(rs[i].Regexp)
-		if err != nil {
-			return fmt.Errorf("failed to compile label regexp: %q, error: %w", rs[i].Regexp, err)
+		// Configs with a RequiredFamily or Families do not have a regexp.
+		if rs[i].Regexp != "" {
+			re, err := regexp.Compile(rs[i].Regexp)
+			if err != nil {
+				return fmt.Errorf("failed to compile label regexp: %q, error: %w", rs[i].Regexp, err)
+			}
+			rs[i].Re = re
+		}
+		rs[i].FamilyRes = nil
+		for _, family := range rs[i].Families {
+			re, err := regexp.Compile(family)
+			if err != nil {
+				return fmt.Errorf("failed to compile label family regexp: %q, error: %w", family, err)
+			}
+			rs[i].FamilyRes = append(rs[i].FamilyRes, re)
 		}
-		rs[i].Re = re
 
-		var dur time.Duration
-		dur, err = time.ParseDuration(rs[i].GracePeriod)
+		dur, err := time.ParseDuration(rs[i].GracePeriod)
 		if err != nil {
 			return fmt.Errorf("failed to compile grace period duration: %q, error: %w", rs[i].GracePeriod, err)
 		}
 		rs[i].GracePeriodDuration = dur
+
+		if rs[i].MaxLabelAge != "" {
+			dur, err = time.ParseDuration(rs[i].MaxLabelAge)
+			if err != nil {
+				return fmt.Errorf("failed to compile max label age duration: %q, error: %w", rs[i].MaxLabelAge, err)
+			}
+			rs[i].MaxLabelAgeDuration = dur
+		}
+
+		if rs[i].RenotifyAfter != "" {
+			dur, err = time.ParseDuration(rs[i].RenotifyAfter)
+			if err != nil {
+				return fmt.Errorf("failed to compile renotify after duration: %q, error: %w", rs[i].RenotifyAfter, err)
+			}
+			rs[i].RenotifyAfterDuration = dur
+		}
 	}
 	return nil
 }
@@ -1388,6 +2408,14 @@ func (c *Configuration) Validate() error {
 		logrus.Warn("no plugins specified-- check syntax?")
 	}
 
+	// Inheritance should run before defaulting so that defaults do not take
+	// precedence over inherited values.
+	resolved, err := resolveRequireMatchingLabelInheritance(c.RequireMatchingLabel)
+	if err != nil {
+		return err
+	}
+	c.RequireMatchingLabel = resolved
+
 	// Defaulting should run before validation.
 	c.setDefaults()
 	// Regexp compilation should run after defaulting, but before validation.
@@ -1410,6 +2438,9 @@ func (c *Configuration) Validate() error {
 	if err := validateSizes(c.Size); err != nil {
 		return err
 	}
+	if err := validateSizeOverrides(c); err != nil {
+		return err
+	}
 	if err := validateRequireMatchingLabel(c.RequireMatchingLabel); err != nil {
 		return err
 	}
@@ -2011,7 +3042,8 @@ func (c *Configuration) mergeFrom(other *Configuration) error {
 
 	diff := cmp.Diff(other, &Configuration{Approve: other.Approve, Bugzilla: other.Bugzilla,
 		ExternalPlugins: other.ExternalPlugins, Label: Label{RestrictedLabels: other.Label.RestrictedLabels},
-		Lgtm: other.Lgtm, Plugins: other.Plugins, Triggers: other.Triggers, Welcome: other.Welcome},
+		Lgtm: other.Lgtm, Plugins: other.Plugins, RequireMatchingLabel: other.RequireMatchingLabel,
+		Triggers: other.Triggers, Welcome: other.Welcome},
 		config.DefaultDiffOpts...)
 
 	if diff != "" {
@@ -2031,6 +3063,7 @@ func (c *Configuration) mergeFrom(other *Configuration) error {
 
 	c.Approve = append(c.Approve, other.Approve...)
 	c.Lgtm = append(c.Lgtm, other.Lgtm...)
+	c.RequireMatchingLabel = append(c.RequireMatchingLabel, other.RequireMatchingLabel...)
 	c.Triggers = append(c.Triggers, other.Triggers...)
 	c.Welcome = append(c.Welcome, other.Welcome...)
 
@@ -2164,7 +3197,7 @@ func (c *Configuration) HasConfigFor() (global bool, orgs sets.Set[string], repo
 	equals := reflect.DeepEqual(c,
 		&Configuration{Approve: c.Approve, Bugzilla: c.Bugzilla, ExternalPlugins: c.ExternalPlugins,
 			Label: Label{RestrictedLabels: c.Label.RestrictedLabels}, Lgtm: c.Lgtm, Plugins: c.Plugins,
-			Triggers: c.Triggers, Welcome: c.Welcome})
+			RequireMatchingLabel: c.RequireMatchingLabel, Triggers: c.Triggers, Welcome: c.Welcome})
 
 	if !equals || c.Bugzilla.Default != nil {
 		global = true
@@ -2221,6 +3254,17 @@ func (c *Configuration) HasConfigFor() (global bool, orgs sets.Set[string], repo
 		}
 	}
 
+	for _, rml := range c.RequireMatchingLabel {
+		switch {
+		case rml.Org == "":
+			global = true
+		case rml.Repo == "":
+			orgs.Insert(rml.Org)
+		default:
+			repos.Insert(rml.Org + "/" + rml.Repo)
+		}
+	}
+
 	for _, trigger := range c.Triggers {
 		for _, orgOrRepo := range trigger.Repos {
 			if strings.Contains(orgOrRepo, "/") {
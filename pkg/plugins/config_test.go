@@ -275,6 +275,163 @@ func TestTriggerFor(t *testing.T) {
 	}
 }
 
+func TestSizeFor(t *testing.T) {
+	config := Configuration{
+		Size: Size{
+			S:             10,
+			M:             30,
+			L:             100,
+			Xl:            500,
+			Xxl:           1000,
+			StatusContext: "size",
+			ForceXXLGlobs: []string{"**/*.proto"},
+			Summary:       utilpointer.Bool(true),
+		},
+		SizeOverrides: map[string]Size{
+			"org": {
+				Xxl:            2000,
+				MaxFilePercent: 50,
+				CommentMinAge:  "10m",
+			},
+			"org/repo": {
+				S:             20,
+				StatusFailXXL: utilpointer.Bool(true),
+				ForceXXLGlobs: []string{},
+			},
+			"other/repo": {
+				GoSemantic: utilpointer.Bool(true),
+				Summary:    utilpointer.Bool(false),
+			},
+		},
+	}
+	if err := config.Validate(); err != nil {
+		t.Fatalf("unexpected error validating config: %v", err)
+	}
+
+	testCases := []struct {
+		name      string
+		org, repo string
+		expected  Size
+	}{
+		{
+			name: "global config",
+			org:  "k8s",
+			repo: "k8s",
+			expected: Size{
+				S: 10, M: 30, L: 100, Xl: 500, Xxl: 1000,
+				StatusContext: "size",
+				ForceXXLGlobs: []string{"**/*.proto"},
+				Summary:       utilpointer.Bool(true),
+			},
+		},
+		{
+			name: "org overrides some fields",
+			org:  "org",
+			repo: "other",
+			expected: Size{
+				S: 10, M: 30, L: 100, Xl: 500, Xxl: 2000,
+				StatusContext:         "size",
+				MaxFilePercent:        50,
+				ForceXXLGlobs:         []string{"**/*.proto"},
+				Summary:               utilpointer.Bool(true),
+				CommentMinAge:         "10m",
+				CommentMinAgeDuration: 10 * time.Minute,
+			},
+		},
+		{
+			name: "repo overrides some fields of the org",
+			org:  "org",
+			repo: "repo",
+			expected: Size{
+				S: 20, M: 30, L: 100, Xl: 500, Xxl: 2000,
+				StatusContext:         "size",
+				StatusFailXXL:         utilpointer.Bool(true),
+				MaxFilePercent:        50,
+				ForceXXLGlobs:         []string{},
+				Summary:               utilpointer.Bool(true),
+				CommentMinAge:         "10m",
+				CommentMinAgeDuration: 10 * time.Minute,
+			},
+		},
+		{
+			name: "repo overrides without org overrides, turning off a bool",
+			org:  "other",
+			repo: "repo",
+			expected: Size{
+				S: 10, M: 30, L: 100, Xl: 500, Xxl: 1000,
+				StatusContext: "size",
+				ForceXXLGlobs: []string{"**/*.proto"},
+				GoSemantic:    utilpointer.Bool(true),
+				Summary:       utilpointer.Bool(false),
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, config.SizeFor(tc.org, tc.repo)); diff != "" {
+				t.Errorf("unexpected size config (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateSizeOverrides(t *testing.T) {
+	testCases := []struct {
+		name        string
+		config      Configuration
+		expectedErr bool
+	}{
+		{
+			name: "valid overrides",
+			config: Configuration{
+				Size:          Size{S: 10, M: 30, L: 100, Xl: 500, Xxl: 1000},
+				SizeOverrides: map[string]Size{"org": {Xxl: 2000}, "org/repo": {S: 20}},
+			},
+		},
+		{
+			name: "override makes thresholds inconsistent",
+			config: Configuration{
+				Size:          Size{S: 10, M: 30, L: 100, Xl: 500, Xxl: 1000},
+				SizeOverrides: map[string]Size{"org/repo": {M: 5}},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "repo override makes org override inconsistent",
+			config: Configuration{
+				Size:          Size{S: 10, M: 30, L: 100, Xl: 500, Xxl: 1000},
+				SizeOverrides: map[string]Size{"org": {Xxl: 2000}, "org/repo": {Xl: 3000}},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "invalid field in override",
+			config: Configuration{
+				SizeOverrides: map[string]Size{"org": {MaxFilePercent: 101}},
+			},
+			expectedErr: true,
+		},
+		{
+			name: "empty key",
+			config: Configuration{
+				SizeOverrides: map[string]Size{"": {S: 1}},
+			},
+			expectedErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validateSizeOverrides(&tc.config)
+			if err != nil && !tc.expectedErr {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if err == nil && tc.expectedErr {
+				t.Error("expected an error, but got none")
+			}
+		})
+	}
+}
+
 func TestSetApproveDefaults(t *testing.T) {
 	c := &Configuration{
 		Approve: []Approve{
@@ -1356,6 +1513,217 @@ func TestStatesMatch(t *testing.T) {
 	}
 }
 
+func TestValidateRequireMatchingLabelRequiredFamily(t *testing.T) {
+	testCases := []struct {
+		name          string
+		regexp        string
+		family        []string
+		errorExpected bool
+	}{
+		{
+			name:   "regexp",
+			regexp: "^sig/",
+		},
+		{
+			name:   "required family",
+			family: []string{"sig/network", "sig/node"},
+		},
+		{
+			name:          "neither regexp nor required family",
+			errorExpected: true,
+		},
+		{
+			name:          "regexp and required family are mutually exclusive",
+			regexp:        "^sig/",
+			family:        []string{"sig/network"},
+			errorExpected: true,
+		},
+		{
+			name:          "empty label in required family",
+			family:        []string{"sig/network", ""},
+			errorExpected: true,
+		},
+		{
+			name:          "missing label in required family",
+			family:        []string{"sig/network", "needs-sig"},
+			errorExpected: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := &Configuration{
+				RequireMatchingLabel: []RequireMatchingLabel{
+					{
+						Org:            "org",
+						Issues:         utilpointer.Bool(true),
+						Regexp:         tc.regexp,
+						RequiredFamily: tc.family,
+						MissingLabel:   "needs-sig",
+						GracePeriod:    "5s",
+					},
+				},
+			}
+			if err := config.Validate(); (err != nil) != tc.errorExpected {
+				t.Errorf("expected error: %t, got: %v", tc.errorExpected, err)
+			}
+		})
+	}
+}
+
+func TestValidateRequireMatchingLabelFamilies(t *testing.T) {
+	testCases := []struct {
+		name                string
+		regexp              string
+		families            []string
+		minFamilies         *int
+		expectedMinFamilies int
+		errorExpected       bool
+	}{
+		{
+			name:                "min families defaults to the number of families",
+			families:            []string{"^sig/", "^kind/"},
+			expectedMinFamilies: 2,
+		},
+		{
+			name:                "min families",
+			families:            []string{"^sig/", "^kind/", "^priority/"},
+			minFamilies:         utilpointer.Int(2),
+			expectedMinFamilies: 2,
+		},
+		{
+			name:          "regexp and families are mutually exclusive",
+			regexp:        "^sig/",
+			families:      []string{"^kind/"},
+			errorExpected: true,
+		},
+		{
+			name:          "empty regexp in families",
+			families:      []string{"^sig/", ""},
+			errorExpected: true,
+		},
+		{
+			name:          "invalid regexp in families",
+			families:      []string{"^sig/", "("},
+			errorExpected: true,
+		},
+		{
+			name:          "min families exceeds the number of families",
+			families:      []string{"^sig/", "^kind/"},
+			minFamilies:   utilpointer.Int(3),
+			errorExpected: true,
+		},
+		{
+			name:          "negative min families",
+			families:      []string{"^sig/", "^kind/"},
+			minFamilies:   utilpointer.Int(-1),
+			errorExpected: true,
+		},
+		{
+			name:          "min families without families",
+			regexp:        "^sig/",
+			minFamilies:   utilpointer.Int(1),
+			errorExpected: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := &Configuration{
+				RequireMatchingLabel: []RequireMatchingLabel{
+					{
+						Org:          "org",
+						Issues:       utilpointer.Bool(true),
+						Regexp:       tc.regexp,
+						Families:     tc.families,
+						MinFamilies:  tc.minFamilies,
+						MissingLabel: "needs-triage",
+						GracePeriod:  "5s",
+					},
+				},
+			}
+			err := config.Validate()
+			if (err != nil) != tc.errorExpected {
+				t.Fatalf("expected error: %t, got: %v", tc.errorExpected, err)
+			}
+			if err == nil && config.RequireMatchingLabel[0].GetMinFamilies() != tc.expectedMinFamilies {
+				t.Errorf("expected min families %d, got %d", tc.expectedMinFamilies, config.RequireMatchingLabel[0].GetMinFamilies())
+			}
+		})
+	}
+}
+
+func TestValidateRequireMatchingLabelMutuallyExclusive(t *testing.T) {
+	testCases := []struct {
+		name                  string
+		mutuallyExclusive     [][]string
+		conflictLabel         string
+		conflictComment       string
+		expectedConflictLabel string
+		errorExpected         bool
+	}{
+		{
+			name:                  "conflict label defaults",
+			mutuallyExclusive:     [][]string{{"lifecycle/frozen", "lifecycle/rotten"}},
+			expectedConflictLabel: "needs-label-cleanup",
+		},
+		{
+			name:                  "custom conflict label",
+			mutuallyExclusive:     [][]string{{"lifecycle/frozen", "lifecycle/rotten"}},
+			conflictLabel:         "contradictory-labels",
+			expectedConflictLabel: "contradictory-labels",
+		},
+		{
+			name:              "group with a single label",
+			mutuallyExclusive: [][]string{{"lifecycle/frozen"}},
+			errorExpected:     true,
+		},
+		{
+			name:              "empty label in group",
+			mutuallyExclusive: [][]string{{"lifecycle/frozen", ""}},
+			errorExpected:     true,
+		},
+		{
+			name:              "conflict label in group",
+			mutuallyExclusive: [][]string{{"lifecycle/frozen", "needs-label-cleanup"}},
+			errorExpected:     true,
+		},
+		{
+			name:              "conflict label matching regexp",
+			mutuallyExclusive: [][]string{{"lifecycle/frozen", "lifecycle/rotten"}},
+			conflictLabel:     "sig/cleanup",
+			errorExpected:     true,
+		},
+		{
+			name:            "conflict comment without groups",
+			conflictComment: "Please remove one of the labels.",
+			errorExpected:   true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := &Configuration{
+				RequireMatchingLabel: []RequireMatchingLabel{
+					{
+						Org:               "org",
+						Issues:            utilpointer.Bool(true),
+						Regexp:            "^sig/",
+						MissingLabel:      "needs-sig",
+						MutuallyExclusive: tc.mutuallyExclusive,
+						ConflictLabel:     tc.conflictLabel,
+						ConflictComment:   tc.conflictComment,
+					},
+				},
+			}
+			err := config.Validate()
+			if (err != nil) != tc.errorExpected {
+				t.Fatalf("expected error: %t, got: %v", tc.errorExpected, err)
+			}
+			if err == nil && config.RequireMatchingLabel[0].ConflictLabel != tc.expectedConflictLabel {
+				t.Errorf("expected conflict label %q, got %q", tc.expectedConflictLabel, config.RequireMatchingLabel[0].ConflictLabel)
+			}
+		})
+	}
+}
+
 func TestValidateConfigUpdater(t *testing.T) {
 	testCases := []struct {
 		name        string
@@ -2063,6 +2431,7 @@ func TestHasConfigFor(t *testing.T) {
 				fuzzedConfig.Approve = nil
 				fuzzedConfig.Label.RestrictedLabels = nil
 				fuzzedConfig.Lgtm = nil
+				fuzzedConfig.RequireMatchingLabel = nil
 				fuzzedConfig.Triggers = nil
 				fuzzedConfig.Welcome = nil
 				fuzzedConfig.ExternalPlugins = nil
@@ -2140,6 +2509,26 @@ func TestHasConfigFor(t *testing.T) {
 				return fuzzedConfig, false, expectOrgs, expectRepos
 			},
 		},
+		{
+			name: "Any config with require_matching_label is considered to be for the orgs and repos references there",
+			resultGenerator: func(fuzzedConfig *Configuration) (toCheck *Configuration, expectGlobal bool, expectOrgs sets.Set[string], expectRepos sets.Set[string]) {
+				fuzzedConfig = &Configuration{RequireMatchingLabel: fuzzedConfig.RequireMatchingLabel}
+				expectOrgs, expectRepos = sets.Set[string]{}, sets.Set[string]{}
+
+				for _, rml := range fuzzedConfig.RequireMatchingLabel {
+					switch {
+					case rml.Org == "":
+						expectGlobal = true
+					case rml.Repo == "":
+						expectOrgs.Insert(rml.Org)
+					default:
+						expectRepos.Insert(rml.Org + "/" + rml.Repo)
+					}
+				}
+
+				return fuzzedConfig, expectGlobal, expectOrgs, expectRepos
+			},
+		},
 		{
 			name: "Any config with triggers is considered to be for the orgs and repos references there",
 			resultGenerator: func(fuzzedConfig *Configuration) (toCheck *Configuration, expectGlobal bool, expectOrgs sets.Set[string], expectRepos sets.Set[string]) {
@@ -2248,6 +2637,134 @@ func TestHasConfigFor(t *testing.T) {
 	}
 }
 
+func TestResolveRequireMatchingLabelInheritance(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name          string
+		in            []RequireMatchingLabel
+		expected      []RequireMatchingLabel
+		errorExpected bool
+	}{
+		{
+			name: "configs without inheritance are unchanged",
+			in: []RequireMatchingLabel{
+				{Org: "org", Issues: utilpointer.Bool(true), Regexp: "^sig/", MissingLabel: "needs-sig"},
+			},
+			expected: []RequireMatchingLabel{
+				{Org: "org", Issues: utilpointer.Bool(true), Regexp: "^sig/", MissingLabel: "needs-sig"},
+			},
+		},
+		{
+			name: "inheriting configs are merged with the base, overrides win",
+			in: []RequireMatchingLabel{
+				{Name: "sig", Issues: utilpointer.Bool(true), Regexp: "^sig/", MissingLabel: "needs-sig", MissingComment: "Please add a sig.", GracePeriod: "10s"},
+				{Inherit: "sig", Org: "org", Repo: "a"},
+				{Inherit: "sig", Org: "org", Repo: "b", MissingLabel: "needs-sig-label", PRs: utilpointer.Bool(true)},
+			},
+			expected: []RequireMatchingLabel{
+				{Org: "org", Repo: "a", Issues: utilpointer.Bool(true), Regexp: "^sig/", MissingLabel: "needs-sig", MissingComment: "Please add a sig.", GracePeriod: "10s"},
+				{Org: "org", Repo: "b", Issues: utilpointer.Bool(true), PRs: utilpointer.Bool(true), Regexp: "^sig/", MissingLabel: "needs-sig-label", MissingComment: "Please add a sig.", GracePeriod: "10s"},
+			},
+		},
+		{
+			name: "named configs with an org are applied and can be inherited from",
+			in: []RequireMatchingLabel{
+				{Name: "org-wide", Org: "org", Issues: utilpointer.Bool(true), Regexp: "^kind/", MissingLabel: "needs-kind"},
+				{Name: "repo", Inherit: "org-wide", Repo: "repo", PRs: utilpointer.Bool(true)},
+				{Inherit: "repo", Branch: "main"},
+			},
+			expected: []RequireMatchingLabel{
+				{Name: "org-wide", Org: "org", Issues: utilpointer.Bool(true), Regexp: "^kind/", MissingLabel: "needs-kind"},
+				{Name: "repo", Org: "org", Repo: "repo", Issues: utilpointer.Bool(true), PRs: utilpointer.Bool(true), Regexp: "^kind/", MissingLabel: "needs-kind"},
+				{Org: "org", Repo: "repo", Branch: "main", Issues: utilpointer.Bool(true), PRs: utilpointer.Bool(true), Regexp: "^kind/", MissingLabel: "needs-kind"},
+			},
+		},
+		{
+			name: "regexp and required family are not inherited together",
+			in: []RequireMatchingLabel{
+				{Name: "base", RequiredFamily: []string{"sig/node"}, GracePeriod: "5s"},
+				{Inherit: "base", Org: "org", Regexp: "^sig/"},
+				{Inherit: "base", Org: "org", Repo: "repo"},
+			},
+			expected: []RequireMatchingLabel{
+				{Org: "org", Regexp: "^sig/", GracePeriod: "5s"},
+				{Org: "org", Repo: "repo", RequiredFamily: []string{"sig/node"}, GracePeriod: "5s"},
+			},
+		},
+		{
+			name: "bools of the base can be turned off",
+			in: []RequireMatchingLabel{
+				{Name: "base", PRs: utilpointer.Bool(true), Reviews: utilpointer.Bool(true), LinkedIssues: utilpointer.Bool(true), Regexp: "^sig/"},
+				{Inherit: "base", Org: "org", Issues: utilpointer.Bool(true), PRs: utilpointer.Bool(false), Reviews: utilpointer.Bool(false)},
+			},
+			expected: []RequireMatchingLabel{
+				{Org: "org", Issues: utilpointer.Bool(true), PRs: utilpointer.Bool(false), Reviews: utilpointer.Bool(false), LinkedIssues: utilpointer.Bool(true), Regexp: "^sig/"},
+			},
+		},
+		{
+			name: "ints of the base are inherited unless overridden, including with zero",
+			in: []RequireMatchingLabel{
+				{Name: "base", PRs: utilpointer.Bool(true), Regexp: "^sig/", MaxChangedFiles: utilpointer.Int(5), MinFamilies: utilpointer.Int(1), MaxRenotifications: utilpointer.Int(3), RenotifyAfter: "24h"},
+				{Inherit: "base", Org: "org"},
+				{Inherit: "base", Org: "org", Repo: "repo", MaxChangedFiles: utilpointer.Int(0), MinFamilies: utilpointer.Int(0), MaxRenotifications: utilpointer.Int(0)},
+			},
+			expected: []RequireMatchingLabel{
+				{Org: "org", PRs: utilpointer.Bool(true), Regexp: "^sig/", MaxChangedFiles: utilpointer.Int(5), MinFamilies: utilpointer.Int(1), MaxRenotifications: utilpointer.Int(3), RenotifyAfter: "24h"},
+				{Org: "org", Repo: "repo", PRs: utilpointer.Bool(true), Regexp: "^sig/", MaxChangedFiles: utilpointer.Int(0), MinFamilies: utilpointer.Int(0), MaxRenotifications: utilpointer.Int(0), RenotifyAfter: "24h"},
+			},
+		},
+		{
+			name: "severity is inherited unless overridden",
+			in: []RequireMatchingLabel{
+				{Name: "base", Regexp: "^sig/", MissingLabel: "needs-sig", Severity: "warning"},
+				{Inherit: "base", Org: "org"},
+				{Inherit: "base", Org: "org", Repo: "repo", Severity: "blocking"},
+			},
+			expected: []RequireMatchingLabel{
+				{Org: "org", Regexp: "^sig/", MissingLabel: "needs-sig", Severity: "warning"},
+				{Org: "org", Repo: "repo", Regexp: "^sig/", MissingLabel: "needs-sig", Severity: "blocking"},
+			},
+		},
+		{
+			name: "missing base is an error",
+			in: []RequireMatchingLabel{
+				{Inherit: "missing", Org: "org"},
+			},
+			errorExpected: true,
+		},
+		{
+			name: "duplicate names are an error",
+			in: []RequireMatchingLabel{
+				{Name: "base", Regexp: "^sig/"},
+				{Name: "base", Regexp: "^kind/"},
+			},
+			errorExpected: true,
+		},
+		{
+			name: "inheritance cycle is an error",
+			in: []RequireMatchingLabel{
+				{Name: "a", Inherit: "b"},
+				{Name: "b", Inherit: "a"},
+			},
+			errorExpected: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			actual, err := resolveRequireMatchingLabelInheritance(tc.in)
+			if (err != nil) != tc.errorExpected {
+				t.Fatalf("expected error: %t, got: %v", tc.errorExpected, err)
+			}
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Errorf("resolved configs differ from expected: %s", diff)
+			}
+		})
+	}
+}
+
 func TestMergeFrom(t *testing.T) {
 	t.Parallel()
 	testCases := []struct {
@@ -2275,6 +2792,15 @@ func TestMergeFrom(t *testing.T) {
 				{Repos: []string{"foo/baz"}},
 			}},
 		},
+		{
+			name:                "RequireMatchingLabel config gets merged",
+			in:                  Configuration{RequireMatchingLabel: []RequireMatchingLabel{{Name: "base", Regexp: "^sig/"}}},
+			supplementalConfigs: []Configuration{{RequireMatchingLabel: []RequireMatchingLabel{{Inherit: "base", Org: "foo"}}}},
+			expected: Configuration{RequireMatchingLabel: []RequireMatchingLabel{
+				{Name: "base", Regexp: "^sig/"},
+				{Inherit: "base", Org: "foo"},
+			}},
+		},
 		{
 			name:                "Triggers config gets merged",
 			in:                  Configuration{Triggers: []Trigger{{Repos: []string{"foo/bar"}}}},
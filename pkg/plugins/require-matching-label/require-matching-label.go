@@ -17,40 +17,60 @@ limitations under the License.
 // Package requirematchinglabel implements the `require-matching-label` plugin.
 // This is a configurable plugin that applies a label (and possibly comments)
 // when an issue or PR does not have any labels matching a regexp. If a label
-// is added that matches the regexp, the 'MissingLabel' is removed and the comment
-// is deleted.
+// is added that matches the regexp, the 'MissingLabel' is removed, the comment
+// is deleted and an optional comment confirming the label is created.
 package requirematchinglabel
 
 import (
+	"encoding/json"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
+	"sigs.k8s.io/prow/pkg/commentpruner"
 	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/genfiles"
+	"sigs.k8s.io/prow/pkg/gitattributes"
 	"sigs.k8s.io/prow/pkg/github"
 	"sigs.k8s.io/prow/pkg/pluginhelp"
 	"sigs.k8s.io/prow/pkg/plugins"
 
 	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/utils/clock"
+	utilpointer "k8s.io/utils/pointer"
 )
 
 var (
 	handlePRActions = map[github.PullRequestEventAction]bool{
-		github.PullRequestActionOpened:    true,
-		github.PullRequestActionReopened:  true,
-		github.PullRequestActionLabeled:   true,
-		github.PullRequestActionUnlabeled: true,
+		github.PullRequestActionOpened:               true,
+		github.PullRequestActionReopened:             true,
+		github.PullRequestActionLabeled:              true,
+		github.PullRequestActionUnlabeled:            true,
+		github.PullRequestActionAssigned:             true,
+		github.PullRequestActionUnassigned:           true,
+		github.PullRequestActionSynchronize:          true,
+		github.PullRequestActionReviewRequested:      true,
+		github.PullRequestActionReviewRequestRemoved: true,
 	}
 
 	handleIssueActions = map[github.IssueEventAction]bool{
-		github.IssueActionOpened:    true,
-		github.IssueActionReopened:  true,
-		github.IssueActionLabeled:   true,
-		github.IssueActionUnlabeled: true,
+		github.IssueActionOpened:      true,
+		github.IssueActionReopened:    true,
+		github.IssueActionLabeled:     true,
+		github.IssueActionUnlabeled:   true,
+		github.IssueActionAssigned:    true,
+		github.IssueActionUnassigned:  true,
+		github.IssueActionTransferred: true,
 	}
 
 	checkRequireLabelsRe = regexp.MustCompile(`(?mi)^/check-required-labels\s*$`)
+	wouldRequireLabelsRe = regexp.MustCompile(`(?mi)^/would-require-labels\s*$`)
+
+	// closingIssueRe matches references to issues of the same repo that a PR closes.
+	closingIssueRe = regexp.MustCompile(`(?i)\b(?:close[sd]?|fix(?:e[sd])?|resolve[sd]?):?\s+#(\d+)\b`)
 )
 
 const (
@@ -62,7 +82,17 @@ type githubClient interface {
 	RemoveLabel(org, repo string, number int, label string) error
 	CreateComment(org, repo string, number int, content string) error
 	GetIssueLabels(org, repo string, number int) ([]github.Label, error)
+	GetIssue(org, repo string, number int) (*github.Issue, error)
 	GetPullRequest(org, repo string, number int) (*github.PullRequest, error)
+	GetPullRequestChanges(org, repo string, number int) ([]github.PullRequestChange, error)
+	GetFile(org, repo, filepath, commit string) ([]byte, error)
+	ListIssueEvents(org, repo string, num int) ([]github.ListedIssueEvent, error)
+	ListIssueComments(org, repo string, number int) ([]github.IssueComment, error)
+	HasPermission(org, repo, user string, roles ...string) (bool, error)
+	GetRepoProjects(owner, repo string) ([]github.Project, error)
+	GetOrgProjects(org string) ([]github.Project, error)
+	GetProjectColumns(org string, projectID int) ([]github.ProjectColumn, error)
+	GetColumnProjectCard(org string, columnID int, contentURL string) (*github.ProjectCard, error)
 }
 
 type commentPruner interface {
@@ -72,6 +102,7 @@ type commentPruner interface {
 func init() {
 	plugins.RegisterIssueHandler(pluginName, handleIssue, helpProvider)
 	plugins.RegisterPullRequestHandler(pluginName, handlePullRequest, helpProvider)
+	plugins.RegisterReviewEventHandler(pluginName, handleReview, helpProvider)
 	plugins.RegisterGenericCommentHandler(pluginName, handleCommentEvent, helpProvider)
 }
 
@@ -88,8 +119,8 @@ func helpProvider(config *plugins.Configuration, _ []config.OrgRepo) (*pluginhel
 				Org:            "org",
 				Repo:           "repo",
 				Branch:         "master",
-				PRs:            true,
-				Issues:         true,
+				PRs:            utilpointer.Bool(true),
+				Issues:         utilpointer.Bool(true),
 				Regexp:         "^kind/",
 				MissingLabel:   "needs-kind",
 				MissingComment: "Please add a label referencing the kind.",
@@ -114,6 +145,12 @@ func helpProvider(config *plugins.Configuration, _ []config.OrgRepo) (*pluginhel
 		WhoCanUse:   "Anyone",
 		Examples:    []string{"/check-required-labels"},
 	})
+	pluginHelp.AddCommand(pluginhelp.Command{
+		Usage:       "/would-require-labels",
+		Description: "Reports which labels checking for required labels would add or remove and whether it would comment, without changing anything.",
+		WhoCanUse:   "Maintainers of the repo, i.e. users with write access.",
+		Examples:    []string{"/would-require-labels"},
+	})
 	return pluginHelp, nil
 }
 
@@ -124,29 +161,85 @@ type event struct {
 	author string
 	// The PR's base branch. If empty this is an Issue, not a PR.
 	branch string
+	// The PR's base SHA. This may be omitted, in which case it is fetched if needed.
+	baseSHA string
+	// The issue's or PR's description. This may be omitted, in which case it is fetched if needed.
+	body string
+	// The time the issue or PR was created. This may be omitted, in which case it is fetched if needed.
+	created time.Time
 	// The label that was added or removed. If empty this is an open or reopen event.
 	label string
+	// The user that added the label. Only set for label additions.
+	labeler string
 	// The labels currently on the issue. For PRs this is not contained in the webhook payload and may be omitted.
 	currentLabels []github.Label
+	// Whether the assignees were changed. If true this is an assign or unassign event.
+	assigneeChanged bool
+	// Whether the PR's changes were updated. If true this is a synchronize event.
+	filesChanged bool
+	// Whether the PR's review requests were changed. If true this is a
+	// review_requested or review_request_removed event.
+	reviewRequestChanged bool
+	// Whether a review was submitted for the PR. If true this is a
+	// pull_request_review submitted event.
+	reviewSubmitted bool
+	// The users currently assigned to the issue. This may be omitted, in which case
+	// the assignees are fetched if any relevant config needs them.
+	assignees []github.User
 }
 
 func handleIssue(pc plugins.Agent, ie github.IssueEvent) error {
 	if !handleIssueActions[ie.Action] {
 		return nil
 	}
+	if ie.Action == github.IssueActionTransferred {
+		e, err := transferEvent(ie)
+		if err != nil || e == nil {
+			return err
+		}
+		// The comment pruner of the agent is bound to the issue in the old repo.
+		cp := commentpruner.NewEventClient(pc.GitHubClient, pc.Logger.WithField("client", "commentpruner"), e.org, e.repo, e.number)
+		return handle(pc.Logger, pc.GitHubClient, cp, clock.RealClock{}, pc.PluginConfig.RequireMatchingLabel, e)
+	}
 	e := &event{
-		org:           ie.Repo.Owner.Login,
-		repo:          ie.Repo.Name,
-		number:        ie.Issue.Number,
-		author:        ie.Issue.User.Login,
-		label:         ie.Label.Name, // This will be empty for non-label events.
-		currentLabels: ie.Issue.Labels,
+		org:             ie.Repo.Owner.Login,
+		repo:            ie.Repo.Name,
+		number:          ie.Issue.Number,
+		author:          ie.Issue.User.Login,
+		body:            ie.Issue.Body,
+		created:         ie.Issue.CreatedAt,
+		label:           ie.Label.Name, // This will be empty for non-label events.
+		labeler:         labeler(ie.Action == github.IssueActionLabeled, ie.Sender),
+		currentLabels:   ie.Issue.Labels,
+		assigneeChanged: ie.Action == github.IssueActionAssigned || ie.Action == github.IssueActionUnassigned,
+		assignees:       ie.Issue.Assignees,
 	}
 	cp, err := pc.CommentPruner()
 	if err != nil {
 		return err
 	}
-	return handle(pc.Logger, pc.GitHubClient, cp, pc.PluginConfig.RequireMatchingLabel, e)
+	return handle(pc.Logger, pc.GitHubClient, cp, clock.RealClock{}, pc.PluginConfig.RequireMatchingLabel, e)
+}
+
+// transferEvent returns the event for an issue that was transferred, so that
+// its requirements are re-evaluated in the scope of the repo it was transferred
+// to, as its labels may no longer satisfy the configs of that repo. The event
+// is handled like an issue being opened in the new repo. A nil event is
+// returned if the payload does not describe the new issue.
+func transferEvent(ie github.IssueEvent) (*event, error) {
+	var changes github.IssueTransferChanges
+	if err := json.Unmarshal(ie.Changes, &changes); err != nil {
+		return nil, fmt.Errorf("error parsing the changes of the transfer: %w", err)
+	}
+	if changes.NewRepo.Owner.Login == "" || changes.NewRepo.Name == "" || changes.NewIssue.Number == 0 {
+		return nil, nil
+	}
+	return &event{
+		org:    changes.NewRepo.Owner.Login,
+		repo:   changes.NewRepo.Name,
+		number: changes.NewIssue.Number,
+		author: changes.NewIssue.User.Login,
+	}, nil
 }
 
 func handlePullRequest(pc plugins.Agent, pre github.PullRequestEvent) error {
@@ -154,29 +247,73 @@ func handlePullRequest(pc plugins.Agent, pre github.PullRequestEvent) error {
 		return nil
 	}
 	e := &event{
-		org:    pre.Repo.Owner.Login,
-		repo:   pre.Repo.Name,
-		number: pre.PullRequest.Number,
-		branch: pre.PullRequest.Base.Ref,
-		author: pre.PullRequest.User.Login,
-		label:  pre.Label.Name, // This will be empty for non-label events.
+		org:             pre.Repo.Owner.Login,
+		repo:            pre.Repo.Name,
+		number:          pre.PullRequest.Number,
+		branch:          pre.PullRequest.Base.Ref,
+		body:            pre.PullRequest.Body,
+		created:         pre.PullRequest.CreatedAt,
+		author:          pre.PullRequest.User.Login,
+		label:           pre.Label.Name, // This will be empty for non-label events.
+		labeler:         labeler(pre.Action == github.PullRequestActionLabeled, pre.Sender),
+		assigneeChanged: pre.Action == github.PullRequestActionAssigned || pre.Action == github.PullRequestActionUnassigned,
+		assignees:       pre.PullRequest.Assignees,
+		filesChanged:    pre.Action == github.PullRequestActionSynchronize,
+		reviewRequestChanged: pre.Action == github.PullRequestActionReviewRequested ||
+			pre.Action == github.PullRequestActionReviewRequestRemoved,
+	}
+	cp, err := pc.CommentPruner()
+	if err != nil {
+		return err
+	}
+	return handle(pc.Logger, pc.GitHubClient, cp, clock.RealClock{}, pc.PluginConfig.RequireMatchingLabel, e)
+}
+
+func handleReview(pc plugins.Agent, re github.ReviewEvent) error {
+	if re.Action != github.ReviewActionSubmitted {
+		return nil
+	}
+	e := &event{
+		org:             re.Repo.Owner.Login,
+		repo:            re.Repo.Name,
+		number:          re.PullRequest.Number,
+		branch:          re.PullRequest.Base.Ref,
+		body:            re.PullRequest.Body,
+		created:         re.PullRequest.CreatedAt,
+		author:          re.PullRequest.User.Login,
+		assignees:       re.PullRequest.Assignees,
+		reviewSubmitted: true,
 	}
 	cp, err := pc.CommentPruner()
 	if err != nil {
 		return err
 	}
-	return handle(pc.Logger, pc.GitHubClient, cp, pc.PluginConfig.RequireMatchingLabel, e)
+	return handle(pc.Logger, pc.GitHubClient, cp, clock.RealClock{}, pc.PluginConfig.RequireMatchingLabel, e)
+}
+
+// labeler returns the login of the sender of a label addition event, or an
+// empty string for other events.
+func labeler(labeled bool, sender github.User) string {
+	if !labeled {
+		return ""
+	}
+	return sender.Login
 }
 
 // matchingConfigs filters irrelevant RequireMtchingLabel configs from
 // the list of all configs.
 // `branch` should be empty for Issues and non-empty for PRs.
 // `label` should be omitted in the case of 'open' and 'reopen' actions.
-func matchingConfigs(org, repo, branch, label string, allConfigs []plugins.RequireMatchingLabel) []plugins.RequireMatchingLabel {
+// `assigneeChanged` should be true only for 'assigned' and 'unassigned' actions.
+// `filesChanged` should be true only for 'synchronize' actions.
+// `reviewRequestChanged` should be true only for 'review_requested' and
+// 'review_request_removed' actions.
+// `reviewSubmitted` should be true only for submitted reviews.
+func matchingConfigs(org, repo, branch, label string, assigneeChanged, filesChanged, reviewRequestChanged, reviewSubmitted bool, allConfigs []plugins.RequireMatchingLabel) []plugins.RequireMatchingLabel {
 	var filtered []plugins.RequireMatchingLabel
 	for _, cfg := range allConfigs {
 		// Check if the config applies to this issue type.
-		if (branch == "" && !cfg.Issues) || (branch != "" && !cfg.PRs) {
+		if (branch == "" && !cfg.GetIssues()) || (branch != "" && !cfg.GetPRs()) {
 			continue
 		}
 		// Check if the config applies to this 'org[/repo][/branch]'.
@@ -185,23 +322,41 @@ func matchingConfigs(org, repo, branch, label string, allConfigs []plugins.Requi
 			(cfg.Branch != "" && branch != "" && cfg.Branch != branch) {
 			continue
 		}
-		// If we are reacting to a label event, see if it is relevant.
-		if label != "" && !cfg.Re.MatchString(label) {
+		// If we are reacting to a label event, see if it is relevant. Any label
+		// is relevant to whether the issue has no labels at all.
+		if label != "" && !cfg.Matches(label) && cfg.OnNoLabels == "" && !isExclusive(cfg.MutuallyExclusive, label) {
+			continue
+		}
+		// Assignment changes are only relevant if the config considers assignees.
+		if assigneeChanged && len(cfg.OrAssignees) == 0 {
 			continue
 		}
-		filtered = append(filtered, cfg)
+		// Changes to the PR's files are only relevant if the config considers them.
+		if filesChanged && cfg.GetMaxChangedFiles() == 0 {
+			continue
+		}
+		// Review request changes are only relevant if the config opted in to them.
+		if reviewRequestChanged && !cfg.GetReviewRequests() {
+			continue
+		}
+		// Submitted reviews are only relevant if the config opted in to them.
+		if reviewSubmitted && !cfg.GetReviews() {
+			continue
+		}
+		// Use the missing label and comment specific to the issue type.
+		filtered = append(filtered, cfg.ForKind(branch != ""))
 	}
 	return filtered
 }
 
-func handle(log *logrus.Entry, ghc githubClient, cp commentPruner, configs []plugins.RequireMatchingLabel, e *event) error {
+func handle(log *logrus.Entry, ghc githubClient, cp commentPruner, clk clock.PassiveClock, configs []plugins.RequireMatchingLabel, e *event) error {
 	// Find any configs that may be relevant to this event.
-	matchConfigs := matchingConfigs(e.org, e.repo, e.branch, e.label, configs)
+	matchConfigs := matchingConfigs(e.org, e.repo, e.branch, e.label, e.assigneeChanged, e.filesChanged, e.reviewRequestChanged, e.reviewSubmitted, configs)
 	if len(matchConfigs) == 0 {
 		return nil
 	}
 
-	if e.label == "" /* not a label event */ {
+	if e.label == "" && !e.assigneeChanged && !e.filesChanged && !e.reviewRequestChanged && !e.reviewSubmitted /* only open or reopen events */ {
 		// If we are reacting to a PR or Issue being created or reopened, we should wait a
 		// few seconds to allow other automation to apply labels in order to minimize thrashing.
 		// We use the max grace period from applicable configs.
@@ -212,49 +367,654 @@ func handle(log *logrus.Entry, ghc githubClient, cp commentPruner, configs []plu
 			}
 		}
 		time.Sleep(gracePeriod)
-		// If currentLabels was populated it is now stale.
+		// If currentLabels or assignees were populated they are now stale.
 		e.currentLabels = nil
+		e.assignees = nil
+	}
+	s, err := loadState(log, ghc, clk, matchConfigs, e)
+	if err != nil {
+		return err
 	}
+	apply(log, ghc, cp, e, Evaluate(matchConfigs, s))
+	return nil
+}
+
+// State describes an issue or PR that the configs are evaluated against.
+type State struct {
+	labels    []github.Label
+	assignees []github.User
+	// additions holds the most recent addition of every label. It is only
+	// populated if any of the configs consider who added labels or when.
+	additions map[string]labelAddition
+	// linkedLabels holds the labels of the issues that a PR closes. It is only
+	// populated if any of the configs consider them.
+	linkedLabels []github.Label
+	// parentLabels holds the labels of the parent issue by the ParentMarker
+	// that references it. It is only populated if any of the configs consider
+	// them.
+	parentLabels map[string][]github.Label
+	// projectColumns holds the name of the column of the card of the issue or
+	// PR by the name of the project. It is only populated for the projects
+	// that any of the configs consider and that the issue or PR is part of.
+	projectColumns map[string]string
+	// changedFiles is the number of files changed by a PR, or -1 if none of
+	// the configs consider it.
+	changedFiles int
+	// comments and created are the comments on the issue or PR and the time
+	// it was created. They are only populated if any of the configs
+	// re-notify about missing labels.
+	comments []github.IssueComment
+	created  time.Time
+	now      time.Time
+}
+
+// loadState fetches the parts of the state of the issue or PR of the event
+// that the configs need and that the event does not contain. Disabled configs
+// only consider the labels, so they never cause further API calls.
+func loadState(log *logrus.Entry, ghc githubClient, clk clock.PassiveClock, configs []plugins.RequireMatchingLabel, e *event) (State, error) {
+	configs = enabledConfigs(configs)
+	s := State{changedFiles: -1, now: clk.Now()}
 	if e.currentLabels == nil {
 		var err error
 		e.currentLabels, err = ghc.GetIssueLabels(e.org, e.repo, e.number)
 		if err != nil {
-			return fmt.Errorf("error getting the issue or pr's labels: %w", err)
+			return s, fmt.Errorf("error getting the issue or pr's labels: %w", err)
+		}
+	}
+	s.labels = e.currentLabels
+	if e.assignees == nil && needsAssignees(configs) {
+		issue, err := ghc.GetIssue(e.org, e.repo, e.number)
+		if err != nil {
+			return s, fmt.Errorf("error getting the issue or pr's assignees: %w", err)
+		}
+		e.assignees = issue.Assignees
+	}
+	s.assignees = e.assignees
+	if needsLabelAdditions(configs) {
+		events, err := ghc.ListIssueEvents(e.org, e.repo, e.number)
+		if err != nil {
+			return s, fmt.Errorf("error listing the issue or pr's events: %w", err)
+		}
+		s.additions = map[string]labelAddition{}
+		// Events are listed in chronological order, so the last addition of a label wins.
+		for _, ev := range events {
+			if ev.Event == github.IssueActionLabeled {
+				s.additions[ev.Label.Name] = labelAddition{labeler: ev.Actor.Login, added: ev.CreatedAt}
+			}
+		}
+		// The events API may not yet contain the event we are reacting to.
+		if e.labeler != "" {
+			s.additions[e.label] = labelAddition{labeler: e.labeler, added: s.now}
+		}
+	}
+	if e.body == "" && ((e.branch != "" && needsLinkedIssues(configs)) || needsParentIssues(configs)) {
+		if e.branch != "" {
+			pr, err := ghc.GetPullRequest(e.org, e.repo, e.number)
+			if err != nil {
+				return s, fmt.Errorf("error getting the pr: %w", err)
+			}
+			e.body = pr.Body
+		} else {
+			issue, err := ghc.GetIssue(e.org, e.repo, e.number)
+			if err != nil {
+				return s, fmt.Errorf("error getting the issue: %w", err)
+			}
+			e.body = issue.Body
+		}
+	}
+	if e.branch != "" && needsLinkedIssues(configs) {
+		for _, number := range closingIssues(e.body) {
+			labels, err := ghc.GetIssueLabels(e.org, e.repo, number)
+			if err != nil {
+				log.WithError(err).Warnf("Failed to get the labels of linked issue #%d.", number)
+				continue
+			}
+			s.linkedLabels = append(s.linkedLabels, labels...)
+		}
+	}
+	for _, cfg := range configs {
+		if cfg.ParentMarker == "" || s.parentLabels[cfg.ParentMarker] != nil {
+			continue
+		}
+		number, ok := parentIssue(cfg.ParentMarker, e.body)
+		if !ok || number == e.number {
+			continue
+		}
+		labels, err := ghc.GetIssueLabels(e.org, e.repo, number)
+		if err != nil {
+			log.WithError(err).Warnf("Failed to get the labels of parent issue #%d.", number)
+			continue
+		}
+		if s.parentLabels == nil {
+			s.parentLabels = map[string][]github.Label{}
+		}
+		s.parentLabels[cfg.ParentMarker] = labels
+	}
+	checkedProjects := sets.New[string]()
+	for _, cfg := range configs {
+		if cfg.Project == "" || checkedProjects.Has(cfg.Project) {
+			continue
+		}
+		checkedProjects.Insert(cfg.Project)
+		column, ok, err := projectColumn(ghc, e.org, e.repo, e.number, cfg.Project)
+		if err != nil {
+			log.WithError(err).Warnf("Failed to get the cards of project %q, only considering labels.", cfg.Project)
+			continue
+		}
+		if !ok {
+			continue
+		}
+		if s.projectColumns == nil {
+			s.projectColumns = map[string]string{}
+		}
+		s.projectColumns[cfg.Project] = column
+	}
+	if needsRenotification(configs) {
+		if e.created.IsZero() {
+			issue, err := ghc.GetIssue(e.org, e.repo, e.number)
+			if err != nil {
+				return s, fmt.Errorf("error getting the issue or pr: %w", err)
+			}
+			e.created = issue.CreatedAt
+		}
+		s.created = e.created
+		var err error
+		s.comments, err = ghc.ListIssueComments(e.org, e.repo, e.number)
+		if err != nil {
+			return s, fmt.Errorf("error listing the issue or pr's comments: %w", err)
+		}
+	}
+	if needsChangedFiles(configs) {
+		if e.baseSHA == "" {
+			pr, err := ghc.GetPullRequest(e.org, e.repo, e.number)
+			if err != nil {
+				return s, fmt.Errorf("error getting the pr: %w", err)
+			}
+			e.baseSHA = pr.Base.SHA
+		}
+		var err error
+		s.changedFiles, err = countChangedFiles(log, ghc, e.org, e.repo, e.baseSHA, e.number)
+		if err != nil {
+			return s, fmt.Errorf("error counting the pr's changed files: %w", err)
 		}
 	}
+	return s, nil
+}
 
-	// Handle the potentially relevant configs.
-	for _, cfg := range matchConfigs {
+// Actions describes the changes that the configs require of an issue or PR.
+type Actions struct {
+	// AddLabels are the labels to add.
+	AddLabels []string
+	// RemoveLabels are the labels to remove.
+	RemoveLabels []string
+	// PruneComments are the configured comments whose previous instances are
+	// deleted.
+	PruneComments []string
+	// Comments are the comments to create.
+	Comments []string
+}
+
+// Evaluate returns the actions that the configs require of an issue or PR in
+// the given state. It does not have any side effects, so it can be used to
+// explain the configs without applying them.
+func Evaluate(configs []plugins.RequireMatchingLabel, s State) Actions {
+	var a Actions
+	updatedNoLabels := sets.New[string]()
+	updatedConflicts := sets.New[string]()
+	for _, cfg := range configs {
 		hasMissingLabel := false
-		hasMatchingLabel := false
-		for _, label := range e.currentLabels {
-			hasMissingLabel = hasMissingLabel || label.Name == cfg.MissingLabel
-			hasMatchingLabel = hasMatchingLabel || cfg.Re.MatchString(label.Name)
+		if !cfg.IsEnabled() {
+			for _, label := range s.labels {
+				hasMissingLabel = hasMissingLabel || label.Name == cfg.MissingLabel
+			}
+			// Clean up the label that the config may have applied before it was
+			// disabled, unless an enabled config applies the same label.
+			if hasMissingLabel && !appliesLabel(configs, cfg.MissingLabel) {
+				a.RemoveLabels = append(a.RemoveLabels, cfg.MissingLabel)
+			}
+			continue
 		}
-
-		if hasMatchingLabel && hasMissingLabel {
-			if err := ghc.RemoveLabel(e.org, e.repo, e.number, cfg.MissingLabel); err != nil {
-				log.WithError(err).Errorf("Failed to remove %q label.", cfg.MissingLabel)
+		if cfg.OnNoLabels != "" && !updatedNoLabels.Has(cfg.OnNoLabels) {
+			a.updateNoLabels(s.labels, cfg.OnNoLabels, configs)
+			updatedNoLabels.Insert(cfg.OnNoLabels)
+		}
+		if len(cfg.MutuallyExclusive) > 0 && !updatedConflicts.Has(cfg.ConflictLabel) {
+			a.updateConflictLabel(s.labels, cfg)
+			updatedConflicts.Insert(cfg.ConflictLabel)
+		}
+		// The missing label may be present in the form of an alias.
+		missingLabel := cfg.MissingLabel
+		var matchingLabels []string
+		for _, label := range s.labels {
+			if cfg.IsMissingLabel(label.Name) {
+				hasMissingLabel = true
+				missingLabel = label.Name
+			}
+			if cfg.Matches(label.Name) && s.additions[label.Name].counts(cfg, s.now) {
+				matchingLabels = append(matchingLabels, label.Name)
+			}
+		}
+		if cfg.GetLinkedIssues() {
+			for _, label := range s.linkedLabels {
+				if cfg.Matches(label.Name) {
+					matchingLabels = append(matchingLabels, label.Name)
+				}
+			}
+		}
+		for _, label := range s.parentLabels[cfg.ParentMarker] {
+			if cfg.Matches(label.Name) {
+				matchingLabels = append(matchingLabels, label.Name)
 			}
+		}
+		hasMatchingLabel := len(matchingLabels) > 0
+		if len(cfg.Families) > 0 {
+			hasMatchingLabel = cfg.MatchedFamilies(matchingLabels) >= cfg.GetMinFamilies()
+		}
+		satisfied := hasMatchingLabel || hasAnyAssignee(cfg.OrAssignees, s.assignees) ||
+			(cfg.GetMaxChangedFiles() > 0 && s.changedFiles <= cfg.GetMaxChangedFiles()) ||
+			isInProject(cfg, s.projectColumns)
+
+		if satisfied && hasMissingLabel {
+			a.RemoveLabels = append(a.RemoveLabels, missingLabel)
 			if cfg.MissingComment != "" {
-				cp.PruneComments(func(comment github.IssueComment) bool {
-					return strings.Contains(comment.Body, cfg.MissingComment)
-				})
+				a.PruneComments = append(a.PruneComments, cfg.MissingComment)
+			}
+			if cfg.SatisfiedComment != "" {
+				a.Comments = append(a.Comments, cfg.SatisfiedComment)
 			}
-		} else if !hasMatchingLabel && !hasMissingLabel {
-			if err := ghc.AddLabel(e.org, e.repo, e.number, cfg.MissingLabel); err != nil {
-				log.WithError(err).Errorf("Failed to add %q label.", cfg.MissingLabel)
+		} else if !satisfied && !hasMissingLabel {
+			a.AddLabels = append(a.AddLabels, cfg.MissingLabel)
+			if cfg.SatisfiedComment != "" {
+				a.PruneComments = append(a.PruneComments, cfg.SatisfiedComment)
 			}
 			if cfg.MissingComment != "" {
-				msg := plugins.FormatSimpleResponse(cfg.MissingComment)
-				if err := ghc.CreateComment(e.org, e.repo, e.number, msg); err != nil {
-					log.WithError(err).Error("Failed to create comment.")
+				a.Comments = append(a.Comments, cfg.MissingComment)
+			}
+		} else if !satisfied && cfg.RenotifyAfterDuration > 0 && cfg.MissingComment != "" && renotifies(cfg, s) {
+			a.Comments = append(a.Comments, cfg.MissingComment)
+		}
+	}
+	return a
+}
+
+// renotifies returns true if the MissingComment of the config is due to be
+// posted again on an issue or PR that is still missing a matching label. The
+// continued non-compliance is measured since the comment was last posted, or
+// since the issue or PR was created if the comment is not present. Earlier
+// instances of the comment are kept, as they count the renotifications.
+func renotifies(cfg plugins.RequireMatchingLabel, s State) bool {
+	posted := 0
+	last := s.created
+	for _, comment := range s.comments {
+		if !strings.Contains(comment.Body, cfg.MissingComment) {
+			continue
+		}
+		posted++
+		if comment.CreatedAt.After(last) {
+			last = comment.CreatedAt
+		}
+	}
+	// The first instance of the comment is the initial notification.
+	if posted > cfg.GetMaxRenotifications() {
+		return false
+	}
+	return s.now.Sub(last) >= cfg.RenotifyAfterDuration
+}
+
+// updateNoLabels applies the label to the issue or PR if it has no other labels
+// and removes it otherwise. The missing labels of the configs are not counted,
+// as they may be applied just because the issue or PR has no labels.
+func (a *Actions) updateNoLabels(labels []github.Label, noLabels string, configs []plugins.RequireMatchingLabel) {
+	hasNoLabels := false
+	hasOtherLabels := false
+	for _, label := range labels {
+		if label.Name == noLabels {
+			hasNoLabels = true
+		} else if !isMissingLabel(configs, label.Name) {
+			hasOtherLabels = true
+		}
+	}
+	if hasOtherLabels && hasNoLabels {
+		a.RemoveLabels = append(a.RemoveLabels, noLabels)
+	} else if !hasOtherLabels && !hasNoLabels {
+		a.AddLabels = append(a.AddLabels, noLabels)
+	}
+}
+
+// updateConflictLabel applies the ConflictLabel of the config to the issue or PR
+// if it has more than one label of any group of MutuallyExclusive and removes
+// it otherwise.
+func (a *Actions) updateConflictLabel(labels []github.Label, cfg plugins.RequireMatchingLabel) {
+	present := sets.New[string]()
+	for _, label := range labels {
+		present.Insert(label.Name)
+	}
+	hasConflict := false
+	for _, group := range cfg.MutuallyExclusive {
+		hasConflict = hasConflict || present.Intersection(sets.New[string](group...)).Len() > 1
+	}
+	hasLabel := present.Has(cfg.ConflictLabel)
+	if hasConflict && !hasLabel {
+		a.AddLabels = append(a.AddLabels, cfg.ConflictLabel)
+		if cfg.ConflictComment != "" {
+			a.Comments = append(a.Comments, cfg.ConflictComment)
+		}
+	} else if !hasConflict && hasLabel {
+		a.RemoveLabels = append(a.RemoveLabels, cfg.ConflictLabel)
+		if cfg.ConflictComment != "" {
+			a.PruneComments = append(a.PruneComments, cfg.ConflictComment)
+		}
+	}
+}
+
+// isExclusive returns true if the label is part of any of the groups.
+func isExclusive(groups [][]string, label string) bool {
+	for _, group := range groups {
+		for _, l := range group {
+			if l == label {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// apply applies the actions to the issue or PR of the event.
+func apply(log *logrus.Entry, ghc githubClient, cp commentPruner, e *event, a Actions) {
+	for _, label := range a.RemoveLabels {
+		if err := ghc.RemoveLabel(e.org, e.repo, e.number, label); err != nil {
+			log.WithError(err).Errorf("Failed to remove %q label.", label)
+		}
+	}
+	for _, label := range a.AddLabels {
+		if err := ghc.AddLabel(e.org, e.repo, e.number, label); err != nil {
+			log.WithError(err).Errorf("Failed to add %q label.", label)
+		}
+	}
+	if len(a.PruneComments) > 0 {
+		cp.PruneComments(func(comment github.IssueComment) bool {
+			for _, body := range a.PruneComments {
+				if strings.Contains(comment.Body, body) {
+					return true
 				}
 			}
+			return false
+		})
+	}
+	for _, comment := range a.Comments {
+		if err := ghc.CreateComment(e.org, e.repo, e.number, plugins.FormatSimpleResponse(comment)); err != nil {
+			log.WithError(err).Error("Failed to create comment.")
 		}
+	}
+}
 
+// isMissingLabel returns true if the label is the missing label of any of the configs.
+func isMissingLabel(configs []plugins.RequireMatchingLabel, label string) bool {
+	for _, cfg := range configs {
+		if cfg.IsMissingLabel(label) {
+			return true
+		}
 	}
-	return nil
+	return false
+}
+
+// appliesLabel returns true if any of the enabled configs applies the label.
+func appliesLabel(configs []plugins.RequireMatchingLabel, label string) bool {
+	for _, cfg := range configs {
+		if cfg.IsEnabled() && cfg.MissingLabel == label {
+			return true
+		}
+	}
+	return false
+}
+
+// enabledConfigs returns the configs that are enabled.
+func enabledConfigs(configs []plugins.RequireMatchingLabel) []plugins.RequireMatchingLabel {
+	var enabled []plugins.RequireMatchingLabel
+	for _, cfg := range configs {
+		if cfg.IsEnabled() {
+			enabled = append(enabled, cfg)
+		}
+	}
+	return enabled
+}
+
+// needsAssignees returns true if any of the configs consider assignees.
+func needsAssignees(configs []plugins.RequireMatchingLabel) bool {
+	for _, cfg := range configs {
+		if len(cfg.OrAssignees) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// projectColumn returns the name of the column of the card of the issue or PR
+// in the project with the given name of the repo or org, and false if the
+// issue or PR is not part of the project.
+func projectColumn(ghc githubClient, org, repo string, number int, project string) (string, bool, error) {
+	projects, err := ghc.GetRepoProjects(org, repo)
+	if err != nil {
+		return "", false, fmt.Errorf("error getting the projects of %s/%s: %w", org, repo, err)
+	}
+	projectID, found := 0, false
+	for _, p := range projects {
+		if p.Name == project {
+			projectID, found = p.ID, true
+			break
+		}
+	}
+	if !found {
+		projects, err := ghc.GetOrgProjects(org)
+		if err != nil {
+			return "", false, fmt.Errorf("error getting the projects of %s: %w", org, err)
+		}
+		for _, p := range projects {
+			if p.Name == project {
+				projectID, found = p.ID, true
+				break
+			}
+		}
+	}
+	if !found {
+		return "", false, fmt.Errorf("project %q not found in %s/%s or %s", project, org, repo, org)
+	}
+	columns, err := ghc.GetProjectColumns(org, projectID)
+	if err != nil {
+		return "", false, fmt.Errorf("error getting the columns of project %q: %w", project, err)
+	}
+	// Cards reference issues and PRs by the API URL of the issue.
+	issueURL := fmt.Sprintf("https://api.github.com/repos/%s/%s/issues/%v", org, repo, number)
+	for _, column := range columns {
+		card, err := ghc.GetColumnProjectCard(org, column.ID, issueURL)
+		if err != nil {
+			return "", false, fmt.Errorf("error getting the cards of column %q of project %q: %w", column.Name, project, err)
+		}
+		if card != nil {
+			return column.Name, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// isInProject returns true if the issue or PR has a card in the Project of the
+// config, in any of its ProjectColumns if specified.
+func isInProject(cfg plugins.RequireMatchingLabel, projectColumns map[string]string) bool {
+	if cfg.Project == "" {
+		return false
+	}
+	column, ok := projectColumns[cfg.Project]
+	if !ok {
+		return false
+	}
+	if len(cfg.ProjectColumns) == 0 {
+		return true
+	}
+	for _, c := range cfg.ProjectColumns {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
+
+// needsRenotification returns true if any of the configs re-notify about
+// missing labels.
+func needsRenotification(configs []plugins.RequireMatchingLabel) bool {
+	for _, cfg := range configs {
+		if cfg.RenotifyAfterDuration > 0 && cfg.MissingComment != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// needsChangedFiles returns true if any of the configs consider the number of changed files.
+func needsChangedFiles(configs []plugins.RequireMatchingLabel) bool {
+	for _, cfg := range configs {
+		if cfg.GetMaxChangedFiles() > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// labelAddition describes the most recent addition of a label. Its fields are
+// empty if the addition is unknown.
+type labelAddition struct {
+	labeler string
+	added   time.Time
+}
+
+// counts returns true unless the config ignores labels added by the labeler or
+// labels added before the config's MaxLabelAge. Labels without a known
+// addition time are counted regardless of their age.
+func (a labelAddition) counts(cfg plugins.RequireMatchingLabel, now time.Time) bool {
+	if isIgnoredLabeler(cfg.IgnoredLabelers, a.labeler) {
+		return false
+	}
+	return cfg.MaxLabelAgeDuration == 0 || a.added.IsZero() || now.Sub(a.added) <= cfg.MaxLabelAgeDuration
+}
+
+// needsLabelAdditions returns true if any of the configs consider who added
+// labels or when.
+func needsLabelAdditions(configs []plugins.RequireMatchingLabel) bool {
+	for _, cfg := range configs {
+		if len(cfg.IgnoredLabelers) > 0 || cfg.MaxLabelAgeDuration > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// isIgnoredLabeler returns true if the login is among the ignored labelers.
+func isIgnoredLabeler(ignored []string, login string) bool {
+	if login == "" {
+		return false
+	}
+	for _, labeler := range ignored {
+		if github.NormLogin(labeler) == github.NormLogin(login) {
+			return true
+		}
+	}
+	return false
+}
+
+// needsLinkedIssues returns true if any of the configs consider the labels of linked issues.
+func needsLinkedIssues(configs []plugins.RequireMatchingLabel) bool {
+	for _, cfg := range configs {
+		if cfg.GetLinkedIssues() {
+			return true
+		}
+	}
+	return false
+}
+
+// needsParentIssues returns true if any of the configs consider the labels of parent issues.
+func needsParentIssues(configs []plugins.RequireMatchingLabel) bool {
+	for _, cfg := range configs {
+		if cfg.ParentMarker != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// parentIssue returns the number of the parent issue that is referenced in the
+// description with the marker, e.g. 'Parent: #123' for the marker 'Parent:'.
+func parentIssue(marker, body string) (int, bool) {
+	re, err := regexp.Compile(`(?mi)^\s*` + regexp.QuoteMeta(marker) + `\s*#(\d+)\b`)
+	if err != nil {
+		return 0, false
+	}
+	match := re.FindStringSubmatch(body)
+	if match == nil {
+		return 0, false
+	}
+	number, err := strconv.Atoi(match[1])
+	if err != nil {
+		return 0, false
+	}
+	return number, true
+}
+
+// closingIssues returns the numbers of the issues that a PR with the given
+// description closes, e.g. with 'Fixes #123'.
+func closingIssues(body string) []int {
+	var numbers []int
+	seen := sets.New[int]()
+	for _, match := range closingIssueRe.FindAllStringSubmatch(body, -1) {
+		number, err := strconv.Atoi(match[1])
+		if err != nil || seen.Has(number) {
+			continue
+		}
+		seen.Insert(number)
+		numbers = append(numbers, number)
+	}
+	return numbers
+}
+
+// countChangedFiles counts the files changed by a PR, skipping generated and
+// linguist-generated files.
+func countChangedFiles(log *logrus.Entry, ghc githubClient, org, repo, sha string, number int) (int, error) {
+	gf, err := genfiles.NewGroup(ghc, org, repo, sha)
+	if err != nil {
+		switch err.(type) {
+		case *genfiles.ParseError:
+			// Continue on parse errors, but warn that something is wrong.
+			log.Warnf("error while parsing .generated_files: %v", err)
+		default:
+			return 0, err
+		}
+	}
+	ga, err := gitattributes.NewGroup(func() ([]byte, error) { return ghc.GetFile(org, repo, ".gitattributes", sha) })
+	if err != nil {
+		return 0, err
+	}
+	changes, err := ghc.GetPullRequestChanges(org, repo, number)
+	if err != nil {
+		return 0, err
+	}
+	var count int
+	for _, change := range changes {
+		if gf.Match(change.Filename) || ga.IsLinguistGenerated(change.Filename) {
+			continue
+		}
+		count++
+	}
+	return count, nil
+}
+
+// hasAnyAssignee returns true if any of the logins is among the assignees.
+func hasAnyAssignee(logins []string, assignees []github.User) bool {
+	for _, login := range logins {
+		for _, assignee := range assignees {
+			if github.NormLogin(login) == github.NormLogin(assignee.Login) {
+				return true
+			}
+		}
+	}
+	return false
 }
 
 func handleCommentEvent(pc plugins.Agent, ce github.GenericCommentEvent) error {
@@ -262,6 +1022,9 @@ func handleCommentEvent(pc plugins.Agent, ce github.GenericCommentEvent) error {
 	if ce.IssueState != "open" || ce.Action != github.GenericCommentActionCreated {
 		return nil
 	}
+	if wouldRequireLabelsRe.MatchString(ce.Body) {
+		return handleDryRun(pc.Logger, pc.GitHubClient, pc.PluginConfig.RequireMatchingLabel, &ce)
+	}
 	// Only consider "/check-required-labels" comments.
 	if !checkRequireLabelsRe.MatchString(ce.Body) {
 		return nil
@@ -276,22 +1039,94 @@ func handleCommentEvent(pc plugins.Agent, ce github.GenericCommentEvent) error {
 }
 
 func handleComment(log *logrus.Entry, ghc githubClient, cp commentPruner, configs []plugins.RequireMatchingLabel, e *github.GenericCommentEvent) error {
-	org := e.Repo.Owner.Login
-	repo := e.Repo.Name
-	number := e.Number
+	event, err := commentEvent(ghc, e)
+	if err != nil {
+		return err
+	}
+	return handle(log, ghc, cp, clock.RealClock{}, configs, event)
+}
 
+// commentEvent returns the event for checking the required labels of the issue
+// or PR that was commented on, which is handled like an open event.
+func commentEvent(ghc githubClient, e *github.GenericCommentEvent) (*event, error) {
 	event := &event{
-		org:    org,
-		repo:   repo,
-		number: number,
+		org:    e.Repo.Owner.Login,
+		repo:   e.Repo.Name,
+		number: e.Number,
 		author: e.User.Login,
 	}
 	if e.IsPR {
-		pr, err := ghc.GetPullRequest(org, repo, number)
+		pr, err := ghc.GetPullRequest(event.org, event.repo, event.number)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		event.branch = pr.Base.Ref
+		event.baseSHA = pr.Base.SHA
+		event.body = pr.Body
+	}
+	return event, nil
+}
+
+// handleDryRun replies to a comment with the actions that checking the required
+// labels of the issue or PR would take, without taking them. Only maintainers
+// of the repo may do so.
+func handleDryRun(log *logrus.Entry, ghc githubClient, configs []plugins.RequireMatchingLabel, ce *github.GenericCommentEvent) error {
+	org := ce.Repo.Owner.Login
+	repo := ce.Repo.Name
+	maintainer, err := ghc.HasPermission(org, repo, ce.User.Login, string(github.Admin), string(github.Maintain), string(github.Write))
+	if err != nil {
+		return fmt.Errorf("error checking the permissions of %s: %w", ce.User.Login, err)
+	}
+	if !maintainer {
+		resp := "only maintainers of this repo can use `/would-require-labels`."
+		return ghc.CreateComment(org, repo, ce.Number, plugins.FormatResponseRaw(ce.Body, ce.HTMLURL, ce.User.Login, resp))
+	}
+	e, err := commentEvent(ghc, ce)
+	if err != nil {
+		return err
+	}
+	matchConfigs := matchingConfigs(e.org, e.repo, e.branch, e.label, false, false, false, false, configs)
+	s, err := loadState(log, ghc, clock.RealClock{}, matchConfigs, e)
+	if err != nil {
+		return err
+	}
+	resp := explainActions(ce.IsPR, Evaluate(matchConfigs, s))
+	return ghc.CreateComment(org, repo, ce.Number, plugins.FormatResponseRaw(ce.Body, ce.HTMLURL, ce.User.Login, resp))
+}
+
+// explainActions describes the actions in a reply to a comment.
+func explainActions(isPR bool, a Actions) string {
+	kind := "issue"
+	if isPR {
+		kind = "PR"
+	}
+	var steps []string
+	if len(a.AddLabels) > 0 {
+		steps = append(steps, fmt.Sprintf("- add the %s label(s)", formatLabels(a.AddLabels)))
+	}
+	if len(a.RemoveLabels) > 0 {
+		steps = append(steps, fmt.Sprintf("- remove the %s label(s)", formatLabels(a.RemoveLabels)))
+	}
+	for _, comment := range a.PruneComments {
+		steps = append(steps, fmt.Sprintf("- delete earlier comments containing %q", comment))
+	}
+	for _, comment := range a.Comments {
+		steps = append(steps, fmt.Sprintf("- post the comment %q", comment))
+	}
+	if len(steps) == 0 {
+		return fmt.Sprintf("checking the required labels of this %s would not change any labels or post a comment.", kind)
+	}
+	if len(a.Comments) == 0 {
+		steps = append(steps, "- not post a comment")
+	}
+	return fmt.Sprintf("checking the required labels of this %s would:\n%s", kind, strings.Join(steps, "\n"))
+}
+
+// formatLabels returns the sorted, deduplicated labels as a list of code spans.
+func formatLabels(labels []string) string {
+	var formatted []string
+	for _, label := range sets.List(sets.New[string](labels...)) {
+		formatted = append(formatted, fmt.Sprintf("`%s`", label))
 	}
-	return handle(log, ghc, cp, configs, event)
+	return strings.Join(formatted, ", ")
 }
@@ -17,11 +17,18 @@ limitations under the License.
 package requirematchinglabel
 
 import (
+	"errors"
+	"reflect"
 	"regexp"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/utils/clock"
+	clocktesting "k8s.io/utils/clock/testing"
+	utilpointer "k8s.io/utils/pointer"
 	"sigs.k8s.io/prow/pkg/github"
 	"sigs.k8s.io/prow/pkg/labels"
 	"sigs.k8s.io/prow/pkg/plugins"
@@ -31,6 +38,23 @@ type fakeGitHub struct {
 	labels                               sets.Set[string]
 	IssueLabelsAdded, IssueLabelsRemoved sets.Set[string]
 	commented                            bool
+	comments                             []string
+	assignees                            []string
+	changes                              []github.PullRequestChange
+	files                                map[string][]byte
+	body                                 string
+	issueLabels                          map[int][]string
+	events                               []github.ListedIssueEvent
+	maintainers                          sets.Set[string]
+	created                              time.Time
+	issueComments                        []github.IssueComment
+	// repoProjects and orgProjects hold the projects of the repo and org, and
+	// columns and cards the columns of every project and the content URLs of
+	// the cards of every column by ID.
+	repoProjects, orgProjects []github.Project
+	columns                   map[int][]github.ProjectColumn
+	cards                     map[int][]string
+	projectsErr               error
 }
 
 func newFakeGitHub(initialLabels ...string) *fakeGitHub {
@@ -55,10 +79,18 @@ func (f *fakeGitHub) RemoveLabel(org, repo string, number int, label string) err
 
 func (f *fakeGitHub) CreateComment(org, repo string, number int, content string) error {
 	f.commented = true
+	f.comments = append(f.comments, content)
 	return nil
 }
 
 func (f *fakeGitHub) GetIssueLabels(org, repo string, number int) ([]github.Label, error) {
+	if labels, ok := f.issueLabels[number]; ok {
+		var res []github.Label
+		for _, label := range labels {
+			res = append(res, github.Label{Name: label})
+		}
+		return res, nil
+	}
 	res := make([]github.Label, 0, len(f.labels))
 	for label := range f.labels {
 		res = append(res, github.Label{Name: label})
@@ -67,10 +99,63 @@ func (f *fakeGitHub) GetIssueLabels(org, repo string, number int) ([]github.Labe
 }
 
 func (f *fakeGitHub) GetPullRequest(org, repo string, number int) (*github.PullRequest, error) {
-	res := &github.PullRequest{}
+	res := &github.PullRequest{Body: f.body}
+	return res, nil
+}
+
+func (f *fakeGitHub) GetPullRequestChanges(org, repo string, number int) ([]github.PullRequestChange, error) {
+	return f.changes, nil
+}
+
+func (f *fakeGitHub) GetFile(org, repo, filepath, commit string) ([]byte, error) {
+	content, ok := f.files[filepath]
+	if !ok {
+		return nil, &github.FileNotFound{}
+	}
+	return content, nil
+}
+
+func (f *fakeGitHub) GetIssue(org, repo string, number int) (*github.Issue, error) {
+	res := &github.Issue{Body: f.body, CreatedAt: f.created}
+	for _, assignee := range f.assignees {
+		res.Assignees = append(res.Assignees, github.User{Login: assignee})
+	}
 	return res, nil
 }
 
+func (f *fakeGitHub) ListIssueEvents(org, repo string, num int) ([]github.ListedIssueEvent, error) {
+	return f.events, nil
+}
+
+func (f *fakeGitHub) ListIssueComments(org, repo string, number int) ([]github.IssueComment, error) {
+	return f.issueComments, nil
+}
+
+func (f *fakeGitHub) GetRepoProjects(owner, repo string) ([]github.Project, error) {
+	return f.repoProjects, f.projectsErr
+}
+
+func (f *fakeGitHub) GetOrgProjects(org string) ([]github.Project, error) {
+	return f.orgProjects, f.projectsErr
+}
+
+func (f *fakeGitHub) GetProjectColumns(org string, projectID int) ([]github.ProjectColumn, error) {
+	return f.columns[projectID], nil
+}
+
+func (f *fakeGitHub) GetColumnProjectCard(org string, columnID int, contentURL string) (*github.ProjectCard, error) {
+	for i, url := range f.cards[columnID] {
+		if url == contentURL {
+			return &github.ProjectCard{ID: i, ContentURL: url}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (f *fakeGitHub) HasPermission(org, repo, user string, roles ...string) (bool, error) {
+	return f.maintainers.Has(user), nil
+}
+
 type fakePruner struct{}
 
 func (fp *fakePruner) PruneComments(shouldPrune func(github.IssueComment) bool) {}
@@ -80,7 +165,7 @@ func TestHandle(t *testing.T) {
 		// needs-sig over k8s org (issues)
 		{
 			Org:          "k8s",
-			Issues:       true,
+			Issues:       utilpointer.Bool(true),
 			Re:           regexp.MustCompile(`^(sig|wg|committee)/`),
 			MissingLabel: "needs-sig",
 		},
@@ -89,7 +174,7 @@ func TestHandle(t *testing.T) {
 		{
 			Org:          "k8s",
 			Repo:         "t-i",
-			PRs:          true,
+			PRs:          utilpointer.Bool(true),
 			Re:           regexp.MustCompile(`^kind/`),
 			MissingLabel: "needs-kind",
 		},
@@ -98,8 +183,8 @@ func TestHandle(t *testing.T) {
 			Org:            "k8s",
 			Repo:           "t-i",
 			Branch:         "meow",
-			Issues:         true,
-			PRs:            true,
+			Issues:         utilpointer.Bool(true),
+			PRs:            utilpointer.Bool(true),
 			Re:             regexp.MustCompile(`^(cat|floof|loaf)$`),
 			MissingLabel:   "needs-cat",
 			MissingComment: "Meow?",
@@ -251,7 +336,7 @@ func TestHandle(t *testing.T) {
 		t.Logf("Running test case %q...", tc.name)
 		log := logrus.WithField("plugin", "require-matching-label")
 		fghc := newFakeGitHub(tc.initialLabels...)
-		if err := handle(log, fghc, &fakePruner{}, configs, tc.event); err != nil {
+		if err := handle(log, fghc, &fakePruner{}, clock.RealClock{}, configs, tc.event); err != nil {
 			t.Fatalf("Unexpected error from handle: %v.", err)
 		}
 
@@ -270,3 +355,1580 @@ func TestHandle(t *testing.T) {
 		}
 	}
 }
+
+func TestHandleOrAssignees(t *testing.T) {
+	configs := []plugins.RequireMatchingLabel{
+		{
+			Org:          "k8s",
+			Issues:       utilpointer.Bool(true),
+			Re:           regexp.MustCompile(`^sig/`),
+			OrAssignees:  []string{"sig-lead"},
+			MissingLabel: "needs-sig",
+		},
+	}
+
+	tcs := []struct {
+		name          string
+		event         *event
+		initialLabels []string
+		assignees     []string
+
+		expectedAdded   sets.Set[string]
+		expectedRemoved sets.Set[string]
+	}{
+		{
+			name: "matching label only satisfies requirement",
+			event: &event{
+				org:  "k8s",
+				repo: "k8s",
+			},
+			initialLabels: []string{"sig/node"},
+		},
+		{
+			name: "assignee only satisfies requirement",
+			event: &event{
+				org:  "k8s",
+				repo: "k8s",
+			},
+			assignees: []string{"Sig-Lead"},
+		},
+		{
+			name: "neither label nor assignee adds missing label",
+			event: &event{
+				org:  "k8s",
+				repo: "k8s",
+			},
+			assignees:     []string{"someone-else"},
+			expectedAdded: sets.New[string]("needs-sig"),
+		},
+		{
+			name: "assigning configured assignee removes missing label",
+			event: &event{
+				org:             "k8s",
+				repo:            "k8s",
+				assigneeChanged: true,
+			},
+			initialLabels:   []string{"needs-sig"},
+			assignees:       []string{"sig-lead"},
+			expectedRemoved: sets.New[string]("needs-sig"),
+		},
+		{
+			name: "unassigning configured assignee adds missing label",
+			event: &event{
+				org:             "k8s",
+				repo:            "k8s",
+				assigneeChanged: true,
+			},
+			expectedAdded: sets.New[string]("needs-sig"),
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			log := logrus.WithField("plugin", "require-matching-label")
+			fghc := newFakeGitHub(tc.initialLabels...)
+			fghc.assignees = tc.assignees
+			if err := handle(log, fghc, &fakePruner{}, clock.RealClock{}, configs, tc.event); err != nil {
+				t.Fatalf("Unexpected error from handle: %v.", err)
+			}
+			if !tc.expectedAdded.Equal(fghc.IssueLabelsAdded) {
+				t.Errorf("Expected the %q labels to be added, but got %q.", sets.List(tc.expectedAdded), sets.List(fghc.IssueLabelsAdded))
+			}
+			if !tc.expectedRemoved.Equal(fghc.IssueLabelsRemoved) {
+				t.Errorf("Expected the %q labels to be removed, but got %q.", sets.List(tc.expectedRemoved), sets.List(fghc.IssueLabelsRemoved))
+			}
+		})
+	}
+}
+
+func TestHandleMaxChangedFiles(t *testing.T) {
+	configs := []plugins.RequireMatchingLabel{
+		{
+			Org:             "k8s",
+			PRs:             utilpointer.Bool(true),
+			Re:              regexp.MustCompile(`^split-not-needed$`),
+			MaxChangedFiles: utilpointer.Int(3),
+			MissingLabel:    "needs-split",
+		},
+	}
+	changes := func(files ...string) []github.PullRequestChange {
+		var res []github.PullRequestChange
+		for _, file := range files {
+			res = append(res, github.PullRequestChange{Filename: file})
+		}
+		return res
+	}
+
+	tcs := []struct {
+		name          string
+		event         *event
+		initialLabels []string
+		changes       []github.PullRequestChange
+		// generatedFiles overrides the .generated_files of the repo.
+		generatedFiles string
+
+		expectedAdded   sets.Set[string]
+		expectedRemoved sets.Set[string]
+	}{
+		{
+			name: "above the threshold adds missing label",
+			event: &event{
+				org:    "k8s",
+				repo:   "k8s",
+				branch: "master",
+			},
+			changes:       changes("a", "b", "c", "d"),
+			expectedAdded: sets.New[string]("needs-split"),
+		},
+		{
+			name: "at the threshold is satisfied",
+			event: &event{
+				org:    "k8s",
+				repo:   "k8s",
+				branch: "master",
+			},
+			changes: changes("a", "b", "c"),
+		},
+		{
+			name: "generated files are not counted",
+			event: &event{
+				org:    "k8s",
+				repo:   "k8s",
+				branch: "master",
+			},
+			changes: changes("a", "b", "c", "zz_generated.deepcopy.go"),
+		},
+		{
+			name: "generated files before an invalid .generated_files line are not counted",
+			event: &event{
+				org:    "k8s",
+				repo:   "k8s",
+				branch: "master",
+			},
+			changes:        changes("a", "b", "c", "zz_generated.deepcopy.go"),
+			generatedFiles: "file-prefix zz_generated.\ninvalid line here",
+		},
+		{
+			name: "above the threshold with override label is satisfied",
+			event: &event{
+				org:    "k8s",
+				repo:   "k8s",
+				branch: "master",
+			},
+			initialLabels: []string{"split-not-needed"},
+			changes:       changes("a", "b", "c", "d"),
+		},
+		{
+			name: "pushing below the threshold removes missing label",
+			event: &event{
+				org:          "k8s",
+				repo:         "k8s",
+				branch:       "master",
+				filesChanged: true,
+			},
+			initialLabels:   []string{"needs-split"},
+			changes:         changes("a", "b"),
+			expectedRemoved: sets.New[string]("needs-split"),
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			log := logrus.WithField("plugin", "require-matching-label")
+			fghc := newFakeGitHub(tc.initialLabels...)
+			fghc.changes = tc.changes
+			generatedFiles := "file-prefix zz_generated."
+			if tc.generatedFiles != "" {
+				generatedFiles = tc.generatedFiles
+			}
+			fghc.files = map[string][]byte{".generated_files": []byte(generatedFiles)}
+			if err := handle(log, fghc, &fakePruner{}, clock.RealClock{}, configs, tc.event); err != nil {
+				t.Fatalf("Unexpected error from handle: %v.", err)
+			}
+			if !tc.expectedAdded.Equal(fghc.IssueLabelsAdded) {
+				t.Errorf("Expected the %q labels to be added, but got %q.", sets.List(tc.expectedAdded), sets.List(fghc.IssueLabelsAdded))
+			}
+			if !tc.expectedRemoved.Equal(fghc.IssueLabelsRemoved) {
+				t.Errorf("Expected the %q labels to be removed, but got %q.", sets.List(tc.expectedRemoved), sets.List(fghc.IssueLabelsRemoved))
+			}
+		})
+	}
+}
+
+func TestHandleSatisfiedComment(t *testing.T) {
+	configs := []plugins.RequireMatchingLabel{
+		{
+			Org:              "k8s",
+			Issues:           utilpointer.Bool(true),
+			Re:               regexp.MustCompile(`^sig/`),
+			MissingLabel:     "needs-sig",
+			SatisfiedComment: "Thanks for adding a sig!",
+		},
+	}
+	log := logrus.WithField("plugin", "require-matching-label")
+	fghc := newFakeGitHub("needs-sig")
+	e := &event{org: "k8s", repo: "k8s"}
+
+	// The issue is unsatisfied and already has the missing label.
+	if err := handle(log, fghc, &fakePruner{}, clock.RealClock{}, configs, e); err != nil {
+		t.Fatalf("Unexpected error from handle: %v.", err)
+	}
+	if len(fghc.comments) != 0 {
+		t.Fatalf("Expected no comments while unsatisfied, got %q.", fghc.comments)
+	}
+
+	// Adding a matching label satisfies the requirement.
+	fghc.labels.Insert("sig/node")
+	for i := 0; i < 2; i++ {
+		e := &event{org: "k8s", repo: "k8s", label: "sig/node"}
+		if err := handle(log, fghc, &fakePruner{}, clock.RealClock{}, configs, e); err != nil {
+			t.Fatalf("Unexpected error from handle: %v.", err)
+		}
+	}
+	if !fghc.IssueLabelsRemoved.Equal(sets.New[string]("needs-sig")) {
+		t.Errorf("Expected the missing label to be removed, but got %q.", sets.List(fghc.IssueLabelsRemoved))
+	}
+	if len(fghc.comments) != 1 {
+		t.Fatalf("Expected exactly one comment, got %q.", fghc.comments)
+	}
+	if !strings.Contains(fghc.comments[0], "Thanks for adding a sig!") {
+		t.Errorf("Expected the satisfied comment, got %q.", fghc.comments[0])
+	}
+}
+
+func TestHandleReviewRequests(t *testing.T) {
+	tcs := []struct {
+		name           string
+		reviewRequests bool
+		initialLabels  []string
+
+		expectedAdded   sets.Set[string]
+		expectedRemoved sets.Set[string]
+	}{
+		{
+			name:           "review request adds missing label",
+			reviewRequests: true,
+			expectedAdded:  sets.New[string]("needs-triage"),
+		},
+		{
+			name:            "review request removes missing label",
+			reviewRequests:  true,
+			initialLabels:   []string{"needs-triage", "triage/accepted"},
+			expectedRemoved: sets.New[string]("needs-triage"),
+		},
+		{
+			name: "review request is ignored unless configured",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			configs := []plugins.RequireMatchingLabel{
+				{
+					Org:            "k8s",
+					PRs:            utilpointer.Bool(true),
+					Re:             regexp.MustCompile(`^triage/`),
+					MissingLabel:   "needs-triage",
+					ReviewRequests: utilpointer.Bool(tc.reviewRequests),
+				},
+			}
+			e := &event{
+				org:                  "k8s",
+				repo:                 "k8s",
+				branch:               "master",
+				reviewRequestChanged: true,
+			}
+			log := logrus.WithField("plugin", "require-matching-label")
+			fghc := newFakeGitHub(tc.initialLabels...)
+			if err := handle(log, fghc, &fakePruner{}, clock.RealClock{}, configs, e); err != nil {
+				t.Fatalf("Unexpected error from handle: %v.", err)
+			}
+			if !tc.expectedAdded.Equal(fghc.IssueLabelsAdded) {
+				t.Errorf("Expected the %q labels to be added, but got %q.", sets.List(tc.expectedAdded), sets.List(fghc.IssueLabelsAdded))
+			}
+			if !tc.expectedRemoved.Equal(fghc.IssueLabelsRemoved) {
+				t.Errorf("Expected the %q labels to be removed, but got %q.", sets.List(tc.expectedRemoved), sets.List(fghc.IssueLabelsRemoved))
+			}
+		})
+	}
+}
+
+func TestHandleReviews(t *testing.T) {
+	tcs := []struct {
+		name          string
+		reviews       bool
+		initialLabels []string
+
+		expectedAdded   sets.Set[string]
+		expectedRemoved sets.Set[string]
+	}{
+		{
+			name:          "review submission adds missing label",
+			reviews:       true,
+			initialLabels: []string{"kind/bug"},
+			expectedAdded: sets.New[string]("needs-decision"),
+		},
+		{
+			name:            "review submission removes missing label",
+			reviews:         true,
+			initialLabels:   []string{"needs-decision", "decision/needs-changes"},
+			expectedRemoved: sets.New[string]("needs-decision"),
+		},
+		{
+			name:          "review submission is ignored unless configured",
+			initialLabels: []string{"kind/bug"},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			configs := []plugins.RequireMatchingLabel{
+				{
+					Org:          "k8s",
+					PRs:          utilpointer.Bool(true),
+					Re:           regexp.MustCompile(`^decision/`),
+					MissingLabel: "needs-decision",
+					Reviews:      utilpointer.Bool(tc.reviews),
+				},
+			}
+			e := &event{
+				org:             "k8s",
+				repo:            "k8s",
+				branch:          "master",
+				reviewSubmitted: true,
+			}
+			log := logrus.WithField("plugin", "require-matching-label")
+			fghc := newFakeGitHub(tc.initialLabels...)
+			if err := handle(log, fghc, &fakePruner{}, clock.RealClock{}, configs, e); err != nil {
+				t.Fatalf("Unexpected error from handle: %v.", err)
+			}
+			if !tc.expectedAdded.Equal(fghc.IssueLabelsAdded) {
+				t.Errorf("Expected the %q labels to be added, but got %q.", sets.List(tc.expectedAdded), sets.List(fghc.IssueLabelsAdded))
+			}
+			if !tc.expectedRemoved.Equal(fghc.IssueLabelsRemoved) {
+				t.Errorf("Expected the %q labels to be removed, but got %q.", sets.List(tc.expectedRemoved), sets.List(fghc.IssueLabelsRemoved))
+			}
+		})
+	}
+}
+
+func TestHandleLinkedIssues(t *testing.T) {
+	tcs := []struct {
+		name          string
+		linkedIssues  bool
+		body          string
+		issueLabels   map[int][]string
+		initialLabels []string
+
+		expectedAdded   sets.Set[string]
+		expectedRemoved sets.Set[string]
+	}{
+		{
+			name:         "linked issue supplies the matching label",
+			linkedIssues: true,
+			body:         "This PR does things.\n\nFixes #5",
+			issueLabels:  map[int][]string{5: {"kind/bug"}},
+		},
+		{
+			name:            "linked issue label removes missing label",
+			linkedIssues:    true,
+			body:            "closes #5, resolves #6",
+			issueLabels:     map[int][]string{5: {"priority/low"}, 6: {"kind/feature"}},
+			initialLabels:   []string{"needs-kind"},
+			expectedRemoved: sets.New[string]("needs-kind"),
+		},
+		{
+			name:          "linked issue without matching label",
+			linkedIssues:  true,
+			body:          "Fixes #5",
+			issueLabels:   map[int][]string{5: {"priority/low"}},
+			expectedAdded: sets.New[string]("needs-kind"),
+		},
+		{
+			name:          "mentioned issue is not linked",
+			linkedIssues:  true,
+			body:          "Related to #5",
+			issueLabels:   map[int][]string{5: {"kind/bug"}},
+			expectedAdded: sets.New[string]("needs-kind"),
+		},
+		{
+			name:          "linked issues are ignored unless configured",
+			body:          "Fixes #5",
+			issueLabels:   map[int][]string{5: {"kind/bug"}},
+			expectedAdded: sets.New[string]("needs-kind"),
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			configs := []plugins.RequireMatchingLabel{
+				{
+					Org:          "k8s",
+					PRs:          utilpointer.Bool(true),
+					Re:           regexp.MustCompile(`^kind/`),
+					MissingLabel: "needs-kind",
+					LinkedIssues: utilpointer.Bool(tc.linkedIssues),
+				},
+			}
+			e := &event{
+				org:    "k8s",
+				repo:   "k8s",
+				number: 1,
+				branch: "master",
+			}
+			log := logrus.WithField("plugin", "require-matching-label")
+			fghc := newFakeGitHub(tc.initialLabels...)
+			fghc.body = tc.body
+			fghc.issueLabels = tc.issueLabels
+			if err := handle(log, fghc, &fakePruner{}, clock.RealClock{}, configs, e); err != nil {
+				t.Fatalf("Unexpected error from handle: %v.", err)
+			}
+			if !tc.expectedAdded.Equal(fghc.IssueLabelsAdded) {
+				t.Errorf("Expected the %q labels to be added, but got %q.", sets.List(tc.expectedAdded), sets.List(fghc.IssueLabelsAdded))
+			}
+			if !tc.expectedRemoved.Equal(fghc.IssueLabelsRemoved) {
+				t.Errorf("Expected the %q labels to be removed, but got %q.", sets.List(tc.expectedRemoved), sets.List(fghc.IssueLabelsRemoved))
+			}
+		})
+	}
+}
+
+func TestHandleIgnoredLabelers(t *testing.T) {
+	labeled := func(label, actor string) github.ListedIssueEvent {
+		return github.ListedIssueEvent{Event: github.IssueActionLabeled, Label: github.Label{Name: label}, Actor: github.User{Login: actor}}
+	}
+
+	tcs := []struct {
+		name            string
+		ignoredLabelers []string
+		event           *event
+		initialLabels   []string
+		events          []github.ListedIssueEvent
+
+		expectedAdded sets.Set[string]
+	}{
+		{
+			name:            "only automation-applied label adds missing label",
+			ignoredLabelers: []string{"k8s-ci-robot"},
+			event:           &event{org: "k8s", repo: "k8s"},
+			initialLabels:   []string{"sig/node"},
+			events:          []github.ListedIssueEvent{labeled("sig/node", "k8s-ci-robot")},
+			expectedAdded:   sets.New[string]("needs-sig"),
+		},
+		{
+			name:            "human-applied label satisfies",
+			ignoredLabelers: []string{"k8s-ci-robot"},
+			event:           &event{org: "k8s", repo: "k8s"},
+			initialLabels:   []string{"sig/node"},
+			events:          []github.ListedIssueEvent{labeled("sig/node", "alice")},
+		},
+		{
+			name:            "label re-applied by a human satisfies",
+			ignoredLabelers: []string{"k8s-ci-robot"},
+			event:           &event{org: "k8s", repo: "k8s"},
+			initialLabels:   []string{"sig/node"},
+			events:          []github.ListedIssueEvent{labeled("sig/node", "k8s-ci-robot"), labeled("sig/node", "alice")},
+		},
+		{
+			name:            "label event from automation adds missing label",
+			ignoredLabelers: []string{"k8s-ci-robot"},
+			event:           &event{org: "k8s", repo: "k8s", label: "sig/node", labeler: "K8s-CI-Robot"},
+			initialLabels:   []string{"sig/node"},
+			expectedAdded:   sets.New[string]("needs-sig"),
+		},
+		{
+			name:          "automation-applied label satisfies unless configured",
+			event:         &event{org: "k8s", repo: "k8s"},
+			initialLabels: []string{"sig/node"},
+			events:        []github.ListedIssueEvent{labeled("sig/node", "k8s-ci-robot")},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			configs := []plugins.RequireMatchingLabel{
+				{
+					Org:             "k8s",
+					Issues:          utilpointer.Bool(true),
+					Re:              regexp.MustCompile(`^sig/`),
+					MissingLabel:    "needs-sig",
+					IgnoredLabelers: tc.ignoredLabelers,
+				},
+			}
+			log := logrus.WithField("plugin", "require-matching-label")
+			fghc := newFakeGitHub(tc.initialLabels...)
+			fghc.events = tc.events
+			if err := handle(log, fghc, &fakePruner{}, clock.RealClock{}, configs, tc.event); err != nil {
+				t.Fatalf("Unexpected error from handle: %v.", err)
+			}
+			if !tc.expectedAdded.Equal(fghc.IssueLabelsAdded) {
+				t.Errorf("Expected the %q labels to be added, but got %q.", sets.List(tc.expectedAdded), sets.List(fghc.IssueLabelsAdded))
+			}
+		})
+	}
+}
+
+func TestHandleDisabled(t *testing.T) {
+	disabled := false
+	sigConfig := plugins.RequireMatchingLabel{
+		Org:          "k8s",
+		Issues:       utilpointer.Bool(true),
+		Re:           regexp.MustCompile(`^sig/`),
+		MissingLabel: "needs-sig",
+	}
+	disabledSigConfig := sigConfig
+	disabledSigConfig.Enabled = &disabled
+	kindConfig := plugins.RequireMatchingLabel{
+		Org:          "k8s",
+		Issues:       utilpointer.Bool(true),
+		Re:           regexp.MustCompile(`^kind/`),
+		MissingLabel: "needs-sig",
+	}
+
+	tcs := []struct {
+		name          string
+		configs       []plugins.RequireMatchingLabel
+		initialLabels []string
+
+		expectedAdded   sets.Set[string]
+		expectedRemoved sets.Set[string]
+	}{
+		{
+			name:    "disabled rule does not add missing label",
+			configs: []plugins.RequireMatchingLabel{disabledSigConfig},
+		},
+		{
+			name:            "disabled rule removes previously applied missing label",
+			configs:         []plugins.RequireMatchingLabel{disabledSigConfig},
+			initialLabels:   []string{"needs-sig"},
+			expectedRemoved: sets.New[string]("needs-sig"),
+		},
+		{
+			name:          "disabled rule keeps missing label applied by an enabled rule",
+			configs:       []plugins.RequireMatchingLabel{disabledSigConfig, kindConfig},
+			initialLabels: []string{"needs-sig"},
+		},
+		{
+			name:          "rule is enabled by default",
+			configs:       []plugins.RequireMatchingLabel{sigConfig},
+			expectedAdded: sets.New[string]("needs-sig"),
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			log := logrus.WithField("plugin", "require-matching-label")
+			fghc := newFakeGitHub(tc.initialLabels...)
+			if err := handle(log, fghc, &fakePruner{}, clock.RealClock{}, tc.configs, &event{org: "k8s", repo: "k8s"}); err != nil {
+				t.Fatalf("Unexpected error from handle: %v.", err)
+			}
+			if !tc.expectedAdded.Equal(fghc.IssueLabelsAdded) {
+				t.Errorf("Expected the %q labels to be added, but got %q.", sets.List(tc.expectedAdded), sets.List(fghc.IssueLabelsAdded))
+			}
+			if !tc.expectedRemoved.Equal(fghc.IssueLabelsRemoved) {
+				t.Errorf("Expected the %q labels to be removed, but got %q.", sets.List(tc.expectedRemoved), sets.List(fghc.IssueLabelsRemoved))
+			}
+		})
+	}
+}
+
+// labelsOnlyGitHub fails all calls but getting the labels of the issue or PR.
+type labelsOnlyGitHub struct {
+	*fakeGitHub
+}
+
+func (f *labelsOnlyGitHub) GetIssue(org, repo string, number int) (*github.Issue, error) {
+	return nil, errors.New("unexpected call to GetIssue")
+}
+
+func (f *labelsOnlyGitHub) GetPullRequest(org, repo string, number int) (*github.PullRequest, error) {
+	return nil, errors.New("unexpected call to GetPullRequest")
+}
+
+func (f *labelsOnlyGitHub) GetPullRequestChanges(org, repo string, number int) ([]github.PullRequestChange, error) {
+	return nil, errors.New("unexpected call to GetPullRequestChanges")
+}
+
+func (f *labelsOnlyGitHub) ListIssueEvents(org, repo string, num int) ([]github.ListedIssueEvent, error) {
+	return nil, errors.New("unexpected call to ListIssueEvents")
+}
+
+func (f *labelsOnlyGitHub) ListIssueComments(org, repo string, number int) ([]github.IssueComment, error) {
+	return nil, errors.New("unexpected call to ListIssueComments")
+}
+
+func (f *labelsOnlyGitHub) GetRepoProjects(owner, repo string) ([]github.Project, error) {
+	return nil, errors.New("unexpected call to GetRepoProjects")
+}
+
+func TestLoadStateDisabled(t *testing.T) {
+	disabled := false
+	cfg := plugins.RequireMatchingLabel{
+		Org:                   "k8s",
+		PRs:                   utilpointer.Bool(true),
+		Enabled:               &disabled,
+		Re:                    regexp.MustCompile(`^sig/`),
+		MissingLabel:          "needs-sig",
+		MissingComment:        "Please add a sig label.",
+		OrAssignees:           []string{"alice"},
+		IgnoredLabelers:       []string{"bot"},
+		LinkedIssues:          utilpointer.Bool(true),
+		ParentMarker:          "Parent:",
+		Project:               "Roadmap",
+		RenotifyAfterDuration: time.Hour,
+		MaxChangedFiles:       utilpointer.Int(10),
+	}
+	fghc := &labelsOnlyGitHub{fakeGitHub: newFakeGitHub("needs-sig")}
+	e := &event{org: "k8s", repo: "k8s", branch: "main", number: 5}
+	s, err := loadState(logrus.WithField("plugin", "require-matching-label"), fghc, clock.RealClock{}, []plugins.RequireMatchingLabel{cfg}, e)
+	if err != nil {
+		t.Fatalf("Expected disabled configs to only get the labels, but got: %v.", err)
+	}
+	if len(s.labels) != 1 || s.labels[0].Name != "needs-sig" {
+		t.Errorf("Expected the needs-sig label, but got %v.", s.labels)
+	}
+}
+
+func TestHandleMaxLabelAge(t *testing.T) {
+	labeled := func(label string, age time.Duration) github.ListedIssueEvent {
+		return github.ListedIssueEvent{Event: github.IssueActionLabeled, Label: github.Label{Name: label}, CreatedAt: time.Now().Add(-age)}
+	}
+
+	tcs := []struct {
+		name   string
+		events []github.ListedIssueEvent
+
+		expectedAdded sets.Set[string]
+	}{
+		{
+			name:   "fresh matching label satisfies",
+			events: []github.ListedIssueEvent{labeled("triage/accepted", time.Hour)},
+		},
+		{
+			name:          "stale matching label adds missing label",
+			events:        []github.ListedIssueEvent{labeled("triage/accepted", 100*24*time.Hour)},
+			expectedAdded: sets.New[string]("needs-triage"),
+		},
+		{
+			name:   "re-added matching label satisfies",
+			events: []github.ListedIssueEvent{labeled("triage/accepted", 100*24*time.Hour), labeled("triage/accepted", time.Hour)},
+		},
+		{
+			name: "matching label without timestamp satisfies",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			configs := []plugins.RequireMatchingLabel{
+				{
+					Org:                 "k8s",
+					Issues:              utilpointer.Bool(true),
+					Re:                  regexp.MustCompile(`^triage/`),
+					MissingLabel:        "needs-triage",
+					MaxLabelAgeDuration: 30 * 24 * time.Hour,
+				},
+			}
+			log := logrus.WithField("plugin", "require-matching-label")
+			fghc := newFakeGitHub("triage/accepted")
+			fghc.events = tc.events
+			if err := handle(log, fghc, &fakePruner{}, clock.RealClock{}, configs, &event{org: "k8s", repo: "k8s"}); err != nil {
+				t.Fatalf("Unexpected error from handle: %v.", err)
+			}
+			if !tc.expectedAdded.Equal(fghc.IssueLabelsAdded) {
+				t.Errorf("Expected the %q labels to be added, but got %q.", sets.List(tc.expectedAdded), sets.List(fghc.IssueLabelsAdded))
+			}
+		})
+	}
+}
+
+func TestHandleTransfer(t *testing.T) {
+	configs := []plugins.RequireMatchingLabel{
+		{
+			Org:          "dest",
+			Issues:       utilpointer.Bool(true),
+			Re:           regexp.MustCompile(`^sig/`),
+			MissingLabel: "needs-sig",
+		},
+	}
+
+	tcs := []struct {
+		name          string
+		changes       string
+		initialLabels []string
+
+		expectedEvent *event
+		expectedAdded sets.Set[string]
+	}{
+		{
+			name:          "transfer newly requires missing label",
+			changes:       `{"new_issue":{"number":7,"user":{"login":"author"}},"new_repository":{"owner":{"login":"dest"},"name":"repo"}}`,
+			initialLabels: []string{"kind/bug"},
+			expectedEvent: &event{org: "dest", repo: "repo", number: 7, author: "author"},
+			expectedAdded: sets.New[string]("needs-sig"),
+		},
+		{
+			name:          "transfer with matching label",
+			changes:       `{"new_issue":{"number":7,"user":{"login":"author"}},"new_repository":{"owner":{"login":"dest"},"name":"repo"}}`,
+			initialLabels: []string{"sig/testing"},
+			expectedEvent: &event{org: "dest", repo: "repo", number: 7, author: "author"},
+		},
+		{
+			name:    "transfer without new issue is ignored",
+			changes: `{}`,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			ie := github.IssueEvent{
+				Action:  github.IssueActionTransferred,
+				Issue:   github.Issue{Number: 1},
+				Repo:    github.Repo{Owner: github.User{Login: "source"}, Name: "repo"},
+				Changes: []byte(tc.changes),
+			}
+			e, err := transferEvent(ie)
+			if err != nil {
+				t.Fatalf("Unexpected error from transferEvent: %v.", err)
+			}
+			if !reflect.DeepEqual(tc.expectedEvent, e) {
+				t.Fatalf("Expected event %+v, but got %+v.", tc.expectedEvent, e)
+			}
+			if e == nil {
+				return
+			}
+
+			log := logrus.WithField("plugin", "require-matching-label")
+			fghc := newFakeGitHub(tc.initialLabels...)
+			if err := handle(log, fghc, &fakePruner{}, clock.RealClock{}, configs, e); err != nil {
+				t.Fatalf("Unexpected error from handle: %v.", err)
+			}
+			if !tc.expectedAdded.Equal(fghc.IssueLabelsAdded) {
+				t.Errorf("Expected the %q labels to be added, but got %q.", sets.List(tc.expectedAdded), sets.List(fghc.IssueLabelsAdded))
+			}
+		})
+	}
+}
+
+func TestHandleLabelAliases(t *testing.T) {
+	tcs := []struct {
+		name          string
+		aliases       map[string]string
+		event         *event
+		initialLabels []string
+
+		expectedAdded   sets.Set[string]
+		expectedRemoved sets.Set[string]
+	}{
+		{
+			name:          "only alias form of matching label satisfies",
+			aliases:       map[string]string{"sig-network": "sig/network"},
+			event:         &event{org: "k8s", repo: "k8s"},
+			initialLabels: []string{"sig-network"},
+		},
+		{
+			name:          "alias form of matching label does not satisfy without alias",
+			event:         &event{org: "k8s", repo: "k8s"},
+			initialLabels: []string{"sig-network"},
+			expectedAdded: sets.New[string]("needs-sig"),
+		},
+		{
+			name:            "adding alias form of matching label removes missing label",
+			aliases:         map[string]string{"sig-network": "sig/network"},
+			event:           &event{org: "k8s", repo: "k8s", label: "sig-network"},
+			initialLabels:   []string{"sig-network", "needs-sig"},
+			expectedRemoved: sets.New[string]("needs-sig"),
+		},
+		{
+			name:          "alias form of missing label is not applied twice",
+			aliases:       map[string]string{"needs_sig": "needs-sig"},
+			event:         &event{org: "k8s", repo: "k8s"},
+			initialLabels: []string{"needs_sig"},
+		},
+		{
+			name:            "alias form of missing label is removed once satisfied",
+			aliases:         map[string]string{"needs_sig": "needs-sig"},
+			event:           &event{org: "k8s", repo: "k8s", label: "sig/node"},
+			initialLabels:   []string{"needs_sig", "sig/node"},
+			expectedRemoved: sets.New[string]("needs_sig"),
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			configs := []plugins.RequireMatchingLabel{
+				{
+					Org:          "k8s",
+					Issues:       utilpointer.Bool(true),
+					Re:           regexp.MustCompile(`^sig/`),
+					MissingLabel: "needs-sig",
+					LabelAliases: tc.aliases,
+				},
+			}
+			log := logrus.WithField("plugin", "require-matching-label")
+			fghc := newFakeGitHub(tc.initialLabels...)
+			if err := handle(log, fghc, &fakePruner{}, clock.RealClock{}, configs, tc.event); err != nil {
+				t.Fatalf("Unexpected error from handle: %v.", err)
+			}
+			if !tc.expectedAdded.Equal(fghc.IssueLabelsAdded) {
+				t.Errorf("Expected the %q labels to be added, but got %q.", sets.List(tc.expectedAdded), sets.List(fghc.IssueLabelsAdded))
+			}
+			if !tc.expectedRemoved.Equal(fghc.IssueLabelsRemoved) {
+				t.Errorf("Expected the %q labels to be removed, but got %q.", sets.List(tc.expectedRemoved), sets.List(fghc.IssueLabelsRemoved))
+			}
+		})
+	}
+}
+
+func TestHandleIssueAndPRMissingLabels(t *testing.T) {
+	config := plugins.RequireMatchingLabel{
+		Org:               "k8s",
+		Issues:            utilpointer.Bool(true),
+		PRs:               utilpointer.Bool(true),
+		Re:                regexp.MustCompile(`^kind/`),
+		MissingLabel:      "needs-kind",
+		MissingComment:    "Please add a kind.",
+		IssueMissingLabel: "needs-triage",
+		PRMissingLabel:    "needs-review-label",
+		PRMissingComment:  "Please add a kind to your PR.",
+	}
+	issueOnlyConfig := config
+	issueOnlyConfig.PRMissingLabel = ""
+	issueOnlyConfig.PRMissingComment = ""
+
+	tcs := []struct {
+		name          string
+		config        plugins.RequireMatchingLabel
+		event         *event
+		initialLabels []string
+
+		expectedAdded   sets.Set[string]
+		expectedRemoved sets.Set[string]
+		expectedComment string
+	}{
+		{
+			name:            "issue gets issue specific label and shared comment",
+			config:          config,
+			event:           &event{org: "k8s", repo: "k8s"},
+			expectedAdded:   sets.New[string]("needs-triage"),
+			expectedComment: "Please add a kind.",
+		},
+		{
+			name:            "PR gets PR specific label and comment",
+			config:          config,
+			event:           &event{org: "k8s", repo: "k8s", branch: "master"},
+			expectedAdded:   sets.New[string]("needs-review-label"),
+			expectedComment: "Please add a kind to your PR.",
+		},
+		{
+			name:            "PR specific label is removed once satisfied",
+			config:          config,
+			event:           &event{org: "k8s", repo: "k8s", branch: "master", label: "kind/bug"},
+			initialLabels:   []string{"needs-review-label", "kind/bug"},
+			expectedRemoved: sets.New[string]("needs-review-label"),
+		},
+		{
+			name:            "PR falls back to shared label",
+			config:          issueOnlyConfig,
+			event:           &event{org: "k8s", repo: "k8s", branch: "master"},
+			expectedAdded:   sets.New[string]("needs-kind"),
+			expectedComment: "Please add a kind.",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			log := logrus.WithField("plugin", "require-matching-label")
+			fghc := newFakeGitHub(tc.initialLabels...)
+			if err := handle(log, fghc, &fakePruner{}, clock.RealClock{}, []plugins.RequireMatchingLabel{tc.config}, tc.event); err != nil {
+				t.Fatalf("Unexpected error from handle: %v.", err)
+			}
+			if !tc.expectedAdded.Equal(fghc.IssueLabelsAdded) {
+				t.Errorf("Expected the %q labels to be added, but got %q.", sets.List(tc.expectedAdded), sets.List(fghc.IssueLabelsAdded))
+			}
+			if !tc.expectedRemoved.Equal(fghc.IssueLabelsRemoved) {
+				t.Errorf("Expected the %q labels to be removed, but got %q.", sets.List(tc.expectedRemoved), sets.List(fghc.IssueLabelsRemoved))
+			}
+			if tc.expectedComment == "" && fghc.commented {
+				t.Errorf("Expected no comment, but got %q.", fghc.comments)
+			}
+			if tc.expectedComment != "" && (len(fghc.comments) != 1 || !strings.Contains(fghc.comments[0], tc.expectedComment)) {
+				t.Errorf("Expected a comment containing %q, but got %q.", tc.expectedComment, fghc.comments)
+			}
+		})
+	}
+}
+
+func TestHandleOnNoLabels(t *testing.T) {
+	tcs := []struct {
+		name          string
+		onNoLabels    string
+		event         *event
+		initialLabels []string
+
+		expectedAdded   sets.Set[string]
+		expectedRemoved sets.Set[string]
+	}{
+		{
+			name:          "issue without labels is labeled",
+			onNoLabels:    "needs-triage",
+			event:         &event{org: "k8s", repo: "k8s"},
+			expectedAdded: sets.New[string]("needs-triage", "needs-sig"),
+		},
+		{
+			name:          "issue with unrelated label is not labeled",
+			onNoLabels:    "needs-triage",
+			event:         &event{org: "k8s", repo: "k8s"},
+			initialLabels: []string{"priority/important-soon"},
+			expectedAdded: sets.New[string]("needs-sig"),
+		},
+		{
+			name:          "missing label does not count as a label",
+			onNoLabels:    "needs-triage",
+			event:         &event{org: "k8s", repo: "k8s"},
+			initialLabels: []string{"needs-sig"},
+			expectedAdded: sets.New[string]("needs-triage"),
+		},
+		{
+			name:            "label is removed once unrelated label is added",
+			onNoLabels:      "needs-triage",
+			event:           &event{org: "k8s", repo: "k8s", label: "priority/important-soon"},
+			initialLabels:   []string{"needs-triage", "needs-sig", "priority/important-soon"},
+			expectedRemoved: sets.New[string]("needs-triage"),
+		},
+		{
+			name:          "label is applied again once last label is removed",
+			onNoLabels:    "needs-triage",
+			event:         &event{org: "k8s", repo: "k8s", label: "priority/important-soon"},
+			initialLabels: []string{"needs-sig"},
+			expectedAdded: sets.New[string]("needs-triage"),
+		},
+		{
+			name:          "unrelated label is irrelevant without on_no_labels",
+			event:         &event{org: "k8s", repo: "k8s", label: "priority/important-soon"},
+			initialLabels: []string{"priority/important-soon"},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			configs := []plugins.RequireMatchingLabel{
+				{
+					Org:          "k8s",
+					Issues:       utilpointer.Bool(true),
+					Re:           regexp.MustCompile(`^sig/`),
+					MissingLabel: "needs-sig",
+					OnNoLabels:   tc.onNoLabels,
+				},
+			}
+			log := logrus.WithField("plugin", "require-matching-label")
+			fghc := newFakeGitHub(tc.initialLabels...)
+			if err := handle(log, fghc, &fakePruner{}, clock.RealClock{}, configs, tc.event); err != nil {
+				t.Fatalf("Unexpected error from handle: %v.", err)
+			}
+			if !tc.expectedAdded.Equal(fghc.IssueLabelsAdded) {
+				t.Errorf("Expected the %q labels to be added, but got %q.", sets.List(tc.expectedAdded), sets.List(fghc.IssueLabelsAdded))
+			}
+			if !tc.expectedRemoved.Equal(fghc.IssueLabelsRemoved) {
+				t.Errorf("Expected the %q labels to be removed, but got %q.", sets.List(tc.expectedRemoved), sets.List(fghc.IssueLabelsRemoved))
+			}
+		})
+	}
+}
+
+func TestHandleRequiredFamily(t *testing.T) {
+	tcs := []struct {
+		name          string
+		event         *event
+		initialLabels []string
+
+		expectedAdded   sets.Set[string]
+		expectedRemoved sets.Set[string]
+	}{
+		{
+			name:          "family member satisfies",
+			event:         &event{org: "k8s", repo: "k8s"},
+			initialLabels: []string{"sig/node"},
+		},
+		{
+			name:          "label outside of the family does not satisfy",
+			event:         &event{org: "k8s", repo: "k8s"},
+			initialLabels: []string{"sig/storage"},
+			expectedAdded: sets.New[string]("needs-sig"),
+		},
+		{
+			name:          "absent family adds missing label",
+			event:         &event{org: "k8s", repo: "k8s"},
+			expectedAdded: sets.New[string]("needs-sig"),
+		},
+		{
+			name:            "adding family member removes missing label",
+			event:           &event{org: "k8s", repo: "k8s", label: "sig/network"},
+			initialLabels:   []string{"sig/network", "needs-sig"},
+			expectedRemoved: sets.New[string]("needs-sig"),
+		},
+		{
+			name:          "adding label outside of the family is ignored",
+			event:         &event{org: "k8s", repo: "k8s", label: "sig/storage"},
+			initialLabels: []string{"sig/storage"},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			configs := []plugins.RequireMatchingLabel{
+				{
+					Org:            "k8s",
+					Issues:         utilpointer.Bool(true),
+					RequiredFamily: []string{"sig/network", "sig/node"},
+					MissingLabel:   "needs-sig",
+				},
+			}
+			log := logrus.WithField("plugin", "require-matching-label")
+			fghc := newFakeGitHub(tc.initialLabels...)
+			if err := handle(log, fghc, &fakePruner{}, clock.RealClock{}, configs, tc.event); err != nil {
+				t.Fatalf("Unexpected error from handle: %v.", err)
+			}
+			if !tc.expectedAdded.Equal(fghc.IssueLabelsAdded) {
+				t.Errorf("Expected the %q labels to be added, but got %q.", sets.List(tc.expectedAdded), sets.List(fghc.IssueLabelsAdded))
+			}
+			if !tc.expectedRemoved.Equal(fghc.IssueLabelsRemoved) {
+				t.Errorf("Expected the %q labels to be removed, but got %q.", sets.List(tc.expectedRemoved), sets.List(fghc.IssueLabelsRemoved))
+			}
+		})
+	}
+}
+
+func TestHandleParentIssue(t *testing.T) {
+	tcs := []struct {
+		name          string
+		event         *event
+		body          string
+		initialLabels []string
+		issueLabels   map[int][]string
+
+		expectedAdded sets.Set[string]
+	}{
+		{
+			name:        "parent supplies the matching label",
+			event:       &event{org: "k8s", repo: "k8s", number: 5},
+			body:        "Implements part of the epic.\n\nParent: #3",
+			issueLabels: map[int][]string{3: {"sig/node", "kind/epic"}},
+		},
+		{
+			name:        "parent supplies the matching label of a PR",
+			event:       &event{org: "k8s", repo: "k8s", number: 5, branch: "master"},
+			body:        "parent: #3",
+			issueLabels: map[int][]string{3: {"sig/node"}},
+		},
+		{
+			name:          "parent without matching label does not satisfy",
+			event:         &event{org: "k8s", repo: "k8s", number: 5},
+			body:          "Parent: #3",
+			issueLabels:   map[int][]string{3: {"kind/epic"}},
+			expectedAdded: sets.New[string]("needs-sig"),
+		},
+		{
+			name:          "reference without the marker is not a parent",
+			event:         &event{org: "k8s", repo: "k8s", number: 5},
+			body:          "See #3",
+			issueLabels:   map[int][]string{3: {"sig/node"}},
+			expectedAdded: sets.New[string]("needs-sig"),
+		},
+		{
+			name:          "own labels are considered without a parent",
+			event:         &event{org: "k8s", repo: "k8s", number: 5},
+			initialLabels: []string{"sig/node"},
+		},
+		{
+			name:          "own labels are considered along with the parent's",
+			event:         &event{org: "k8s", repo: "k8s", number: 5},
+			body:          "Parent: #3",
+			initialLabels: []string{"sig/node"},
+			issueLabels:   map[int][]string{3: {"kind/epic"}},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			configs := []plugins.RequireMatchingLabel{
+				{
+					Org:          "k8s",
+					Issues:       utilpointer.Bool(true),
+					PRs:          utilpointer.Bool(true),
+					Re:           regexp.MustCompile(`^sig/`),
+					MissingLabel: "needs-sig",
+					ParentMarker: "Parent:",
+				},
+			}
+			log := logrus.WithField("plugin", "require-matching-label")
+			fghc := newFakeGitHub(tc.initialLabels...)
+			fghc.body = tc.body
+			fghc.issueLabels = tc.issueLabels
+			if err := handle(log, fghc, &fakePruner{}, clock.RealClock{}, configs, tc.event); err != nil {
+				t.Fatalf("Unexpected error from handle: %v.", err)
+			}
+			if !tc.expectedAdded.Equal(fghc.IssueLabelsAdded) {
+				t.Errorf("Expected the %q labels to be added, but got %q.", sets.List(tc.expectedAdded), sets.List(fghc.IssueLabelsAdded))
+			}
+		})
+	}
+}
+
+func TestHandleMutuallyExclusive(t *testing.T) {
+	tcs := []struct {
+		name          string
+		event         *event
+		initialLabels []string
+
+		expectedAdded   sets.Set[string]
+		expectedRemoved sets.Set[string]
+		expectComment   bool
+	}{
+		{
+			name:          "conflicting pair is flagged",
+			event:         &event{org: "k8s", repo: "k8s"},
+			initialLabels: []string{"sig/node", "lifecycle/frozen", "lifecycle/rotten"},
+			expectedAdded: sets.New[string]("needs-label-cleanup"),
+			expectComment: true,
+		},
+		{
+			name:          "adding a conflicting label is flagged",
+			event:         &event{org: "k8s", repo: "k8s", label: "lifecycle/rotten"},
+			initialLabels: []string{"sig/node", "lifecycle/frozen", "lifecycle/rotten"},
+			expectedAdded: sets.New[string]("needs-label-cleanup"),
+			expectComment: true,
+		},
+		{
+			name:          "single label of the group is clean",
+			event:         &event{org: "k8s", repo: "k8s"},
+			initialLabels: []string{"sig/node", "lifecycle/frozen"},
+		},
+		{
+			name:          "labels of different groups do not conflict",
+			event:         &event{org: "k8s", repo: "k8s"},
+			initialLabels: []string{"sig/node", "lifecycle/frozen", "priority/backlog"},
+		},
+		{
+			name:            "resolving the conflict removes the label",
+			event:           &event{org: "k8s", repo: "k8s", label: "lifecycle/rotten"},
+			initialLabels:   []string{"sig/node", "lifecycle/frozen", "needs-label-cleanup"},
+			expectedRemoved: sets.New[string]("needs-label-cleanup"),
+		},
+		{
+			name:          "conflict is flagged along with the missing label",
+			event:         &event{org: "k8s", repo: "k8s"},
+			initialLabels: []string{"lifecycle/frozen", "lifecycle/rotten"},
+			expectedAdded: sets.New[string]("needs-label-cleanup", "needs-sig"),
+			expectComment: true,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			configs := []plugins.RequireMatchingLabel{
+				{
+					Org:          "k8s",
+					Issues:       utilpointer.Bool(true),
+					Re:           regexp.MustCompile(`^sig/`),
+					MissingLabel: "needs-sig",
+					MutuallyExclusive: [][]string{
+						{"lifecycle/frozen", "lifecycle/rotten"},
+						{"priority/backlog", "priority/critical-urgent"},
+					},
+					ConflictLabel:   "needs-label-cleanup",
+					ConflictComment: "Please remove all but one of the contradicting labels.",
+				},
+			}
+			log := logrus.WithField("plugin", "require-matching-label")
+			fghc := newFakeGitHub(tc.initialLabels...)
+			if err := handle(log, fghc, &fakePruner{}, clock.RealClock{}, configs, tc.event); err != nil {
+				t.Fatalf("Unexpected error from handle: %v.", err)
+			}
+			if !tc.expectedAdded.Equal(fghc.IssueLabelsAdded) {
+				t.Errorf("Expected the %q labels to be added, but got %q.", sets.List(tc.expectedAdded), sets.List(fghc.IssueLabelsAdded))
+			}
+			if !tc.expectedRemoved.Equal(fghc.IssueLabelsRemoved) {
+				t.Errorf("Expected the %q labels to be removed, but got %q.", sets.List(tc.expectedRemoved), sets.List(fghc.IssueLabelsRemoved))
+			}
+			commented := false
+			for _, comment := range fghc.comments {
+				commented = commented || strings.Contains(comment, "contradicting labels")
+			}
+			if commented != tc.expectComment {
+				t.Errorf("Expected a comment about the conflict: %t, got comments %q.", tc.expectComment, fghc.comments)
+			}
+		})
+	}
+}
+
+func TestHandleRenotification(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	week := 7 * 24 * time.Hour
+	notification := func(age time.Duration) github.IssueComment {
+		return github.IssueComment{Body: plugins.FormatSimpleResponse("Please add a sig label."), CreatedAt: created.Add(age)}
+	}
+	configs := []plugins.RequireMatchingLabel{
+		{
+			Org:                   "k8s",
+			Issues:                utilpointer.Bool(true),
+			Re:                    regexp.MustCompile(`^sig/`),
+			MissingLabel:          "needs-sig",
+			MissingComment:        "Please add a sig label.",
+			RenotifyAfterDuration: week,
+			MaxRenotifications:    utilpointer.Int(2),
+		},
+	}
+	tcs := []struct {
+		name          string
+		elapsed       time.Duration
+		initialLabels []string
+		comments      []github.IssueComment
+
+		expectComment bool
+	}{
+		{
+			name:          "recent notification is not repeated",
+			elapsed:       week - time.Hour,
+			initialLabels: []string{"needs-sig"},
+			comments:      []github.IssueComment{notification(0)},
+		},
+		{
+			name:          "notification is repeated after the threshold",
+			elapsed:       week,
+			initialLabels: []string{"needs-sig"},
+			comments:      []github.IssueComment{notification(0)},
+			expectComment: true,
+		},
+		{
+			name:          "threshold is measured since the last notification",
+			elapsed:       week + time.Hour,
+			initialLabels: []string{"needs-sig"},
+			comments:      []github.IssueComment{notification(0), notification(2 * time.Hour)},
+		},
+		{
+			name:          "renotifications are capped",
+			elapsed:       10 * week,
+			initialLabels: []string{"needs-sig"},
+			comments:      []github.IssueComment{notification(0), notification(week), notification(2 * week)},
+		},
+		{
+			name:          "missing notification is posted after the issue's age exceeds the threshold",
+			elapsed:       week,
+			initialLabels: []string{"needs-sig"},
+			expectComment: true,
+		},
+		{
+			name:          "satisfied issue is not renotified",
+			elapsed:       week,
+			initialLabels: []string{"sig/node"},
+			comments:      []github.IssueComment{notification(0)},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			log := logrus.WithField("plugin", "require-matching-label")
+			fghc := newFakeGitHub(tc.initialLabels...)
+			fghc.created = created
+			fghc.issueComments = tc.comments
+			clk := clocktesting.NewFakePassiveClock(created.Add(tc.elapsed))
+			e := &event{org: "k8s", repo: "k8s", number: 5}
+			if err := handle(log, fghc, &fakePruner{}, clk, configs, e); err != nil {
+				t.Fatalf("Unexpected error from handle: %v.", err)
+			}
+			if len(fghc.IssueLabelsAdded) != 0 || len(fghc.IssueLabelsRemoved) != 0 {
+				t.Errorf("Expected no label changes, but got %q added and %q removed.", sets.List(fghc.IssueLabelsAdded), sets.List(fghc.IssueLabelsRemoved))
+			}
+			if fghc.commented != tc.expectComment {
+				t.Errorf("Expected a comment: %t, got comments %q.", tc.expectComment, fghc.comments)
+			}
+		})
+	}
+}
+
+func TestHandleProject(t *testing.T) {
+	const issueURL = "https://api.github.com/repos/k8s/k8s/issues/5"
+	configs := []plugins.RequireMatchingLabel{
+		{
+			Org:            "k8s",
+			Issues:         utilpointer.Bool(true),
+			Re:             regexp.MustCompile(`^triage/`),
+			MissingLabel:   "needs-triage",
+			Project:        "Triage",
+			ProjectColumns: []string{"Accepted", "Done"},
+		},
+	}
+	tcs := []struct {
+		name          string
+		initialLabels []string
+		repoProjects  []github.Project
+		orgProjects   []github.Project
+		cards         map[int][]string
+		projectsErr   error
+
+		expectedAdded   sets.Set[string]
+		expectedRemoved sets.Set[string]
+	}{
+		{
+			name:            "card in a configured column of a repo project satisfies the requirement",
+			initialLabels:   []string{"needs-triage"},
+			repoProjects:    []github.Project{{Name: "Triage", ID: 1}},
+			cards:           map[int][]string{12: {issueURL}},
+			expectedAdded:   sets.New[string](),
+			expectedRemoved: sets.New[string]("needs-triage"),
+		},
+		{
+			name:            "card in an org project satisfies the requirement",
+			orgProjects:     []github.Project{{Name: "Triage", ID: 1}},
+			cards:           map[int][]string{13: {issueURL}},
+			expectedAdded:   sets.New[string](),
+			expectedRemoved: sets.New[string](),
+		},
+		{
+			name:            "card in another column does not satisfy the requirement",
+			repoProjects:    []github.Project{{Name: "Triage", ID: 1}},
+			cards:           map[int][]string{11: {issueURL}},
+			expectedAdded:   sets.New[string]("needs-triage"),
+			expectedRemoved: sets.New[string](),
+		},
+		{
+			name:            "issue without a card is missing the label",
+			repoProjects:    []github.Project{{Name: "Triage", ID: 1}},
+			cards:           map[int][]string{12: {"https://api.github.com/repos/k8s/k8s/issues/6"}},
+			expectedAdded:   sets.New[string]("needs-triage"),
+			expectedRemoved: sets.New[string](),
+		},
+		{
+			name:            "unavailable projects fall back to labels",
+			initialLabels:   []string{"triage/accepted"},
+			projectsErr:     errors.New("projects are disabled"),
+			expectedAdded:   sets.New[string](),
+			expectedRemoved: sets.New[string](),
+		},
+		{
+			name:            "unknown project falls back to labels",
+			repoProjects:    []github.Project{{Name: "Roadmap", ID: 2}},
+			expectedAdded:   sets.New[string]("needs-triage"),
+			expectedRemoved: sets.New[string](),
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			log := logrus.WithField("plugin", "require-matching-label")
+			fghc := newFakeGitHub(tc.initialLabels...)
+			fghc.repoProjects = tc.repoProjects
+			fghc.orgProjects = tc.orgProjects
+			fghc.projectsErr = tc.projectsErr
+			fghc.columns = map[int][]github.ProjectColumn{
+				1: {{Name: "New", ID: 11}, {Name: "Accepted", ID: 12}, {Name: "Done", ID: 13}},
+			}
+			fghc.cards = tc.cards
+			e := &event{org: "k8s", repo: "k8s", number: 5}
+			if err := handle(log, fghc, &fakePruner{}, clock.RealClock{}, configs, e); err != nil {
+				t.Fatalf("Unexpected error from handle: %v.", err)
+			}
+			if !tc.expectedAdded.Equal(fghc.IssueLabelsAdded) {
+				t.Errorf("Expected the %q labels to be added, but got %q.", sets.List(tc.expectedAdded), sets.List(fghc.IssueLabelsAdded))
+			}
+			if !tc.expectedRemoved.Equal(fghc.IssueLabelsRemoved) {
+				t.Errorf("Expected the %q labels to be removed, but got %q.", sets.List(tc.expectedRemoved), sets.List(fghc.IssueLabelsRemoved))
+			}
+		})
+	}
+}
+
+func TestHandleMinFamilies(t *testing.T) {
+	configs := []plugins.RequireMatchingLabel{
+		{
+			Org:          "k8s",
+			Issues:       utilpointer.Bool(true),
+			Families:     []string{"^sig/", "^kind/", "^priority/"},
+			FamilyRes:    []*regexp.Regexp{regexp.MustCompile(`^sig/`), regexp.MustCompile(`^kind/`), regexp.MustCompile(`^priority/`)},
+			MinFamilies:  utilpointer.Int(2),
+			MissingLabel: "needs-triage",
+		},
+	}
+	tcs := []struct {
+		name          string
+		initialLabels []string
+
+		expectedAdded   sets.Set[string]
+		expectedRemoved sets.Set[string]
+	}{
+		{
+			name:            "one family is not enough",
+			initialLabels:   []string{"sig/node"},
+			expectedAdded:   sets.New[string]("needs-triage"),
+			expectedRemoved: sets.New[string](),
+		},
+		{
+			name:            "several labels of one family count once",
+			initialLabels:   []string{"sig/node", "sig/network"},
+			expectedAdded:   sets.New[string]("needs-triage"),
+			expectedRemoved: sets.New[string](),
+		},
+		{
+			name:            "two families satisfy the requirement",
+			initialLabels:   []string{"sig/node", "kind/bug"},
+			expectedAdded:   sets.New[string](),
+			expectedRemoved: sets.New[string](),
+		},
+		{
+			name:            "two families remove the missing label",
+			initialLabels:   []string{"needs-triage", "kind/bug", "priority/important-soon"},
+			expectedAdded:   sets.New[string](),
+			expectedRemoved: sets.New[string]("needs-triage"),
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			log := logrus.WithField("plugin", "require-matching-label")
+			fghc := newFakeGitHub(tc.initialLabels...)
+			e := &event{org: "k8s", repo: "k8s", number: 5}
+			if err := handle(log, fghc, &fakePruner{}, clock.RealClock{}, configs, e); err != nil {
+				t.Fatalf("Unexpected error from handle: %v.", err)
+			}
+			if !tc.expectedAdded.Equal(fghc.IssueLabelsAdded) {
+				t.Errorf("Expected the %q labels to be added, but got %q.", sets.List(tc.expectedAdded), sets.List(fghc.IssueLabelsAdded))
+			}
+			if !tc.expectedRemoved.Equal(fghc.IssueLabelsRemoved) {
+				t.Errorf("Expected the %q labels to be removed, but got %q.", sets.List(tc.expectedRemoved), sets.List(fghc.IssueLabelsRemoved))
+			}
+		})
+	}
+}
+
+func TestHandleSeverity(t *testing.T) {
+	tcs := []struct {
+		name          string
+		severity      string
+		initialLabels []string
+
+		expectedAdded   sets.Set[string]
+		expectedRemoved sets.Set[string]
+	}{
+		{
+			name:            "missing label without severity",
+			expectedAdded:   sets.New[string]("needs-sig"),
+			expectedRemoved: sets.New[string](),
+		},
+		{
+			name:            "missing label namespaced by the blocking severity",
+			severity:        "blocking",
+			expectedAdded:   sets.New[string]("blocking/needs-sig"),
+			expectedRemoved: sets.New[string](),
+		},
+		{
+			name:            "missing label namespaced by the warning severity",
+			severity:        "warning",
+			expectedAdded:   sets.New[string]("warning/needs-sig"),
+			expectedRemoved: sets.New[string](),
+		},
+		{
+			name:            "namespaced missing label is removed once satisfied",
+			severity:        "blocking",
+			initialLabels:   []string{"blocking/needs-sig", "sig/node"},
+			expectedAdded:   sets.New[string](),
+			expectedRemoved: sets.New[string]("blocking/needs-sig"),
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			configs := []plugins.RequireMatchingLabel{
+				{
+					Org:          "k8s",
+					Issues:       utilpointer.Bool(true),
+					Re:           regexp.MustCompile(`^sig/`),
+					MissingLabel: "needs-sig",
+					Severity:     tc.severity,
+				},
+			}
+			log := logrus.WithField("plugin", "require-matching-label")
+			fghc := newFakeGitHub(tc.initialLabels...)
+			e := &event{org: "k8s", repo: "k8s", number: 5}
+			if err := handle(log, fghc, &fakePruner{}, clock.RealClock{}, configs, e); err != nil {
+				t.Fatalf("Unexpected error from handle: %v.", err)
+			}
+			if !tc.expectedAdded.Equal(fghc.IssueLabelsAdded) {
+				t.Errorf("Expected the %q labels to be added, but got %q.", sets.List(tc.expectedAdded), sets.List(fghc.IssueLabelsAdded))
+			}
+			if !tc.expectedRemoved.Equal(fghc.IssueLabelsRemoved) {
+				t.Errorf("Expected the %q labels to be removed, but got %q.", sets.List(tc.expectedRemoved), sets.List(fghc.IssueLabelsRemoved))
+			}
+		})
+	}
+}
+
+func TestHandleDryRun(t *testing.T) {
+	configs := []plugins.RequireMatchingLabel{
+		{
+			Org:            "k8s",
+			Issues:         utilpointer.Bool(true),
+			Re:             regexp.MustCompile(`^sig/`),
+			MissingLabel:   "needs-sig",
+			MissingComment: "Please add a sig label.",
+		},
+	}
+	tcs := []struct {
+		name          string
+		commenter     string
+		initialLabels []string
+
+		expectedReply []string
+	}{
+		{
+			name:          "missing family is reported",
+			commenter:     "maintainer",
+			initialLabels: []string{"kind/bug"},
+			expectedReply: []string{
+				"checking the required labels of this issue would:",
+				"- add the `needs-sig` label(s)",
+				`- post the comment "Please add a sig label."`,
+			},
+		},
+		{
+			name:          "satisfied family is reported",
+			commenter:     "maintainer",
+			initialLabels: []string{"sig/node"},
+			expectedReply: []string{"checking the required labels of this issue would not change any labels or post a comment."},
+		},
+		{
+			name:          "non-maintainers are refused",
+			commenter:     "contributor",
+			initialLabels: []string{"kind/bug"},
+			expectedReply: []string{"only maintainers of this repo can use `/would-require-labels`."},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			log := logrus.WithField("plugin", "require-matching-label")
+			fghc := newFakeGitHub(tc.initialLabels...)
+			fghc.maintainers = sets.New[string]("maintainer")
+			ce := &github.GenericCommentEvent{
+				Repo:   github.Repo{Owner: github.User{Login: "k8s"}, Name: "k8s"},
+				Number: 1,
+				User:   github.User{Login: tc.commenter},
+				Body:   "/would-require-labels",
+			}
+			if err := handleDryRun(log, fghc, configs, ce); err != nil {
+				t.Fatalf("Unexpected error from handleDryRun: %v.", err)
+			}
+			if len(fghc.IssueLabelsAdded) != 0 || len(fghc.IssueLabelsRemoved) != 0 {
+				t.Errorf("Expected no label changes, but got %q added and %q removed.", sets.List(fghc.IssueLabelsAdded), sets.List(fghc.IssueLabelsRemoved))
+			}
+			if len(fghc.comments) != 1 {
+				t.Fatalf("Expected exactly one reply, got %q.", fghc.comments)
+			}
+			for _, line := range tc.expectedReply {
+				if !strings.Contains(fghc.comments[0], line) {
+					t.Errorf("Expected the reply to contain %q, got %q.", line, fghc.comments[0])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package size
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/utils/clock"
+
+	"sigs.k8s.io/prow/pkg/github"
+	"sigs.k8s.io/prow/pkg/plugins"
+)
+
+// BackfillOptions selects the historical PRs of a repo to reprocess.
+type BackfillOptions struct {
+	// Numbers are the numbers of the PRs to reprocess.
+	Numbers []int
+	// Since and Until select the PRs created in this time range if Numbers is
+	// empty. A zero Until selects the PRs created since Since.
+	Since, Until time.Time
+	// Interval is the time to wait between reprocessing two PRs, so that
+	// backfilling many PRs does not exhaust the API rate limit.
+	Interval time.Duration
+	// SummarySink receives the summaries of the reprocessed PRs if Summary is
+	// enabled. If it is nil, the sink set with SetSummarySink is used.
+	SummarySink SummarySink
+}
+
+// backfillClient is the subset of github.Client methods needed to backfill.
+type backfillClient interface {
+	githubClient
+	FindIssuesWithOrg(org, query, sort string, asc bool) ([]github.Issue, error)
+}
+
+// Backfill reprocesses the selected historical PRs of a repo as if they were
+// just opened, so that the size labels of PRs that predate the plugin being
+// enabled are backfilled. Reprocessing a PR is idempotent, as its labels are
+// only changed if its size changed. Errors for single PRs do not stop the
+// backfill but are returned in aggregate.
+func Backfill(gc backfillClient, sizes plugins.Size, clk clock.Clock, le *logrus.Entry, org, repo string, opts BackfillOptions) error {
+	numbers := opts.Numbers
+	if len(numbers) == 0 {
+		var err error
+		numbers, err = prsCreatedBetween(gc, org, repo, opts.Since, opts.Until)
+		if err != nil {
+			return err
+		}
+	}
+
+	sink := opts.SummarySink
+	if sink == nil {
+		sink = summarySink
+	}
+	var errs []error
+	for i, number := range numbers {
+		if i > 0 && opts.Interval > 0 {
+			clk.Sleep(opts.Interval)
+		}
+		pr, err := gc.GetPullRequest(org, repo, number)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error getting PR %s/%s#%d: %w", org, repo, number, err))
+			continue
+		}
+		pe := github.PullRequestEvent{
+			Action:      github.PullRequestActionOpened,
+			Number:      number,
+			PullRequest: *pr,
+		}
+		// The PR does not necessarily contain its repo.
+		pe.PullRequest.Base.Repo.Owner.Login = org
+		pe.PullRequest.Base.Repo.Name = repo
+		if err := handlePR(gc, sizes, clk, history, sink, le.WithField("pr", number), pe); err != nil {
+			errs = append(errs, fmt.Errorf("error backfilling PR %s/%s#%d: %w", org, repo, number, err))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// prsCreatedBetween returns the numbers of the PRs of a repo created in the
+// given time range, oldest first.
+func prsCreatedBetween(gc backfillClient, org, repo string, since, until time.Time) ([]int, error) {
+	created := since.UTC().Format(time.RFC3339) + "..*"
+	if !until.IsZero() {
+		created = fmt.Sprintf("%s..%s", since.UTC().Format(time.RFC3339), until.UTC().Format(time.RFC3339))
+	}
+	query := fmt.Sprintf("repo:%s/%s is:pr created:%s", org, repo, created)
+	issues, err := gc.FindIssuesWithOrg(org, query, "created", true)
+	if err != nil {
+		return nil, fmt.Errorf("error searching PRs with %q: %w", query, err)
+	}
+	var numbers []int
+	for _, issue := range issues {
+		numbers = append(numbers, issue.Number)
+	}
+	return numbers, nil
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package size
+
+import (
+	"fmt"
+	"sort"
+
+	"sigs.k8s.io/prow/pkg/github"
+	"sigs.k8s.io/prow/pkg/plugins"
+)
+
+// defaultCheckRunAnnotations is the number of largest files annotated in the
+// size check run if CheckRunAnnotations is unset.
+const defaultCheckRunAnnotations = 5
+
+// largestFiles returns at most n of the counted files with the most counted
+// lines, largest first. Files with the same number of lines are ordered by name.
+func largestFiles(files []fileCount, n int) []fileCount {
+	var counted []fileCount
+	for _, file := range files {
+		if file.Lines > 0 {
+			counted = append(counted, file)
+		}
+	}
+	sort.Slice(counted, func(i, j int) bool {
+		if counted[i].Lines != counted[j].Lines {
+			return counted[i].Lines > counted[j].Lines
+		}
+		return counted[i].Filename < counted[j].Filename
+	})
+	if len(counted) > n {
+		counted = counted[:n]
+	}
+	return counted
+}
+
+// sizeCheckRun returns the completed check run for the given head SHA, which
+// annotates the largest files of the count. The check run is informational,
+// so its conclusion is always neutral.
+func sizeCheckRun(count changeCount, sha string, sizes plugins.Size) github.CheckRun {
+	var annotations []github.CheckRunAnnotation
+	for _, file := range largestFiles(count.files, defaultIfZero(sizes.CheckRunAnnotations, defaultCheckRunAnnotations)) {
+		message := fmt.Sprintf("%d of the %d counted lines of the PR are changed in this file.", file.Lines, count.lines)
+		if file.Capped {
+			message += fmt.Sprintf(" The %d changed lines of the file were capped.", file.Changes)
+		}
+		annotations = append(annotations, github.CheckRunAnnotation{
+			Path:            file.Filename,
+			StartLine:       1,
+			EndLine:         1,
+			AnnotationLevel: "notice",
+			Title:           fmt.Sprintf("%d changed lines", file.Lines),
+			Message:         message,
+		})
+	}
+	return github.CheckRun{
+		Name:       sizes.CheckRunName,
+		HeadSHA:    sha,
+		Status:     "completed",
+		Conclusion: "neutral",
+		Output: github.CheckRunOutput{
+			Title:       count.label(sizes),
+			Summary:     fmt.Sprintf("Counted %d changed lines. The %d largest files are annotated.", count.lines, len(annotations)),
+			Annotations: annotations,
+		},
+	}
+}
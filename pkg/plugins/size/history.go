@@ -0,0 +1,94 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package size
+
+import (
+	"sort"
+	"sync"
+)
+
+const (
+	labelBelowNormal = "size/below-normal"
+	labelNormal      = "size/normal"
+	labelAboveNormal = "size/above-normal"
+)
+
+// sizeHistory holds the number of changed lines of the recently merged PRs of
+// every repo in memory. It is safe for concurrent use.
+type sizeHistory struct {
+	lock sync.Mutex
+	// lines holds the changed lines of merged PRs by org/repo, oldest first.
+	lines map[string][]int
+}
+
+// history is the history of the PRs merged since the plugin started.
+var history = newSizeHistory()
+
+func newSizeHistory() *sizeHistory {
+	return &sizeHistory{lines: map[string][]int{}}
+}
+
+// record records the changed lines of a merged PR of the repo, keeping the
+// last window PRs.
+func (h *sizeHistory) record(repo string, lines, window int) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	recorded := append(h.lines[repo], lines)
+	if len(recorded) > window {
+		recorded = recorded[len(recorded)-window:]
+	}
+	h.lines[repo] = recorded
+}
+
+// median returns the median of the changed lines of the last window merged
+// PRs of the repo. It returns false until at least half of the window has been
+// recorded, as the median of fewer PRs does not reflect the norms of the repo.
+// A nil history has no PRs recorded.
+func (h *sizeHistory) median(repo string, window int) (int, bool) {
+	if h == nil || window <= 0 {
+		return 0, false
+	}
+	h.lock.Lock()
+	recorded := append([]int(nil), h.lines[repo]...)
+	h.lock.Unlock()
+	if len(recorded) > window {
+		recorded = recorded[len(recorded)-window:]
+	}
+	if len(recorded) == 0 || 2*len(recorded) < window {
+		return 0, false
+	}
+	sort.Ints(recorded)
+	mid := len(recorded) / 2
+	if len(recorded)%2 == 0 {
+		return (recorded[mid-1] + recorded[mid]) / 2, true
+	}
+	return recorded[mid], true
+}
+
+// relativeLabel returns the size label of a PR with the given changed lines
+// relative to the median of the repo. PRs that change less than half or more
+// than twice the median are below or above normal respectively.
+func relativeLabel(lines, median int) string {
+	switch {
+	case lines < median/2:
+		return labelBelowNormal
+	case lines > 2*median:
+		return labelAboveNormal
+	default:
+		return labelNormal
+	}
+}
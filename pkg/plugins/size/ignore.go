@@ -0,0 +1,73 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package size
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	gitignore "github.com/denormal/go-gitignore"
+	"github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/prow/pkg/github"
+)
+
+// ignoreFile holds the patterns of the ignore file of a repo, which lists the
+// files that are not counted for the size of PRs in gitignore syntax.
+type ignoreFile struct {
+	patterns gitignore.GitIgnore
+}
+
+// loadIgnoreFile returns the patterns of the ignore file at the given path of
+// a repo at the given commit. If path is empty or the file does not exist,
+// nil is returned, which matches no files. Invalid patterns are skipped.
+func loadIgnoreFile(gc githubClient, le *logrus.Entry, owner, repo, path, sha string) (*ignoreFile, error) {
+	if path == "" {
+		return nil, nil
+	}
+	content, err := gc.GetFile(owner, repo, path, sha)
+	if err != nil {
+		var notFound *github.FileNotFound
+		if errors.As(err, &notFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting %s at %s: %w", path, sha, err)
+	}
+	patterns := gitignore.New(bytes.NewReader(content), "", func(err gitignore.Error) bool {
+		le.WithError(err).Warnf("skipping invalid pattern in %s", path)
+		return true
+	})
+	return &ignoreFile{patterns: patterns}, nil
+}
+
+// Match returns whether the file at the given path is ignored, either by a
+// pattern matching the file or a pattern matching any of its directories.
+func (f *ignoreFile) Match(path string) bool {
+	if f == nil {
+		return false
+	}
+	dirs := strings.Split(path, "/")
+	for i := 1; i < len(dirs); i++ {
+		if match := f.patterns.Relative(strings.Join(dirs[:i], "/"), true); match != nil && match.Ignore() {
+			return true
+		}
+	}
+	match := f.patterns.Relative(path, false)
+	return match != nil && match.Ignore()
+}
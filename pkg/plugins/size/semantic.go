@@ -0,0 +1,137 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package size
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/prow/pkg/genfiles"
+	"sigs.k8s.io/prow/pkg/gitattributes"
+	"sigs.k8s.io/prow/pkg/github"
+)
+
+// countChangedDecls returns the number of changed top-level declarations of
+// every changed Go file that is neither generated nor ignored, keyed by file
+// name. Files that cannot be fetched or parsed are omitted, so that their lines
+// are counted instead.
+func countChangedDecls(gc githubClient, le *logrus.Entry, owner, repo, baseSHA, headSHA string, changes []github.PullRequestChange, gf *genfiles.Group, ga *gitattributes.Group, ignored *ignoreFile) map[string]int {
+	counts := map[string]int{}
+	for _, change := range changes {
+		if !strings.HasSuffix(change.Filename, ".go") || gf.Match(change.Filename) || ga.IsLinguistGenerated(change.Filename) || ignored.Match(change.Filename) {
+			continue
+		}
+		baseName := change.Filename
+		if change.PreviousFilename != "" {
+			baseName = change.PreviousFilename
+		}
+		base, err := goDecls(gc, owner, repo, baseName, baseSHA)
+		if err != nil {
+			le.WithError(err).Infof("counting lines of %s instead of declarations", change.Filename)
+			continue
+		}
+		head, err := goDecls(gc, owner, repo, change.Filename, headSHA)
+		if err != nil {
+			le.WithError(err).Infof("counting lines of %s instead of declarations", change.Filename)
+			continue
+		}
+
+		var changed int
+		for key, src := range head {
+			if base[key] != src {
+				changed++
+			}
+		}
+		for key := range base {
+			if _, ok := head[key]; !ok {
+				changed++
+			}
+		}
+		counts[change.Filename] = changed
+	}
+	return counts
+}
+
+// goDecls returns the source of the top-level declarations of a Go file at the
+// given commit, keyed by their kind and name. A file that does not exist has
+// no declarations.
+func goDecls(gc githubClient, owner, repo, path, sha string) (map[string]string, error) {
+	src, err := gc.GetFile(owner, repo, path, sha)
+	if err != nil {
+		var notFound *github.FileNotFound
+		if errors.As(err, &notFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting %s at %s: %w", path, sha, err)
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, path, src, parser.SkipObjectResolution)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s at %s: %w", path, sha, err)
+	}
+
+	decls := map[string]string{}
+	add := func(key string, node ast.Node) {
+		// Declarations with the same key, e.g. several init functions, are
+		// told apart by their order.
+		for i := 1; ; i++ {
+			k := key
+			if i > 1 {
+				k = fmt.Sprintf("%s#%d", key, i)
+			}
+			if _, ok := decls[k]; !ok {
+				decls[k] = string(src[fset.Position(node.Pos()).Offset:fset.Position(node.End()).Offset])
+				return
+			}
+		}
+	}
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			key := "func " + d.Name.Name
+			if d.Recv != nil && len(d.Recv.List) > 0 {
+				key = fmt.Sprintf("func (%s) %s", types.ExprString(d.Recv.List[0].Type), d.Name.Name)
+			}
+			add(key, d)
+		case *ast.GenDecl:
+			if d.Tok == token.IMPORT {
+				continue
+			}
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					add("type "+s.Name.Name, s)
+				case *ast.ValueSpec:
+					var names []string
+					for _, name := range s.Names {
+						names = append(names, name.Name)
+					}
+					add(fmt.Sprintf("%s %s", d.Tok, strings.Join(names, ", ")), s)
+				}
+			}
+		}
+	}
+	return decls, nil
+}
@@ -20,10 +20,15 @@ limitations under the License.
 package size
 
 import (
+	"encoding/json"
 	"fmt"
+	"regexp"
+	"slices"
 	"strings"
 
+	"github.com/mattn/go-zglob"
 	"github.com/sirupsen/logrus"
+	"k8s.io/utils/clock"
 
 	"sigs.k8s.io/prow/pkg/config"
 	"sigs.k8s.io/prow/pkg/genfiles"
@@ -37,6 +42,8 @@ import (
 // in here represent default values used as fallback if none are provided.
 const pluginName = "size"
 
+var explainRe = regexp.MustCompile(`(?mi)^/size-explain\s*$`)
+
 var defaultSizes = plugins.Size{
 	S:   10,
 	M:   30,
@@ -47,10 +54,20 @@ var defaultSizes = plugins.Size{
 
 func init() {
 	plugins.RegisterPullRequestHandler(pluginName, handlePullRequest, helpProvider)
+	plugins.RegisterGenericCommentHandler(pluginName, handleGenericComment, helpProvider)
 }
 
-func helpProvider(config *plugins.Configuration, _ []config.OrgRepo) (*pluginhelp.PluginHelp, error) {
+func helpProvider(config *plugins.Configuration, enabledRepos []config.OrgRepo) (*pluginhelp.PluginHelp, error) {
 	sizes := sizesOrDefault(config.Size)
+	configInfo := map[string]string{
+		"": thresholdsInfo(sizes),
+	}
+	for _, repo := range enabledRepos {
+		repoSizes := sizesOrDefault(config.SizeFor(repo.Org, repo.Repo))
+		if repoSizes.S != sizes.S || repoSizes.M != sizes.M || repoSizes.L != sizes.L || repoSizes.Xl != sizes.Xl || repoSizes.Xxl != sizes.Xxl {
+			configInfo[repo.String()] = thresholdsInfo(repoSizes)
+		}
+	}
 	yamlSnippet, err := plugins.CommentMap.GenYaml(&plugins.Configuration{
 		Size: plugins.Size{
 			S:   10,
@@ -63,25 +80,40 @@ func helpProvider(config *plugins.Configuration, _ []config.OrgRepo) (*pluginhel
 	if err != nil {
 		logrus.WithError(err).Warnf("cannot generate comments for %s plugin", pluginName)
 	}
-	return &pluginhelp.PluginHelp{
-			Description: "The size plugin manages the 'size/*' labels, maintaining the appropriate label on each pull request as it is updated. Generated files identified by the config file '.generated_files' at the repo root are ignored. Labels are applied based on the total number of lines of changes (additions and deletions).",
-			Config: map[string]string{
-				"": fmt.Sprintf(`The plugin has the following thresholds:<ul>
+	pluginHelp := &pluginhelp.PluginHelp{
+		Description: "The size plugin manages the 'size/*' labels, maintaining the appropriate label on each pull request as it is updated. Generated files identified by the config file '.generated_files' at the repo root are ignored. Labels are applied based on the total number of lines of changes (additions and deletions).",
+		Config:      configInfo,
+		Snippet:     yamlSnippet,
+	}
+	pluginHelp.AddCommand(pluginhelp.Command{
+		Usage:       "/size-explain",
+		Description: "Explains how the size label of the pull request was computed.",
+		Featured:    false,
+		WhoCanUse:   "Members of the organization.",
+		Examples:    []string{"/size-explain"},
+	})
+	return pluginHelp, nil
+}
+
+func thresholdsInfo(sizes plugins.Size) string {
+	return fmt.Sprintf(`The plugin has the following thresholds:<ul>
 <li>size/XS:  0-%d</li>
 <li>size/S:   %d-%d</li>
 <li>size/M:   %d-%d</li>
 <li>size/L:   %d-%d</li>
 <li>size/XL:  %d-%d</li>
 <li>size/XXL: %d+</li>
-</ul>`, sizes.S-1, sizes.S, sizes.M-1, sizes.M, sizes.L-1, sizes.L, sizes.Xl-1, sizes.Xl, sizes.Xxl-1, sizes.Xxl),
-			},
-			Snippet: yamlSnippet,
-		},
-		nil
+</ul>`, sizes.S-1, sizes.S, sizes.M-1, sizes.M, sizes.L-1, sizes.L, sizes.Xl-1, sizes.Xl, sizes.Xxl-1, sizes.Xxl)
 }
 
 func handlePullRequest(pc plugins.Agent, pe github.PullRequestEvent) error {
-	return handlePR(pc.GitHubClient, sizesOrDefault(pc.PluginConfig.Size), pc.Logger, pe)
+	sizes := sizesOrDefault(pc.PluginConfig.SizeFor(pe.Repo.Owner.Login, pe.Repo.Name))
+	return handlePR(pc.GitHubClient, sizes, clock.RealClock{}, history, summarySink, pc.Logger, pe)
+}
+
+func handleGenericComment(pc plugins.Agent, ce github.GenericCommentEvent) error {
+	sizes := sizesOrDefault(pc.PluginConfig.SizeFor(ce.Repo.Owner.Login, ce.Repo.Name))
+	return handleComment(pc.GitHubClient, sizes, history, pc.Logger, ce)
 }
 
 // Strict subset of github.Client methods.
@@ -91,20 +123,100 @@ type githubClient interface {
 	GetIssueLabels(org, repo string, number int) ([]github.Label, error)
 	GetFile(org, repo, filepath, commit string) ([]byte, error)
 	GetPullRequestChanges(org, repo string, number int) ([]github.PullRequestChange, error)
+	GetPullRequest(org, repo string, number int) (*github.PullRequest, error)
+	GetMergeBase(org, repo, base, head string) (string, error)
+	CreateComment(owner, repo string, number int, comment string) error
+	IsMember(org, user string) (bool, error)
+	CreateStatus(org, repo, SHA string, s github.Status) error
+	CreateCheckRun(org, repo string, checkRun github.CheckRun) error
+	ListIssueComments(org, repo string, number int) ([]github.IssueComment, error)
+	TeamBySlugHasMember(org string, teamSlug string, memberLogin string) (bool, error)
 }
 
-func handlePR(gc githubClient, sizes plugins.Size, le *logrus.Entry, pe github.PullRequestEvent) error {
-	if !isPRChanged(pe) {
-		return nil
+// skipReason describes why a changed file was not counted.
+type skipReason string
+
+const (
+	skipGeneratedFiles    skipReason = "listed in .generated_files"
+	skipLinguistGenerated skipReason = "marked linguist-generated in .gitattributes"
+	skipIgnoreFile        skipReason = "listed in the size ignore file"
+)
+
+// skipReasons lists all skip reasons in the order they are reported.
+var skipReasons = []skipReason{skipGeneratedFiles, skipLinguistGenerated, skipIgnoreFile}
+
+// changeCount is the result of counting the lines changed in a PR.
+type changeCount struct {
+	// lines is the number of changed lines that were counted.
+	lines int
+	// skipped is the number of files that were not counted, by reason.
+	skipped map[skipReason]int
+	// files describes how every changed file was counted.
+	files []fileCount
+	// capped is the number of files whose lines were capped.
+	capped int
+	// forcedXXL are the changed files that match a glob of ForceXXLGlobs.
+	forcedXXL []string
+	// generatedLines is the number of changed lines of skipped generated files.
+	generatedLines int
+	// bump is the number of classes the size is bumped up by effort labels.
+	bump int
+	// effortLabels are the labels of the PR that bump its size.
+	effortLabels []string
+	// untested is true if the PR changes source files but no test files,
+	// which bumps its size by one class.
+	untested bool
+	// relative is true if the PR is sized relative to median, the median of
+	// the changed lines of the recently merged PRs of its repo.
+	relative bool
+	median   int
+}
+
+// class returns the size class of the count.
+func (c changeCount) class(sizes plugins.Size) size {
+	if len(c.forcedXXL) > 0 {
+		return sizeXXL
 	}
+	s := bucket(c.lines, sizes) + size(c.bump)
+	if c.untested {
+		s++
+	}
+	if s > sizeXXL {
+		return sizeXXL
+	}
+	return s
+}
 
-	var (
-		owner = pe.PullRequest.Base.Repo.Owner.Login
-		repo  = pe.PullRequest.Base.Repo.Name
-		num   = pe.PullRequest.Number
-		sha   = pe.PullRequest.Base.SHA
-	)
+// addEffortLabels bumps the size of the count by the bumps of the given
+// labels of the PR according to the EffortLabels.
+func (c *changeCount) addEffortLabels(labels []github.Label, sizes plugins.Size) {
+	for _, label := range labels {
+		if bump, ok := sizes.EffortLabels[label.Name]; ok {
+			c.bump += bump
+			c.effortLabels = append(c.effortLabels, label.Name)
+		}
+	}
+}
+
+// addHistory sizes the count relative to the recently merged PRs of the repo
+// according to the HistoryWindow, if enough of them were recorded.
+func (c *changeCount) addHistory(hist *sizeHistory, repo string, sizes plugins.Size) {
+	c.median, c.relative = hist.median(repo, sizes.HistoryWindow)
+}
+
+// label returns the size label of the count, which is relative to the recently
+// merged PRs of the repo unless the size is forced.
+func (c changeCount) label(sizes plugins.Size) string {
+	if c.relative && len(c.forcedXXL) == 0 {
+		return relativeLabel(c.lines, c.median)
+	}
+	return c.class(sizes).label()
+}
 
+// countPR counts the lines changed in a PR, skipping the files that are
+// generated according to the repo's config files at its base SHA.
+func countPR(gc githubClient, sizes plugins.Size, le *logrus.Entry, owner, repo string, pr *github.PullRequest) (changeCount, error) {
+	sha := pr.Base.SHA
 	gf, err := genfiles.NewGroup(gc, owner, repo, sha)
 	if err != nil {
 		switch err.(type) {
@@ -112,36 +224,224 @@ func handlePR(gc githubClient, sizes plugins.Size, le *logrus.Entry, pe github.P
 			// Continue on parse errors, but warn that something is wrong.
 			le.Warnf("error while parsing .generated_files: %v", err)
 		default:
-			return err
+			return changeCount{}, err
 		}
 	}
 
 	ga, err := gitattributes.NewGroup(func() ([]byte, error) { return gc.GetFile(owner, repo, ".gitattributes", sha) })
 	if err != nil {
-		return err
+		return changeCount{}, err
+	}
+
+	ignored, err := loadIgnoreFile(gc, le, owner, repo, sizes.IgnoreFile, sha)
+	if err != nil {
+		return changeCount{}, err
 	}
 
-	changes, err := gc.GetPullRequestChanges(owner, repo, num)
+	changes, err := gc.GetPullRequestChanges(owner, repo, pr.Number)
 	if err != nil {
-		return fmt.Errorf("can not get PR changes for size plugin: %w", err)
+		return changeCount{}, fmt.Errorf("can not get PR changes for size plugin: %w", err)
+	}
+
+	// The changes of stacked PRs are counted relative to the head of their parent.
+	diffSHA := sha
+	if sizes.IsStackedPRs() {
+		parent, err := parentPR(gc, owner, repo, pr)
+		if err != nil {
+			le.WithError(err).Info("counting changes relative to the base branch")
+		} else if parent != nil {
+			changes = incrementalChanges(gc, le, owner, repo, pr, parent, changes)
+			diffSHA = parent.Head.SHA
+		}
+	}
+
+	var decls map[string]int
+	if sizes.IsGoSemantic() {
+		// Declarations are compared at the merge base, which the changed lines
+		// are relative to, as the base may have moved since the PR branched off.
+		mergeBase, err := gc.GetMergeBase(owner, repo, diffSHA, pr.Head.SHA)
+		if err != nil {
+			le.WithError(err).Info("counting lines instead of declarations")
+		} else {
+			decls = countChangedDecls(gc, le, owner, repo, mergeBase, pr.Head.SHA, changes, gf, ga, ignored)
+		}
 	}
+	count := countChanges(changes, gf, ga, ignored, decls, sizes)
+	count.forcedXXL = forcedXXLFiles(changes, sizes.ForceXXLGlobs, le)
+	count.untested = isUntested(count.files, sizes, le)
+	return count, nil
+}
 
-	var count int
+// forcedXXLFiles returns the names of the changed files that match any of globs.
+func forcedXXLFiles(changes []github.PullRequestChange, globs []string, le *logrus.Entry) []string {
+	var forced []string
 	for _, change := range changes {
-		// Skip generated and linguist-generated files.
-		if gf.Match(change.Filename) || ga.IsLinguistGenerated(change.Filename) {
+		if matchesAny(change.Filename, globs, le) {
+			forced = append(forced, change.Filename)
+		}
+	}
+	return forced
+}
+
+// matchesAny returns true if the file matches any of the globs.
+func matchesAny(filename string, globs []string, le *logrus.Entry) bool {
+	for _, glob := range globs {
+		found, err := zglob.Match(glob, filename)
+		if err != nil {
+			// Should not happen, log err and continue
+			le.WithError(err).Infof("error matching glob %q", glob)
 			continue
 		}
+		if found {
+			return true
+		}
+	}
+	return false
+}
+
+// countChanges sums the additions and deletions of the changes, skipping
+// generated and linguist-generated files and capping the lines of single
+// files as configured. Files with an entry in decls are counted by their
+// changed declarations instead.
+func countChanges(changes []github.PullRequestChange, gf *genfiles.Group, ga *gitattributes.Group, ignored *ignoreFile, decls map[string]int, sizes plugins.Size) changeCount {
+	count := changeCount{skipped: map[skipReason]int{}}
+	var total, counted int
+	for _, change := range changes {
+		file := fileCount{Filename: change.Filename, Changes: change.Additions + change.Deletions}
+		switch {
+		case gf.Match(change.Filename):
+			file.Skipped = skipGeneratedFiles
+			count.generatedLines += file.Changes
+		case ga.IsLinguistGenerated(change.Filename):
+			file.Skipped = skipLinguistGenerated
+			count.generatedLines += file.Changes
+		case ignored.Match(change.Filename):
+			file.Skipped = skipIgnoreFile
+		default:
+			file.Lines = file.Changes
+			if n, ok := decls[change.Filename]; ok {
+				file.Lines = n
+			}
+			total += file.Lines
+			counted++
+		}
+		if file.Skipped != "" {
+			count.skipped[file.Skipped]++
+		}
+		count.files = append(count.files, file)
+	}
+
+	maxLines := maxFileLines(total, counted, sizes)
+	for i := range count.files {
+		file := &count.files[i]
+		if file.Skipped != "" {
+			continue
+		}
+		if maxLines > 0 && file.Lines > maxLines {
+			file.Lines = maxLines
+			file.Capped = true
+			count.capped++
+		}
+		count.lines += file.Lines
+	}
+	return count
+}
+
+// maxFileLines returns the maximum number of lines counted for a single file
+// given the total number of changed lines of the counted files, or zero if
+// files are not capped. The percentage cap is never below an even share of
+// the total, as a file of a PR with few files does not dominate it by being
+// larger than MaxFilePercent, e.g. the only file of a PR.
+func maxFileLines(total, files int, sizes plugins.Size) int {
+	maxLines := sizes.MaxFileLines
+	if sizes.MaxFilePercent > 0 && files > 0 {
+		percent := max(sizes.MaxFilePercent, 100/files)
+		if percentLines := total * percent / 100; maxLines == 0 || percentLines < maxLines {
+			maxLines = percentLines
+		}
+	}
+	return maxLines
+}
+
+func handlePR(gc githubClient, sizes plugins.Size, clk clock.PassiveClock, hist *sizeHistory, sink SummarySink, le *logrus.Entry, pe github.PullRequestEvent) error {
+	var (
+		owner = pe.PullRequest.Base.Repo.Owner.Login
+		repo  = pe.PullRequest.Base.Repo.Name
+		num   = pe.PullRequest.Number
+	)
 
-		count += change.Additions + change.Deletions
+	if isPRMerged(pe) && sizes.HistoryWindow > 0 && hist != nil {
+		if isSkipped(pe.PullRequest.User.Login, pe.PullRequest.Labels, sizes) {
+			return nil
+		}
+		count, err := countPR(gc, sizes, le, owner, repo, &pe.PullRequest)
+		if err != nil {
+			return err
+		}
+		hist.record(owner+"/"+repo, count.lines, sizes.HistoryWindow)
+		return nil
+	}
+	if !isPRChanged(pe) && !isEffortLabelChanged(pe, sizes) && !isSkipLabelChanged(pe, sizes) {
+		return nil
 	}
 
 	labels, err := gc.GetIssueLabels(owner, repo, num)
 	if err != nil {
 		le.Warnf("while retrieving labels, error: %v", err)
 	}
+	if isSkipped(pe.PullRequest.User.Login, labels, sizes) {
+		le.Debugf("skipping automated PR by %s", pe.PullRequest.User.Login)
+		for _, label := range labels {
+			if strings.HasPrefix(label.Name, labelPrefix) || strings.HasPrefix(label.Name, teamLabelPrefix) ||
+				(sizes.GeneratedLabel != "" && label.Name == sizes.GeneratedLabel) {
+				if err := gc.RemoveLabel(owner, repo, num, label.Name); err != nil {
+					le.Warnf("error while removing label %q: %v", label.Name, err)
+				}
+			}
+		}
+		return nil
+	}
+
+	pr := &pe.PullRequest
+	if isBaseChanged(pe) {
+		// The diff of a retargeted PR, and the generated and ignored files, are
+		// those of the new base, which the payload may not reflect yet.
+		pr, err = gc.GetPullRequest(owner, repo, num)
+		if err != nil {
+			return fmt.Errorf("error getting retargeted PR %s/%s#%d: %w", owner, repo, num, err)
+		}
+		le.Debugf("recounting PR retargeted to %s at %s", pr.Base.Ref, pr.Base.SHA)
+	}
+
+	count, err := countPR(gc, sizes, le, owner, repo, pr)
+	if err != nil {
+		return err
+	}
+	count.addEffortLabels(labels, sizes)
+	count.addHistory(hist, owner+"/"+repo, sizes)
+
+	if sizes.StatusContext != "" {
+		status := sizeStatus(count.class(sizes), count.lines, sizes)
+		if err := gc.CreateStatus(owner, repo, pe.PullRequest.Head.SHA, status); err != nil {
+			le.Warnf("error while creating %q status: %v", sizes.StatusContext, err)
+		}
+	}
+
+	if sizes.CheckRunName != "" {
+		if err := gc.CreateCheckRun(owner, repo, sizeCheckRun(count, pe.PullRequest.Head.SHA, sizes)); err != nil {
+			le.Warnf("error while creating %q check run: %v", sizes.CheckRunName, err)
+		}
+	}
+
+	if sizes.IsSummary() {
+		if summary, err := count.summary(owner, repo, num, pe.PullRequest.Head.SHA, sizes); err != nil {
+			le.WithError(err).Warn("error while summarizing the size computation")
+		} else {
+			putSummary(sink, le, owner, repo, num, summary)
+		}
+	}
 
-	newLabel := bucket(count, sizes).label()
+	newLabel := count.label(sizes)
 	var hasLabel bool
 
 	for _, label := range labels {
@@ -157,17 +457,155 @@ func handlePR(gc githubClient, sizes plugins.Size, le *logrus.Entry, pe github.P
 		}
 	}
 
-	if hasLabel {
+	if sizes.GeneratedLabel != "" {
+		updateGeneratedLabel(gc, sizes, le, owner, repo, num, labels, count.generatedLines)
+	}
+
+	if len(sizes.TeamPaths) > 0 {
+		updateTeamLabel(gc, sizes, le, owner, repo, num, pe.PullRequest.User.Login, labels, count.files)
+	}
+
+	if !hasLabel {
+		if err := gc.AddLabel(owner, repo, num, newLabel); err != nil {
+			return fmt.Errorf("error adding label to %s/%s PR #%d: %w", owner, repo, num, err)
+		}
+	}
+
+	if len(count.forcedXXL) == 0 || sizes.ForceXXLComment == "" {
 		return nil
 	}
+	// Comments are deferred until the PR is old enough, so that transient
+	// changes while the PR is actively pushed to do not spam.
+	if age := clk.Since(pe.PullRequest.CreatedAt); age < sizes.CommentMinAgeDuration {
+		le.Debugf("deferring comment on PR that is only %s old", age)
+		return nil
+	}
+	// The label may have been applied without the comment, e.g. before the
+	// comment was configured or while the PR was too young to comment on.
+	comments, err := gc.ListIssueComments(owner, repo, num)
+	if err != nil {
+		return fmt.Errorf("error listing comments of %s/%s PR #%d: %w", owner, repo, num, err)
+	}
+	for _, comment := range comments {
+		if strings.Contains(comment.Body, sizes.ForceXXLComment) {
+			return nil
+		}
+	}
 
-	if err := gc.AddLabel(owner, repo, num, newLabel); err != nil {
-		return fmt.Errorf("error adding label to %s/%s PR #%d: %w", owner, repo, num, err)
+	msg := fmt.Sprintf("%s\n\n- %s", sizes.ForceXXLComment, strings.Join(count.forcedXXL, "\n- "))
+	if err := gc.CreateComment(owner, repo, num, plugins.FormatSimpleResponse(msg)); err != nil {
+		le.Warnf("error while commenting on forced %s label: %v", newLabel, err)
 	}
 
 	return nil
 }
 
+// updateGeneratedLabel applies the GeneratedLabel if the changed lines of
+// generated files exceed the GeneratedLabelThreshold, and removes it otherwise.
+func updateGeneratedLabel(gc githubClient, sizes plugins.Size, le *logrus.Entry, owner, repo string, num int, labels []github.Label, generatedLines int) {
+	var hasLabel bool
+	for _, label := range labels {
+		if label.Name == sizes.GeneratedLabel {
+			hasLabel = true
+		}
+	}
+
+	needsLabel := generatedLines > sizes.GeneratedLabelThreshold
+	if needsLabel && !hasLabel {
+		if err := gc.AddLabel(owner, repo, num, sizes.GeneratedLabel); err != nil {
+			le.Warnf("error while adding label %q: %v", sizes.GeneratedLabel, err)
+		}
+	} else if !needsLabel && hasLabel {
+		if err := gc.RemoveLabel(owner, repo, num, sizes.GeneratedLabel); err != nil {
+			le.Warnf("error while removing label %q: %v", sizes.GeneratedLabel, err)
+		}
+	}
+}
+
+// handleComment replies to a /size-explain command from an org member with
+// the breakdown of how the size of the PR was computed.
+func handleComment(gc githubClient, sizes plugins.Size, hist *sizeHistory, le *logrus.Entry, ce github.GenericCommentEvent) error {
+	if !ce.IsPR || ce.Action != github.GenericCommentActionCreated || !explainRe.MatchString(ce.Body) {
+		return nil
+	}
+
+	var (
+		owner = ce.Repo.Owner.Login
+		repo  = ce.Repo.Name
+		num   = ce.Number
+	)
+
+	isMember, err := gc.IsMember(owner, ce.User.Login)
+	if err != nil {
+		return fmt.Errorf("error checking membership of %s in %s: %w", ce.User.Login, owner, err)
+	}
+	if !isMember {
+		resp := fmt.Sprintf("only members of the %s organization may use /size-explain.", owner)
+		return gc.CreateComment(owner, repo, num, plugins.FormatResponseRaw(ce.Body, ce.HTMLURL, ce.User.Login, resp))
+	}
+
+	pr, err := gc.GetPullRequest(owner, repo, num)
+	if err != nil {
+		return fmt.Errorf("error getting PR %s/%s#%d: %w", owner, repo, num, err)
+	}
+
+	count, err := countPR(gc, sizes, le, owner, repo, pr)
+	if err != nil {
+		return err
+	}
+	count.addEffortLabels(pr.Labels, sizes)
+	count.addHistory(hist, owner+"/"+repo, sizes)
+
+	return gc.CreateComment(owner, repo, num, plugins.FormatResponseRaw(ce.Body, ce.HTMLURL, ce.User.Login, explain(count, sizes)))
+}
+
+// explain describes how the count was bucketed into a size class.
+func explain(count changeCount, sizes plugins.Size) string {
+	str := &strings.Builder{}
+	if len(count.forcedXXL) > 0 {
+		fmt.Fprintf(str, "Counted %d changed lines, but the `%s` label is forced by changes to:\n- %s", count.lines, count.class(sizes).label(), strings.Join(count.forcedXXL, "\n- "))
+	} else {
+		fmt.Fprintf(str, "Counted %d changed lines, resulting in the `%s` label.", count.lines, count.label(sizes))
+	}
+	if count.relative && len(count.forcedXXL) == 0 {
+		fmt.Fprintf(str, "\n\nThe label is relative to the median of %d changed lines of the recently merged PRs.", count.median)
+	}
+	if count.capped > 0 {
+		fmt.Fprintf(str, "\n\nThe lines of %d files were capped.", count.capped)
+	}
+	if len(count.effortLabels) > 0 && len(count.forcedXXL) == 0 && !count.relative {
+		fmt.Fprintf(str, "\n\nThe size class was bumped up by %d, as the PR is labeled `%s`.", count.bump, strings.Join(count.effortLabels, "`, `"))
+	}
+	if count.untested && len(count.forcedXXL) == 0 && !count.relative {
+		fmt.Fprint(str, "\n\nThe size class was bumped up by 1, as the PR changes source files but no test files.")
+	}
+	var skipped []string
+	for _, reason := range skipReasons {
+		if n := count.skipped[reason]; n > 0 {
+			skipped = append(skipped, fmt.Sprintf("- %d %s", n, reason))
+		}
+	}
+	if len(skipped) == 0 {
+		fmt.Fprint(str, "\n\nNo files were skipped.")
+	} else {
+		fmt.Fprintf(str, "\n\nSkipped files:\n%s", strings.Join(skipped, "\n"))
+	}
+	return str.String()
+}
+
+// sizeStatus returns the commit status describing the size of a PR.
+func sizeStatus(s size, lines int, sizes plugins.Size) github.Status {
+	state := github.StatusSuccess
+	if s == sizeXXL && sizes.IsStatusFailXXL() {
+		state = github.StatusFailure
+	}
+	return github.Status{
+		State:       state,
+		Description: fmt.Sprintf("%s: %d changed lines", s.label(), lines),
+		Context:     sizes.StatusContext,
+	}
+}
+
 // One of a set of discrete buckets.
 type size int
 
@@ -243,6 +681,68 @@ func isPRChanged(pe github.PullRequestEvent) bool {
 	}
 }
 
+// isBaseChanged returns true if the event is the edit of a PR that changed
+// its base branch.
+func isBaseChanged(pe github.PullRequestEvent) bool {
+	if pe.Action != github.PullRequestActionEdited {
+		return false
+	}
+	var changes struct {
+		Base struct {
+			Ref struct {
+				From string `json:"from"`
+			} `json:"ref"`
+			Sha struct {
+				From string `json:"from"`
+			} `json:"sha"`
+		} `json:"base"`
+	}
+	// The change is detected best-effort, as the payload of every edit is
+	// counted anyway.
+	if err := json.Unmarshal(pe.Changes, &changes); err != nil {
+		return false
+	}
+	return changes.Base.Ref.From != "" || changes.Base.Sha.From != ""
+}
+
+// isPRMerged returns true if the PR was merged.
+func isPRMerged(pe github.PullRequestEvent) bool {
+	return pe.Action == github.PullRequestActionClosed && pe.PullRequest.Merged
+}
+
+// isEffortLabelChanged returns true if a label of the EffortLabels was added
+// to or removed from the PR.
+func isEffortLabelChanged(pe github.PullRequestEvent, sizes plugins.Size) bool {
+	if pe.Action != github.PullRequestActionLabeled && pe.Action != github.PullRequestActionUnlabeled {
+		return false
+	}
+	_, ok := sizes.EffortLabels[pe.Label.Name]
+	return ok
+}
+
+// isSkipLabelChanged returns true if a label of the SkipLabels was added to or
+// removed from the PR.
+func isSkipLabelChanged(pe github.PullRequestEvent, sizes plugins.Size) bool {
+	if pe.Action != github.PullRequestActionLabeled && pe.Action != github.PullRequestActionUnlabeled {
+		return false
+	}
+	return slices.Contains(sizes.SkipLabels, pe.Label.Name)
+}
+
+// isSkipped returns true if a PR by the given author with the given labels is
+// an automated PR that is not sized.
+func isSkipped(author string, labels []github.Label, sizes plugins.Size) bool {
+	if slices.Contains(sizes.SkipAuthors, author) {
+		return true
+	}
+	for _, label := range labels {
+		if slices.Contains(sizes.SkipLabels, label.Name) {
+			return true
+		}
+	}
+	return false
+}
+
 func defaultIfZero(value, defaultValue int) int {
 	if value == 0 {
 		return defaultValue
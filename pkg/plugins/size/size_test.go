@@ -17,9 +17,19 @@ limitations under the License.
 package size
 
 import (
+	"encoding/json"
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"k8s.io/utils/clock"
+	clocktesting "k8s.io/utils/clock/testing"
+	utilpointer "k8s.io/utils/pointer"
 
 	"sigs.k8s.io/prow/pkg/config"
 	"sigs.k8s.io/prow/pkg/github"
@@ -31,6 +41,21 @@ type ghc struct {
 	labels    map[github.Label]bool
 	files     map[string][]byte
 	prChanges []github.PullRequestChange
+	members   map[string]bool
+	comments  []string
+	statuses  []github.Status
+	checkRuns []github.CheckRun
+	// revisions holds the content of files at specific commits, taking
+	// precedence over files.
+	revisions map[string]map[string][]byte
+	// prs holds other PRs by number along with their changes.
+	prs        map[int]*github.PullRequest
+	prsChanges map[int][]github.PullRequestChange
+	// teams holds the members of teams by slug.
+	teams map[string][]string
+	// mergeBases maps base SHAs to the merge base with the head, which is
+	// the base SHA itself if unset.
+	mergeBases map[string]string
 
 	addLabelErr, removeLabelErr, getIssueLabelsErr,
 	getFileErr, getPullRequestChangesErr error
@@ -66,16 +91,80 @@ func (c *ghc) GetIssueLabels(_, _ string, _ int) (ls []github.Label, err error)
 	return
 }
 
-func (c *ghc) GetFile(_, _, path, _ string) ([]byte, error) {
-	c.T.Logf("GetFile: %s", path)
+func (c *ghc) GetFile(_, _, path, commit string) ([]byte, error) {
+	c.T.Logf("GetFile: %s@%s", path, commit)
+	if content, ok := c.revisions[commit][path]; ok {
+		return content, nil
+	}
 	return c.files[path], c.getFileErr
 }
 
-func (c *ghc) GetPullRequestChanges(_, _ string, _ int) ([]github.PullRequestChange, error) {
-	c.T.Log("GetPullRequestChanges")
+func (c *ghc) GetMergeBase(_, _, base, head string) (string, error) {
+	c.T.Logf("GetMergeBase: %s...%s", base, head)
+	if mergeBase, ok := c.mergeBases[base]; ok {
+		return mergeBase, nil
+	}
+	return base, nil
+}
+
+func (c *ghc) GetPullRequestChanges(_, _ string, number int) ([]github.PullRequestChange, error) {
+	c.T.Logf("GetPullRequestChanges: %d", number)
+	if changes, ok := c.prsChanges[number]; ok {
+		return changes, nil
+	}
 	return c.prChanges, c.getPullRequestChangesErr
 }
 
+func (c *ghc) GetPullRequest(_, _ string, number int) (*github.PullRequest, error) {
+	c.T.Logf("GetPullRequest: %d", number)
+	if pr, ok := c.prs[number]; ok {
+		return pr, nil
+	}
+	return &github.PullRequest{Number: number, Base: github.PullRequestBranch{SHA: "abcd"}}, nil
+}
+
+func (c *ghc) ListIssueComments(_, _ string, _ int) ([]github.IssueComment, error) {
+	c.T.Log("ListIssueComments")
+	var comments []github.IssueComment
+	for _, comment := range c.comments {
+		comments = append(comments, github.IssueComment{Body: comment})
+	}
+	return comments, nil
+}
+
+func (c *ghc) CreateComment(_, _ string, _ int, comment string) error {
+	c.T.Logf("CreateComment: %s", comment)
+	c.comments = append(c.comments, comment)
+	return nil
+}
+
+func (c *ghc) CreateStatus(_, _, sha string, s github.Status) error {
+	c.T.Logf("CreateStatus: %s %+v", sha, s)
+	c.statuses = append(c.statuses, s)
+	return nil
+}
+
+func (c *ghc) CreateCheckRun(_, _ string, checkRun github.CheckRun) error {
+	c.T.Logf("CreateCheckRun: %+v", checkRun)
+	c.checkRuns = append(c.checkRuns, checkRun)
+	return nil
+}
+
+func (c *ghc) IsMember(_, user string) (bool, error) {
+	c.T.Logf("IsMember: %s", user)
+	return c.members[user], nil
+}
+
+func (c *ghc) TeamBySlugHasMember(_, teamSlug, memberLogin string) (bool, error) {
+	c.T.Logf("TeamBySlugHasMember: %s %s", teamSlug, memberLogin)
+	for _, member := range c.teams[teamSlug] {
+		if member == memberLogin {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 func TestSizesOrDefault(t *testing.T) {
 	for _, c := range []struct {
 		input    plugins.Size
@@ -106,12 +195,55 @@ func TestSizesOrDefault(t *testing.T) {
 			expected: defaultSizes,
 		},
 	} {
-		if c.expected != sizesOrDefault(c.input) {
-			t.Fatalf("Unexpected sizes from sizesOrDefault - expected %+v but got %+v", c.expected, sizesOrDefault(c.input))
+		if diff := cmp.Diff(c.expected, sizesOrDefault(c.input)); diff != "" {
+			t.Fatalf("Unexpected sizes from sizesOrDefault (-want +got):\n%s", diff)
 		}
 	}
 }
 
+func TestSizesOrDefaultWithOverrides(t *testing.T) {
+	config := &plugins.Configuration{
+		Size: plugins.Size{
+			L:             200,
+			StatusContext: "size",
+		},
+		SizeOverrides: map[string]plugins.Size{
+			"org":      {Xxl: 2000},
+			"org/repo": {S: 5, L: 150},
+		},
+	}
+	for _, c := range []struct {
+		name      string
+		org, repo string
+		expected  plugins.Size
+	}{
+		{
+			name:     "global config is defaulted",
+			org:      "other",
+			repo:     "repo",
+			expected: plugins.Size{S: 10, M: 30, L: 200, Xl: 500, Xxl: 1000, StatusContext: "size"},
+		},
+		{
+			name:     "org override is defaulted",
+			org:      "org",
+			repo:     "other",
+			expected: plugins.Size{S: 10, M: 30, L: 200, Xl: 500, Xxl: 2000, StatusContext: "size"},
+		},
+		{
+			name:     "repo override is defaulted",
+			org:      "org",
+			repo:     "repo",
+			expected: plugins.Size{S: 5, M: 30, L: 150, Xl: 500, Xxl: 2000, StatusContext: "size"},
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			if diff := cmp.Diff(c.expected, sizesOrDefault(config.SizeFor(c.org, c.repo))); diff != "" {
+				t.Errorf("Unexpected sizes (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
 func TestHandlePR(t *testing.T) {
 	cases := []struct {
 		name        string
@@ -620,7 +752,7 @@ func TestHandlePR(t *testing.T) {
 			// Set up test logging.
 			c.client.T = t
 
-			err := handlePR(c.client, c.sizes, logrus.NewEntry(logrus.New()), c.event)
+			err := handlePR(c.client, c.sizes, clock.RealClock{}, nil, nil, logrus.NewEntry(logrus.New()), c.event)
 
 			if err != nil && c.err == nil {
 				t.Fatalf("handlePR error: %v", err)
@@ -648,49 +780,1712 @@ func TestHandlePR(t *testing.T) {
 	}
 }
 
-func TestHelpProvider(t *testing.T) {
-	enabledRepos := []config.OrgRepo{
-		{Org: "org1", Repo: "repo"},
-		{Org: "org2", Repo: "repo"},
-	}
+func TestHandlePRStatus(t *testing.T) {
+	small := []github.PullRequestChange{{Filename: "foobar", Additions: 3}}
+	large := []github.PullRequestChange{{Filename: "foobar", Additions: 2000}}
+
 	cases := []struct {
-		name         string
-		config       *plugins.Configuration
-		enabledRepos []config.OrgRepo
-		err          bool
+		name     string
+		changes  []github.PullRequestChange
+		context  string
+		failXXL  bool
+		expected []github.Status
 	}{
 		{
-			name:         "Empty config",
-			config:       &plugins.Configuration{},
-			enabledRepos: enabledRepos,
+			name:    "no status without context",
+			changes: large,
 		},
 		{
-			name: "Empty sizes",
-			config: &plugins.Configuration{
-				Size: plugins.Size{},
+			name:    "small PR succeeds",
+			changes: small,
+			context: "size",
+			expected: []github.Status{
+				{State: github.StatusSuccess, Description: "size/XS: 3 changed lines", Context: "size"},
 			},
-			enabledRepos: enabledRepos,
 		},
 		{
-			name: "Sizes specified",
-			config: &plugins.Configuration{
-				Size: plugins.Size{
-					S:   12,
-					M:   15,
-					L:   17,
-					Xl:  21,
-					Xxl: 51,
+			name:    "XXL PR succeeds by default",
+			changes: large,
+			context: "size",
+			expected: []github.Status{
+				{State: github.StatusSuccess, Description: "size/XXL: 2000 changed lines", Context: "size"},
+			},
+		},
+		{
+			name:    "failing status succeeds for a small PR",
+			changes: small,
+			context: "size",
+			failXXL: true,
+			expected: []github.Status{
+				{State: github.StatusSuccess, Description: "size/XS: 3 changed lines", Context: "size"},
+			},
+		},
+		{
+			name:    "failing status fails for an XXL PR",
+			changes: large,
+			context: "size",
+			failXXL: true,
+			expected: []github.Status{
+				{State: github.StatusFailure, Description: "size/XXL: 2000 changed lines", Context: "size"},
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			client := &ghc{
+				T:          t,
+				labels:     map[github.Label]bool{},
+				getFileErr: &github.FileNotFound{},
+				prChanges:  c.changes,
+			}
+			sizes := defaultSizes
+			sizes.StatusContext = c.context
+			sizes.StatusFailXXL = utilpointer.Bool(c.failXXL)
+			event := github.PullRequestEvent{
+				Action: github.PullRequestActionOpened,
+				PullRequest: github.PullRequest{
+					Number: 101,
+					Head:   github.PullRequestBranch{SHA: "efgh"},
+					Base: github.PullRequestBranch{
+						SHA:  "abcd",
+						Repo: github.Repo{Owner: github.User{Login: "kubernetes"}, Name: "kubernetes"},
+					},
 				},
+			}
+			if err := handlePR(client, sizes, clock.RealClock{}, nil, nil, logrus.NewEntry(logrus.New()), event); err != nil {
+				t.Fatalf("handlePR error: %v", err)
+			}
+			if diff := cmp.Diff(c.expected, client.statuses); diff != "" {
+				t.Errorf("unexpected statuses (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCountPRFileCap(t *testing.T) {
+	changes := []github.PullRequestChange{
+		{Filename: "huge.go", Additions: 1800, Deletions: 200},
+		{Filename: "a.go", Additions: 10},
+		{Filename: "b.go", Additions: 5, Deletions: 5},
+		{Filename: "c.go", Additions: 10},
+		{Filename: "d.go", Deletions: 10},
+	}
+
+	cases := []struct {
+		name           string
+		changes        []github.PullRequestChange
+		maxFileLines   int
+		maxFilePercent int
+		expectedLines  int
+		expectedCapped int
+		expectedSize   size
+	}{
+		{
+			name:          "uncapped",
+			expectedLines: 2040,
+			expectedSize:  sizeXXL,
+		},
+		{
+			name:           "absolute cap",
+			maxFileLines:   50,
+			expectedLines:  90,
+			expectedCapped: 1,
+			expectedSize:   sizeM,
+		},
+		{
+			name:           "percentage cap is an even share of the total for few files",
+			maxFilePercent: 10,
+			expectedLines:  448,
+			expectedCapped: 1,
+			expectedSize:   sizeL,
+		},
+		{
+			name: "percentage cap",
+			changes: append([]github.PullRequestChange{
+				{Filename: "e.go", Additions: 10},
+				{Filename: "f.go", Additions: 10},
+				{Filename: "g.go", Additions: 10},
+				{Filename: "h.go", Additions: 10},
+				{Filename: "i.go", Additions: 10},
+				{Filename: "j.go", Additions: 10},
+				{Filename: "k.go", Additions: 10},
+				{Filename: "l.go", Additions: 10},
+				{Filename: "m.go", Additions: 10},
+				{Filename: "n.go", Additions: 10},
+			}, changes...),
+			maxFilePercent: 10,
+			expectedLines:  354,
+			expectedCapped: 1,
+			expectedSize:   sizeL,
+		},
+		{
+			name:           "smaller of both caps applies",
+			maxFileLines:   300,
+			maxFilePercent: 10,
+			expectedLines:  340,
+			expectedCapped: 1,
+			expectedSize:   sizeL,
+		},
+		{
+			name:           "single file is not capped by percentage",
+			changes:        []github.PullRequestChange{{Filename: "huge.go", Additions: 1800, Deletions: 200}},
+			maxFilePercent: 10,
+			expectedLines:  2000,
+			expectedSize:   sizeXXL,
+		},
+		{
+			name: "file of two files is capped at half of the total",
+			changes: []github.PullRequestChange{
+				{Filename: "large.go", Additions: 900},
+				{Filename: "small.go", Additions: 100},
 			},
-			enabledRepos: enabledRepos,
+			maxFilePercent: 10,
+			expectedLines:  600,
+			expectedCapped: 1,
+			expectedSize:   sizeXL,
+		},
+		{
+			name:           "cap above every file changes nothing",
+			maxFileLines:   5000,
+			maxFilePercent: 100,
+			expectedLines:  2040,
+			expectedSize:   sizeXXL,
+		},
+		{
+			name:           "small files are capped too",
+			maxFileLines:   8,
+			expectedLines:  40,
+			expectedCapped: 5,
+			expectedSize:   sizeM,
 		},
 	}
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
-			_, err := helpProvider(c.config, c.enabledRepos)
-			if err != nil && !c.err {
-				t.Fatalf("helpProvider error: %v", err)
+			client := &ghc{
+				T:          t,
+				labels:     map[github.Label]bool{},
+				getFileErr: &github.FileNotFound{},
+				prChanges:  changes,
+			}
+			if c.changes != nil {
+				client.prChanges = c.changes
+			}
+			sizes := defaultSizes
+			sizes.MaxFileLines = c.maxFileLines
+			sizes.MaxFilePercent = c.maxFilePercent
+			pr := &github.PullRequest{Number: 101, Base: github.PullRequestBranch{SHA: "abcd"}, Head: github.PullRequestBranch{SHA: "efgh"}}
+			count, err := countPR(client, sizes, logrus.NewEntry(logrus.New()), "kubernetes", "kubernetes", pr)
+			if err != nil {
+				t.Fatalf("countPR error: %v", err)
+			}
+			if count.lines != c.expectedLines {
+				t.Errorf("expected %d lines, got %d", c.expectedLines, count.lines)
+			}
+			if count.capped != c.expectedCapped {
+				t.Errorf("expected %d capped files, got %d", c.expectedCapped, count.capped)
+			}
+			if s := bucket(count.lines, sizes); s != c.expectedSize {
+				t.Errorf("expected size %v, got %v", c.expectedSize.label(), s.label())
+			}
+		})
+	}
+}
+
+func TestHandlePRForceXXL(t *testing.T) {
+	cases := []struct {
+		name             string
+		changes          []github.PullRequestChange
+		globs            []string
+		comment          string
+		initialLabels    []string
+		initialComments  []string
+		expectedLabel    string
+		expectedComments []string
+	}{
+		{
+			name:          "no globs",
+			changes:       []github.PullRequestChange{{Filename: "config/frozen.yaml", Additions: 1}},
+			expectedLabel: "size/XS",
+		},
+		{
+			name:          "no matching file",
+			changes:       []github.PullRequestChange{{Filename: "main.go", Additions: 1}},
+			globs:         []string{"config/**/*.yaml"},
+			comment:       "Handle with care.",
+			expectedLabel: "size/XS",
+		},
+		{
+			name: "matching file forces XXL despite a tiny diff",
+			changes: []github.PullRequestChange{
+				{Filename: "main.go", Additions: 1},
+				{Filename: "config/prod/frozen.yaml", Additions: 1},
+			},
+			globs:         []string{"config/**/*.yaml"},
+			expectedLabel: "size/XXL",
+		},
+		{
+			name:             "matching file forces XXL with a comment",
+			changes:          []github.PullRequestChange{{Filename: "config/prod/frozen.yaml", Additions: 1}},
+			globs:            []string{"config/**/*.yaml"},
+			comment:          "Handle with care.",
+			expectedLabel:    "size/XXL",
+			expectedComments: []string{"Handle with care.\n\n- config/prod/frozen.yaml"},
+		},
+		{
+			name:             "comment when already labeled without the comment",
+			changes:          []github.PullRequestChange{{Filename: "config/prod/frozen.yaml", Additions: 1}},
+			globs:            []string{"config/**/*.yaml"},
+			comment:          "Handle with care.",
+			initialLabels:    []string{"size/XXL"},
+			expectedLabel:    "size/XXL",
+			expectedComments: []string{"Handle with care.\n\n- config/prod/frozen.yaml"},
+		},
+		{
+			name:             "no comment when already commented",
+			changes:          []github.PullRequestChange{{Filename: "config/prod/frozen.yaml", Additions: 1}},
+			globs:            []string{"config/**/*.yaml"},
+			comment:          "Handle with care.",
+			initialLabels:    []string{"size/XXL"},
+			initialComments:  []string{"Handle with care.\n\n- config/prod/frozen.yaml"},
+			expectedLabel:    "size/XXL",
+			expectedComments: []string{"Handle with care.\n\n- config/prod/frozen.yaml"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			client := &ghc{
+				T:          t,
+				labels:     map[github.Label]bool{},
+				getFileErr: &github.FileNotFound{},
+				prChanges:  c.changes,
+				comments:   c.initialComments,
+			}
+			for _, label := range c.initialLabels {
+				client.labels[github.Label{Name: label}] = true
+			}
+			sizes := defaultSizes
+			sizes.ForceXXLGlobs = c.globs
+			sizes.ForceXXLComment = c.comment
+			event := github.PullRequestEvent{
+				Action: github.PullRequestActionOpened,
+				PullRequest: github.PullRequest{
+					Number: 101,
+					Base: github.PullRequestBranch{
+						SHA:  "abcd",
+						Repo: github.Repo{Owner: github.User{Login: "kubernetes"}, Name: "kubernetes"},
+					},
+				},
+			}
+			if err := handlePR(client, sizes, clock.RealClock{}, nil, nil, logrus.NewEntry(logrus.New()), event); err != nil {
+				t.Fatalf("handlePR error: %v", err)
+			}
+			if !client.labels[github.Label{Name: c.expectedLabel}] || len(client.labels) != 1 {
+				t.Errorf("expected only the %s label, got %v", c.expectedLabel, client.labels)
+			}
+			if len(client.comments) != len(c.expectedComments) {
+				t.Fatalf("expected %d comments, got %q", len(c.expectedComments), client.comments)
+			}
+			for i, want := range c.expectedComments {
+				if !strings.Contains(client.comments[i], want) {
+					t.Errorf("expected comment to contain %q, got %q", want, client.comments[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCountPRGoSemantic(t *testing.T) {
+	revisions := map[string]map[string][]byte{
+		"abcd": {
+			"main.go": []byte("package main\n\nimport \"fmt\"\n\nfunc a() {\n\tfmt.Println(\"a\")\n}\n\nfunc b() {}\n\ntype T struct{}\n"),
+			"old.go":  []byte("package main\n\nfunc x() {}\n"),
+		},
+		"efgh": {
+			"main.go":   []byte("package main\n\nimport \"fmt\"\n\nfunc a() {\n" + strings.Repeat("\tfmt.Println(\"a\")\n", 40) + "}\n\nfunc c() {}\n\ntype T struct{}\n"),
+			"broken.go": []byte("package main\n\nfunc {"),
+		},
+	}
+	changes := []github.PullRequestChange{
+		{Filename: "main.go", Additions: 45, Deletions: 3},
+		{Filename: "old.go", Status: "removed", Deletions: 20},
+		{Filename: "broken.go", Status: "added", Additions: 12},
+		{Filename: "README.md", Additions: 5},
+	}
+
+	cases := []struct {
+		name          string
+		goSemantic    bool
+		expectedLines int
+		expectedSize  size
+	}{
+		{
+			name:          "line based",
+			expectedLines: 85,
+			expectedSize:  sizeM,
+		},
+		{
+			name:       "semantic",
+			goSemantic: true,
+			// main.go: a changed, b removed, c added; old.go: x removed;
+			// broken.go and README.md by lines.
+			expectedLines: 3 + 1 + 12 + 5,
+			expectedSize:  sizeS,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			client := &ghc{
+				T:          t,
+				labels:     map[github.Label]bool{},
+				getFileErr: &github.FileNotFound{},
+				prChanges:  changes,
+				revisions:  revisions,
+			}
+			sizes := defaultSizes
+			sizes.GoSemantic = utilpointer.Bool(c.goSemantic)
+			pr := &github.PullRequest{Number: 101, Base: github.PullRequestBranch{SHA: "abcd"}, Head: github.PullRequestBranch{SHA: "efgh"}}
+			count, err := countPR(client, sizes, logrus.NewEntry(logrus.New()), "kubernetes", "kubernetes", pr)
+			if err != nil {
+				t.Fatalf("countPR error: %v", err)
+			}
+			if count.lines != c.expectedLines {
+				t.Errorf("expected %d lines, got %d", c.expectedLines, count.lines)
+			}
+			if s := count.class(sizes); s != c.expectedSize {
+				t.Errorf("expected size %v, got %v", c.expectedSize.label(), s.label())
+			}
+		})
+	}
+}
+
+func TestCountPRGoSemanticMovedBase(t *testing.T) {
+	client := &ghc{
+		T:          t,
+		labels:     map[github.Label]bool{},
+		getFileErr: &github.FileNotFound{},
+		prChanges:  []github.PullRequestChange{{Filename: "main.go", Additions: 30, Deletions: 1}},
+		revisions: map[string]map[string][]byte{
+			// The PR branched off at 1234 and changes b.
+			"1234": {"main.go": []byte("package main\n\nfunc a() {}\n\nfunc b() {}\n")},
+			// The base branch changed a since.
+			"abcd": {"main.go": []byte("package main\n\nfunc a() { println() }\n\nfunc b() {}\n")},
+			"efgh": {"main.go": []byte("package main\n\nfunc a() {}\n\nfunc b() {" + strings.Repeat("\n\tprintln()", 30) + "\n}\n")},
+		},
+		mergeBases: map[string]string{"abcd": "1234"},
+	}
+	sizes := defaultSizes
+	sizes.GoSemantic = utilpointer.Bool(true)
+	pr := &github.PullRequest{Number: 101, Base: github.PullRequestBranch{SHA: "abcd"}, Head: github.PullRequestBranch{SHA: "efgh"}}
+	count, err := countPR(client, sizes, logrus.NewEntry(logrus.New()), "kubernetes", "kubernetes", pr)
+	if err != nil {
+		t.Fatalf("countPR error: %v", err)
+	}
+	if count.lines != 1 {
+		t.Errorf("expected only the declaration changed by the PR to be counted, got %d lines", count.lines)
+	}
+}
+
+func TestCountPRIgnoreFile(t *testing.T) {
+	changes := []github.PullRequestChange{
+		{Filename: "main.go", Additions: 10},
+		{Filename: "docs/guide.md", Additions: 200},
+		{Filename: "site/docs/index.md", Additions: 100},
+		{Filename: "logo.svg", Additions: 300},
+		{Filename: "icons/keep.svg", Additions: 20},
+		{Filename: "CHANGELOG.md", Additions: 50},
+		{Filename: "pkg/CHANGELOG.md", Additions: 5},
+	}
+	ignoreFile := []byte(`# Paths with little review value.
+
+docs/
+*.svg
+!keep.svg
+/CHANGELOG.md
+`)
+
+	cases := []struct {
+		name            string
+		ignoreFile      string
+		files           map[string][]byte
+		expectedLines   int
+		expectedSkipped int
+	}{
+		{
+			name:          "no ignore file configured",
+			files:         map[string][]byte{".size-ignore": ignoreFile},
+			expectedLines: 685,
+		},
+		{
+			name:          "configured ignore file does not exist",
+			ignoreFile:    ".size-ignore",
+			expectedLines: 685,
+		},
+		{
+			name:            "files matching the ignore file are skipped",
+			ignoreFile:      ".size-ignore",
+			files:           map[string][]byte{".size-ignore": ignoreFile},
+			expectedLines:   35,
+			expectedSkipped: 4,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			client := &ghc{
+				T:          t,
+				labels:     map[github.Label]bool{},
+				getFileErr: &github.FileNotFound{},
+				prChanges:  changes,
+				// The ignore file is read at the base of the PR.
+				revisions: map[string]map[string][]byte{"abcd": c.files},
+			}
+			sizes := defaultSizes
+			sizes.IgnoreFile = c.ignoreFile
+			pr := &github.PullRequest{Number: 101, Base: github.PullRequestBranch{SHA: "abcd"}, Head: github.PullRequestBranch{SHA: "efgh"}}
+			count, err := countPR(client, sizes, logrus.NewEntry(logrus.New()), "kubernetes", "kubernetes", pr)
+			if err != nil {
+				t.Fatalf("countPR error: %v", err)
+			}
+			if count.lines != c.expectedLines {
+				t.Errorf("expected %d lines, got %d", c.expectedLines, count.lines)
+			}
+			if n := count.skipped[skipIgnoreFile]; n != c.expectedSkipped {
+				t.Errorf("expected %d skipped files, got %d", c.expectedSkipped, n)
+			}
+			if count.generatedLines != 0 {
+				t.Errorf("expected ignored files not to count as generated, got %d generated lines", count.generatedLines)
+			}
+		})
+	}
+}
+
+func TestCountPRStacked(t *testing.T) {
+	revisions := map[string]map[string][]byte{
+		"parent": {
+			"a.go": []byte("one\ntwo\nthree\n"),
+			"b.go": []byte("unchanged\n"),
+		},
+		"efgh": {
+			"a.go": []byte("one\ntwo\nthree\nfour\nfive\n"),
+			"b.go": []byte("unchanged\n"),
+			"c.go": []byte("1\n2\n3\n4\n5\n"),
+		},
+	}
+	changes := []github.PullRequestChange{
+		{Filename: "a.go", Status: "added", Additions: 5},
+		{Filename: "b.go", Status: "added", Additions: 1},
+		{Filename: "c.go", Status: "added", Additions: 5},
+	}
+	parentChanges := []github.PullRequestChange{
+		{Filename: "a.go", Status: "added", Additions: 3},
+		{Filename: "b.go", Status: "added", Additions: 1},
+	}
+
+	cases := []struct {
+		name          string
+		stackedPRs    bool
+		body          string
+		parentState   string
+		parentBase    string
+		expectedLines int
+	}{
+		{
+			name:          "stacked PRs not configured",
+			body:          "Stacked on #100",
+			parentState:   github.PullRequestStateOpen,
+			parentBase:    "main",
+			expectedLines: 11,
+		},
+		{
+			name:          "PR without parent",
+			stackedPRs:    true,
+			body:          "Fixes #100",
+			parentState:   github.PullRequestStateOpen,
+			parentBase:    "main",
+			expectedLines: 11,
+		},
+		{
+			name:        "changes are counted relative to parent",
+			stackedPRs:  true,
+			body:        "Some description.\n\nStacked on #100\n",
+			parentState: github.PullRequestStateOpen,
+			parentBase:  "main",
+			// a.go: two lines added to the parent; b.go: unchanged; c.go: not changed by the parent.
+			expectedLines: 2 + 5,
+		},
+		{
+			name:          "merged parent is ignored",
+			stackedPRs:    true,
+			body:          "Depends on: #100",
+			parentState:   github.PullRequestStateClosed,
+			parentBase:    "main",
+			expectedLines: 11,
+		},
+		{
+			name:          "parent targeting another branch is ignored",
+			stackedPRs:    true,
+			body:          "Depends on: #100",
+			parentState:   github.PullRequestStateOpen,
+			parentBase:    "release-1.0",
+			expectedLines: 11,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			client := &ghc{
+				T:          t,
+				labels:     map[github.Label]bool{},
+				getFileErr: &github.FileNotFound{},
+				prChanges:  changes,
+				revisions:  revisions,
+				prs: map[int]*github.PullRequest{
+					100: {
+						Number: 100,
+						State:  c.parentState,
+						Base:   github.PullRequestBranch{Ref: c.parentBase, SHA: "abcd"},
+						Head:   github.PullRequestBranch{SHA: "parent"},
+					},
+				},
+				prsChanges: map[int][]github.PullRequestChange{100: parentChanges},
+			}
+			sizes := defaultSizes
+			sizes.StackedPRs = utilpointer.Bool(c.stackedPRs)
+			pr := &github.PullRequest{
+				Number: 101,
+				Body:   c.body,
+				Base:   github.PullRequestBranch{Ref: "main", SHA: "abcd"},
+				Head:   github.PullRequestBranch{SHA: "efgh"},
+			}
+			count, err := countPR(client, sizes, logrus.NewEntry(logrus.New()), "kubernetes", "kubernetes", pr)
+			if err != nil {
+				t.Fatalf("countPR error: %v", err)
+			}
+			if count.lines != c.expectedLines {
+				t.Errorf("expected %d lines, got %d", c.expectedLines, count.lines)
 			}
 		})
 	}
 }
+
+func TestHandlePRCommentMinAge(t *testing.T) {
+	created := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	client := &ghc{
+		T:          t,
+		labels:     map[github.Label]bool{},
+		getFileErr: &github.FileNotFound{},
+		prChanges:  []github.PullRequestChange{{Filename: "config/prod/frozen.yaml", Additions: 1}},
+	}
+	sizes := defaultSizes
+	sizes.ForceXXLGlobs = []string{"config/**/*.yaml"}
+	sizes.ForceXXLComment = "Handle with care."
+	sizes.CommentMinAgeDuration = 10 * time.Minute
+	event := github.PullRequestEvent{
+		Action: github.PullRequestActionSynchronize,
+		PullRequest: github.PullRequest{
+			Number:    101,
+			CreatedAt: created,
+			Base: github.PullRequestBranch{
+				SHA:  "abcd",
+				Repo: github.Repo{Owner: github.User{Login: "kubernetes"}, Name: "kubernetes"},
+			},
+		},
+	}
+
+	for _, step := range []struct {
+		name             string
+		age              time.Duration
+		expectedComments int
+	}{
+		{
+			name: "young PR is labeled without a comment",
+			age:  time.Minute,
+		},
+		{
+			name:             "aged PR gets the deferred comment",
+			age:              11 * time.Minute,
+			expectedComments: 1,
+		},
+		{
+			name:             "comment is not repeated",
+			age:              20 * time.Minute,
+			expectedComments: 1,
+		},
+	} {
+		clk := clocktesting.NewFakePassiveClock(created.Add(step.age))
+		if err := handlePR(client, sizes, clk, nil, nil, logrus.NewEntry(logrus.New()), event); err != nil {
+			t.Fatalf("%s: handlePR error: %v", step.name, err)
+		}
+		if !client.labels[github.Label{Name: "size/XXL"}] {
+			t.Errorf("%s: expected the size/XXL label, got %v", step.name, client.labels)
+		}
+		if len(client.comments) != step.expectedComments {
+			t.Errorf("%s: expected %d comments, got %q", step.name, step.expectedComments, client.comments)
+		}
+	}
+}
+
+func TestHandlePRGeneratedLabel(t *testing.T) {
+	files := map[string][]byte{".generated_files": []byte(`path-prefix generated`)}
+	cases := []struct {
+		name          string
+		changes       []github.PullRequestChange
+		initialLabels []string
+		expected      []string
+	}{
+		{
+			name: "large generated changes with small source changes",
+			changes: []github.PullRequestChange{
+				{Filename: "main.go", Additions: 5},
+				{Filename: "generated/zz_api.go", Additions: 3000, Deletions: 200},
+			},
+			expected: []string{"size/XS", "generated/large"},
+		},
+		{
+			name: "small generated changes",
+			changes: []github.PullRequestChange{
+				{Filename: "main.go", Additions: 5},
+				{Filename: "generated/zz_api.go", Additions: 50},
+			},
+			expected: []string{"size/XS"},
+		},
+		{
+			name: "label is removed once generated changes shrink",
+			changes: []github.PullRequestChange{
+				{Filename: "main.go", Additions: 5},
+				{Filename: "generated/zz_api.go", Additions: 50},
+			},
+			initialLabels: []string{"size/XS", "generated/large"},
+			expected:      []string{"size/XS"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			client := &ghc{
+				T:         t,
+				labels:    map[github.Label]bool{},
+				files:     files,
+				prChanges: c.changes,
+			}
+			for _, label := range c.initialLabels {
+				client.labels[github.Label{Name: label}] = true
+			}
+			sizes := defaultSizes
+			sizes.GeneratedLabel = "generated/large"
+			sizes.GeneratedLabelThreshold = 1000
+			event := github.PullRequestEvent{
+				Action: github.PullRequestActionOpened,
+				PullRequest: github.PullRequest{
+					Number: 101,
+					Base: github.PullRequestBranch{
+						SHA:  "abcd",
+						Repo: github.Repo{Owner: github.User{Login: "kubernetes"}, Name: "kubernetes"},
+					},
+				},
+			}
+			if err := handlePR(client, sizes, clock.RealClock{}, nil, nil, logrus.NewEntry(logrus.New()), event); err != nil {
+				t.Fatalf("handlePR error: %v", err)
+			}
+			var labels []string
+			for label, ok := range client.labels {
+				if ok {
+					labels = append(labels, label.Name)
+				}
+			}
+			if diff := cmp.Diff(c.expected, labels, cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+				t.Errorf("unexpected labels (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestHandlePRBaseChanged(t *testing.T) {
+	cases := []struct {
+		name     string
+		changes  string
+		expected []string
+	}{
+		{
+			name:     "retargeted PR is counted against the new base",
+			changes:  `{"base": {"ref": {"from": "release-1.0"}, "sha": {"from": "old"}}}`,
+			expected: []string{"size/L"},
+		},
+		{
+			name:     "edited PR is counted against the base of the payload",
+			changes:  `{"title": {"from": "WIP: vendor lib"}}`,
+			expected: []string{"size/S"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			client := &ghc{
+				T:          t,
+				labels:     map[github.Label]bool{{Name: "size/S"}: true},
+				getFileErr: &github.FileNotFound{},
+				prChanges: []github.PullRequestChange{
+					{Filename: "main.go", Additions: 20},
+					{Filename: "vendor/lib.go", Additions: 80},
+				},
+				// Only the old base marks the vendored files as generated.
+				revisions: map[string]map[string][]byte{
+					"old": {".generated_files": []byte("path-prefix vendor")},
+				},
+				prs: map[int]*github.PullRequest{
+					101: {Number: 101, Base: github.PullRequestBranch{Ref: "main", SHA: "new"}},
+				},
+			}
+			event := github.PullRequestEvent{
+				Action: github.PullRequestActionEdited,
+				PullRequest: github.PullRequest{
+					Number: 101,
+					// The payload may still reflect the old base.
+					Base: github.PullRequestBranch{
+						Ref:  "release-1.0",
+						SHA:  "old",
+						Repo: github.Repo{Owner: github.User{Login: "kubernetes"}, Name: "kubernetes"},
+					},
+				},
+				Changes: json.RawMessage(c.changes),
+			}
+			if err := handlePR(client, defaultSizes, clock.RealClock{}, nil, nil, logrus.NewEntry(logrus.New()), event); err != nil {
+				t.Fatalf("handlePR error: %v", err)
+			}
+			var labels []string
+			for label, ok := range client.labels {
+				if ok {
+					labels = append(labels, label.Name)
+				}
+			}
+			if diff := cmp.Diff(c.expected, labels, cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+				t.Errorf("unexpected labels (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestHandlePRSkipped(t *testing.T) {
+	cases := []struct {
+		name          string
+		action        github.PullRequestEventAction
+		author        string
+		label         string
+		initialLabels []string
+		expected      []string
+	}{
+		{
+			name:          "bot PR is not sized and loses its size label",
+			action:        github.PullRequestActionSynchronize,
+			author:        "dependabot[bot]",
+			initialLabels: []string{"size/XS"},
+		},
+		{
+			name:          "bot PR loses its generated label",
+			action:        github.PullRequestActionSynchronize,
+			author:        "dependabot[bot]",
+			initialLabels: []string{"size/XS", "lots-of-generated-code", "kind/bug"},
+			expected:      []string{"kind/bug"},
+		},
+		{
+			name:          "PR with a skip label is not sized",
+			action:        github.PullRequestActionOpened,
+			author:        "alice",
+			initialLabels: []string{"dependencies"},
+			expected:      []string{"dependencies"},
+		},
+		{
+			name:          "adding a skip label removes the size label",
+			action:        github.PullRequestActionLabeled,
+			author:        "alice",
+			label:         "dependencies",
+			initialLabels: []string{"dependencies", "size/M"},
+			expected:      []string{"dependencies"},
+		},
+		{
+			name:          "removing a skip label sizes the PR",
+			action:        github.PullRequestActionUnlabeled,
+			author:        "alice",
+			label:         "dependencies",
+			initialLabels: []string{"kind/bug"},
+			expected:      []string{"kind/bug", "size/M"},
+		},
+		{
+			name:     "human PR is sized",
+			action:   github.PullRequestActionOpened,
+			author:   "alice",
+			expected: []string{"size/M"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			client := &ghc{
+				T:         t,
+				labels:    map[github.Label]bool{},
+				prChanges: []github.PullRequestChange{{Filename: "main.go", Additions: 40}},
+			}
+			for _, label := range c.initialLabels {
+				client.labels[github.Label{Name: label}] = true
+			}
+			sizes := defaultSizes
+			sizes.SkipAuthors = []string{"dependabot[bot]", "renovate[bot]"}
+			sizes.SkipLabels = []string{"dependencies"}
+			sizes.GeneratedLabel = "lots-of-generated-code"
+			event := github.PullRequestEvent{
+				Action: c.action,
+				Label:  github.Label{Name: c.label},
+				PullRequest: github.PullRequest{
+					Number: 101,
+					User:   github.User{Login: c.author},
+					Base: github.PullRequestBranch{
+						SHA:  "abcd",
+						Repo: github.Repo{Owner: github.User{Login: "kubernetes"}, Name: "kubernetes"},
+					},
+				},
+			}
+			if err := handlePR(client, sizes, clock.RealClock{}, nil, nil, logrus.NewEntry(logrus.New()), event); err != nil {
+				t.Fatalf("handlePR error: %v", err)
+			}
+			var labels []string
+			for label, ok := range client.labels {
+				if ok {
+					labels = append(labels, label.Name)
+				}
+			}
+			if diff := cmp.Diff(c.expected, labels, cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+				t.Errorf("unexpected labels (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestHandlePRUntested(t *testing.T) {
+	cases := []struct {
+		name           string
+		changes        []github.PullRequestChange
+		sourcePatterns []string
+		expected       string
+	}{
+		{
+			name:     "source-only PR is bumped from size/M to size/L",
+			changes:  []github.PullRequestChange{{Filename: "pkg/foo/foo.go", Additions: 40}},
+			expected: "size/L",
+		},
+		{
+			name: "PR changing source and tests is not bumped",
+			changes: []github.PullRequestChange{
+				{Filename: "pkg/foo/foo.go", Additions: 30},
+				{Filename: "pkg/foo/foo_test.go", Additions: 10},
+			},
+			expected: "size/M",
+		},
+		{
+			name:     "docs-only PR is not bumped",
+			changes:  []github.PullRequestChange{{Filename: "docs/README.md", Additions: 40}},
+			expected: "size/M",
+		},
+		{
+			name:     "test-only PR is not bumped",
+			changes:  []github.PullRequestChange{{Filename: "pkg/foo/foo_test.go", Additions: 40}},
+			expected: "size/M",
+		},
+		{
+			name:           "PR changing no source files matching the patterns is not bumped",
+			changes:        []github.PullRequestChange{{Filename: "config/prow.yaml", Additions: 40}},
+			sourcePatterns: []string{"**/*.go"},
+			expected:       "size/M",
+		},
+		{
+			name: "generated source files are not source files",
+			changes: []github.PullRequestChange{
+				{Filename: "docs/README.md", Additions: 40},
+				{Filename: "pkg/foo/zz_generated.go", Additions: 40},
+			},
+			expected: "size/M",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			client := &ghc{
+				T:         t,
+				labels:    map[github.Label]bool{},
+				prChanges: c.changes,
+				files: map[string][]byte{
+					".generated_files": []byte("file-name zz_generated.go"),
+				},
+			}
+			sizes := defaultSizes
+			sizes.TestFilePatterns = []string{"**/*_test.go"}
+			sizes.SourceFilePatterns = c.sourcePatterns
+			event := github.PullRequestEvent{
+				Action: github.PullRequestActionOpened,
+				PullRequest: github.PullRequest{
+					Number: 101,
+					Base: github.PullRequestBranch{
+						SHA:  "abcd",
+						Repo: github.Repo{Owner: github.User{Login: "kubernetes"}, Name: "kubernetes"},
+					},
+				},
+			}
+			if err := handlePR(client, sizes, clock.RealClock{}, nil, nil, logrus.NewEntry(logrus.New()), event); err != nil {
+				t.Fatalf("handlePR error: %v", err)
+			}
+			var labels []string
+			for label, ok := range client.labels {
+				if ok {
+					labels = append(labels, label.Name)
+				}
+			}
+			if diff := cmp.Diff([]string{c.expected}, labels); diff != "" {
+				t.Errorf("unexpected labels (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestHandlePRTeamPaths(t *testing.T) {
+	cases := []struct {
+		name          string
+		author        string
+		initialLabels []string
+		expected      []string
+	}{
+		{
+			name:     "only the changes in the paths owned by the author's team are sized",
+			author:   "alice",
+			expected: []string{"size/M", "team-size/S"},
+		},
+		{
+			name:     "paths owned by all teams of the author are sized",
+			author:   "bob",
+			expected: []string{"size/M", "team-size/M"},
+		},
+		{
+			name:          "stale team label is replaced",
+			author:        "alice",
+			initialLabels: []string{"size/M", "team-size/XL"},
+			expected:      []string{"size/M", "team-size/S"},
+		},
+		{
+			name:          "author in no team is not labeled",
+			author:        "carol",
+			initialLabels: []string{"team-size/S"},
+			expected:      []string{"size/M"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			client := &ghc{
+				T:      t,
+				labels: map[github.Label]bool{},
+				prChanges: []github.PullRequestChange{
+					{Filename: "pkg/kubelet/kubelet.go", Additions: 15},
+					{Filename: "pkg/proxy/proxy.go", Additions: 20},
+					{Filename: "cmd/kubectl/main.go", Additions: 40},
+					{Filename: "pkg/kubelet/zz_generated.go", Additions: 300},
+				},
+				files: map[string][]byte{
+					".generated_files": []byte("file-name zz_generated.go"),
+				},
+				teams: map[string][]string{
+					"sig-node":    {"alice", "bob"},
+					"sig-network": {"bob"},
+				},
+			}
+			for _, label := range c.initialLabels {
+				client.labels[github.Label{Name: label}] = true
+			}
+			sizes := defaultSizes
+			sizes.TeamPaths = map[string][]string{
+				"sig-node":    {"pkg/kubelet/**"},
+				"sig-network": {"pkg/proxy/**"},
+			}
+			event := github.PullRequestEvent{
+				Action: github.PullRequestActionOpened,
+				PullRequest: github.PullRequest{
+					Number: 101,
+					User:   github.User{Login: c.author},
+					Base: github.PullRequestBranch{
+						SHA:  "abcd",
+						Repo: github.Repo{Owner: github.User{Login: "kubernetes"}, Name: "kubernetes"},
+					},
+				},
+			}
+			if err := handlePR(client, sizes, clock.RealClock{}, nil, nil, logrus.NewEntry(logrus.New()), event); err != nil {
+				t.Fatalf("handlePR error: %v", err)
+			}
+			var labels []string
+			for label, ok := range client.labels {
+				if ok {
+					labels = append(labels, label.Name)
+				}
+			}
+			if diff := cmp.Diff(c.expected, labels, cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+				t.Errorf("unexpected labels (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestHandlePRCheckRun(t *testing.T) {
+	cases := []struct {
+		name                string
+		checkRunName        string
+		checkRunAnnotations int
+		expectedPaths       [][]string
+	}{
+		{
+			name:                "largest files of a skewed change are annotated",
+			checkRunName:        "size",
+			checkRunAnnotations: 2,
+			expectedPaths:       [][]string{{"pkg/big.go", "pkg/medium.go"}},
+		},
+		{
+			name:          "all counted files are annotated up to the default",
+			checkRunName:  "size",
+			expectedPaths: [][]string{{"pkg/big.go", "pkg/medium.go", "pkg/a.go", "pkg/b.go"}},
+		},
+		{
+			name: "no check run is created by default",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			client := &ghc{
+				T:      t,
+				labels: map[github.Label]bool{},
+				prChanges: []github.PullRequestChange{
+					{Filename: "pkg/a.go", Additions: 3},
+					{Filename: "pkg/medium.go", Additions: 40, Deletions: 20},
+					{Filename: "pkg/b.go", Additions: 3},
+					{Filename: "pkg/big.go", Additions: 400},
+					{Filename: "pkg/zz_generated.go", Additions: 1000},
+				},
+				files: map[string][]byte{
+					".generated_files": []byte("file-name zz_generated.go"),
+				},
+			}
+			sizes := defaultSizes
+			sizes.CheckRunName = c.checkRunName
+			sizes.CheckRunAnnotations = c.checkRunAnnotations
+			event := github.PullRequestEvent{
+				Action: github.PullRequestActionOpened,
+				PullRequest: github.PullRequest{
+					Number: 101,
+					Base: github.PullRequestBranch{
+						SHA:  "abcd",
+						Repo: github.Repo{Owner: github.User{Login: "kubernetes"}, Name: "kubernetes"},
+					},
+					Head: github.PullRequestBranch{SHA: "efgh"},
+				},
+			}
+			if err := handlePR(client, sizes, clock.RealClock{}, nil, nil, logrus.NewEntry(logrus.New()), event); err != nil {
+				t.Fatalf("handlePR error: %v", err)
+			}
+			var paths [][]string
+			for _, checkRun := range client.checkRuns {
+				if checkRun.Name != c.checkRunName || checkRun.HeadSHA != "efgh" {
+					t.Errorf("expected check run %q on efgh, got %q on %s", c.checkRunName, checkRun.Name, checkRun.HeadSHA)
+				}
+				if checkRun.Output.Title != "size/L" {
+					t.Errorf("expected the check run to be titled size/L, got %q", checkRun.Output.Title)
+				}
+				var annotated []string
+				for _, annotation := range checkRun.Output.Annotations {
+					annotated = append(annotated, annotation.Path)
+				}
+				paths = append(paths, annotated)
+			}
+			if diff := cmp.Diff(c.expectedPaths, paths); diff != "" {
+				t.Errorf("unexpected annotated files (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestHandlePREffortLabels(t *testing.T) {
+	cases := []struct {
+		name          string
+		action        github.PullRequestEventAction
+		label         string
+		initialLabels []string
+		expected      []string
+	}{
+		{
+			name:     "PR without effort labels",
+			action:   github.PullRequestActionOpened,
+			expected: []string{"size/M"},
+		},
+		{
+			name:          "effort label bumps size/M to size/L",
+			action:        github.PullRequestActionOpened,
+			initialLabels: []string{"area/security"},
+			expected:      []string{"area/security", "size/L"},
+		},
+		{
+			name:          "bumps of several effort labels add up to at most size/XXL",
+			action:        github.PullRequestActionOpened,
+			initialLabels: []string{"area/security", "area/api"},
+			expected:      []string{"area/api", "area/security", "size/XXL"},
+		},
+		{
+			name:          "adding an effort label resizes the PR",
+			action:        github.PullRequestActionLabeled,
+			label:         "area/security",
+			initialLabels: []string{"area/security", "size/M"},
+			expected:      []string{"area/security", "size/L"},
+		},
+		{
+			name:          "adding another label is ignored",
+			action:        github.PullRequestActionLabeled,
+			label:         "kind/bug",
+			initialLabels: []string{"kind/bug", "size/XS"},
+			expected:      []string{"kind/bug", "size/XS"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			client := &ghc{
+				T:         t,
+				labels:    map[github.Label]bool{},
+				prChanges: []github.PullRequestChange{{Filename: "main.go", Additions: 40}},
+			}
+			for _, label := range c.initialLabels {
+				client.labels[github.Label{Name: label}] = true
+			}
+			sizes := defaultSizes
+			sizes.EffortLabels = map[string]int{"area/security": 1, "area/api": 5}
+			event := github.PullRequestEvent{
+				Action: c.action,
+				Label:  github.Label{Name: c.label},
+				PullRequest: github.PullRequest{
+					Number: 101,
+					Base: github.PullRequestBranch{
+						SHA:  "abcd",
+						Repo: github.Repo{Owner: github.User{Login: "kubernetes"}, Name: "kubernetes"},
+					},
+				},
+			}
+			if err := handlePR(client, sizes, clock.RealClock{}, nil, nil, logrus.NewEntry(logrus.New()), event); err != nil {
+				t.Fatalf("handlePR error: %v", err)
+			}
+			var labels []string
+			for label, ok := range client.labels {
+				if ok {
+					labels = append(labels, label.Name)
+				}
+			}
+			if diff := cmp.Diff(c.expected, labels, cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+				t.Errorf("unexpected labels (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestHandlePRHistory(t *testing.T) {
+	normal := []int{40, 45, 50, 50, 55, 60, 35, 50, 48, 52}
+	cases := []struct {
+		name          string
+		history       []int
+		lines         int
+		initialLabels []string
+		expected      []string
+	}{
+		{
+			name:     "absolute thresholds apply without history",
+			lines:    400,
+			expected: []string{"size/L"},
+		},
+		{
+			name:     "absolute thresholds apply until half of the window was merged",
+			history:  normal[:4],
+			lines:    400,
+			expected: []string{"size/L"},
+		},
+		{
+			name:          "outlier is above normal",
+			history:       normal,
+			lines:         400,
+			initialLabels: []string{"size/L"},
+			expected:      []string{"size/above-normal"},
+		},
+		{
+			name:     "PR like the history is normal",
+			history:  normal,
+			lines:    70,
+			expected: []string{"size/normal"},
+		},
+		{
+			name:     "small PR is below normal",
+			history:  normal,
+			lines:    5,
+			expected: []string{"size/below-normal"},
+		},
+		{
+			name:     "only the last PRs of the window count",
+			history:  append([]int{5000, 5000, 5000, 5000, 5000, 5000}, normal...),
+			lines:    400,
+			expected: []string{"size/above-normal"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			client := &ghc{
+				T:         t,
+				labels:    map[github.Label]bool{},
+				prChanges: []github.PullRequestChange{{Filename: "main.go", Additions: c.lines}},
+			}
+			for _, label := range c.initialLabels {
+				client.labels[github.Label{Name: label}] = true
+			}
+			sizes := defaultSizes
+			sizes.HistoryWindow = 10
+			hist := newSizeHistory()
+			for _, lines := range c.history {
+				hist.record("kubernetes/kubernetes", lines, sizes.HistoryWindow)
+			}
+			event := github.PullRequestEvent{
+				Action: github.PullRequestActionOpened,
+				PullRequest: github.PullRequest{
+					Number: 101,
+					Base: github.PullRequestBranch{
+						SHA:  "abcd",
+						Repo: github.Repo{Owner: github.User{Login: "kubernetes"}, Name: "kubernetes"},
+					},
+				},
+			}
+			if err := handlePR(client, sizes, clock.RealClock{}, hist, nil, logrus.NewEntry(logrus.New()), event); err != nil {
+				t.Fatalf("handlePR error: %v", err)
+			}
+			var labels []string
+			for label, ok := range client.labels {
+				if ok {
+					labels = append(labels, label.Name)
+				}
+			}
+			if diff := cmp.Diff(c.expected, labels, cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+				t.Errorf("unexpected labels (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestHandlePRRecordsMergedPRs(t *testing.T) {
+	sizes := defaultSizes
+	sizes.HistoryWindow = 2
+	hist := newSizeHistory()
+	for i, lines := range []int{10, 30, 50} {
+		client := &ghc{
+			T:         t,
+			labels:    map[github.Label]bool{},
+			prChanges: []github.PullRequestChange{{Filename: "main.go", Additions: lines}},
+		}
+		event := github.PullRequestEvent{
+			Action: github.PullRequestActionClosed,
+			PullRequest: github.PullRequest{
+				Number: 101 + i,
+				Merged: true,
+				Base: github.PullRequestBranch{
+					SHA:  "abcd",
+					Repo: github.Repo{Owner: github.User{Login: "kubernetes"}, Name: "kubernetes"},
+				},
+			},
+		}
+		if err := handlePR(client, sizes, clock.RealClock{}, hist, nil, logrus.NewEntry(logrus.New()), event); err != nil {
+			t.Fatalf("handlePR error: %v", err)
+		}
+		if len(client.labels) != 0 {
+			t.Errorf("expected merged PR not to be labeled, got %v", client.labels)
+		}
+	}
+	median, ok := hist.median("kubernetes/kubernetes", sizes.HistoryWindow)
+	if !ok {
+		t.Fatal("expected the merged PRs to be recorded")
+	}
+	if median != 40 {
+		t.Errorf("expected the median of the last 2 merged PRs to be 40, got %d", median)
+	}
+}
+
+func TestHandleComment(t *testing.T) {
+	mixedChanges := []github.PullRequestChange{
+		{Filename: "foobar", Additions: 20, Deletions: 5},
+		{Filename: "barfoo", Additions: 10, Deletions: 0},
+		{Filename: "generated/what.txt", Additions: 300, Deletions: 0},
+		{Filename: "generated/my/file.txt", Additions: 200, Deletions: 10},
+		{Filename: "vendor/dep.go", Additions: 400, Deletions: 0},
+	}
+	mixedFiles := map[string][]byte{
+		".generated_files": []byte(`path-prefix generated`),
+		".gitattributes":   []byte(`vendor/** linguist-generated=true`),
+	}
+
+	cases := []struct {
+		name     string
+		body     string
+		user     string
+		expected []string
+	}{
+		{
+			name: "unrelated comment is ignored",
+			body: "/size",
+			user: "member",
+		},
+		{
+			name:     "non-member is refused",
+			body:     "/size-explain",
+			user:     "outsider",
+			expected: []string{"only members of the kubernetes organization"},
+		},
+		{
+			name: "member gets the breakdown",
+			body: "/size-explain",
+			user: "member",
+			expected: []string{
+				"Counted 35 changed lines, resulting in the `size/M` label.",
+				"- 2 listed in .generated_files",
+				"- 1 marked linguist-generated in .gitattributes",
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			client := &ghc{
+				T:         t,
+				labels:    map[github.Label]bool{},
+				files:     mixedFiles,
+				prChanges: mixedChanges,
+				members:   map[string]bool{"member": true},
+			}
+			ce := github.GenericCommentEvent{
+				IsPR:   true,
+				Action: github.GenericCommentActionCreated,
+				Body:   c.body,
+				Number: 101,
+				User:   github.User{Login: c.user},
+				Repo: github.Repo{
+					Owner: github.User{Login: "kubernetes"},
+					Name:  "kubernetes",
+				},
+			}
+			if err := handleComment(client, defaultSizes, nil, logrus.NewEntry(logrus.New()), ce); err != nil {
+				t.Fatalf("handleComment error: %v", err)
+			}
+			if len(c.expected) == 0 {
+				if len(client.comments) != 0 {
+					t.Fatalf("expected no comments, got %q", client.comments)
+				}
+				return
+			}
+			if len(client.comments) != 1 {
+				t.Fatalf("expected exactly one comment, got %q", client.comments)
+			}
+			for _, want := range c.expected {
+				if !strings.Contains(client.comments[0], want) {
+					t.Errorf("expected comment to contain %q, got %q", want, client.comments[0])
+				}
+			}
+		})
+	}
+}
+
+func TestHelpProvider(t *testing.T) {
+	enabledRepos := []config.OrgRepo{
+		{Org: "org1", Repo: "repo"},
+		{Org: "org2", Repo: "repo"},
+	}
+	cases := []struct {
+		name         string
+		config       *plugins.Configuration
+		enabledRepos []config.OrgRepo
+		err          bool
+	}{
+		{
+			name:         "Empty config",
+			config:       &plugins.Configuration{},
+			enabledRepos: enabledRepos,
+		},
+		{
+			name: "Empty sizes",
+			config: &plugins.Configuration{
+				Size: plugins.Size{},
+			},
+			enabledRepos: enabledRepos,
+		},
+		{
+			name: "Sizes overridden for a repo",
+			config: &plugins.Configuration{
+				SizeOverrides: map[string]plugins.Size{
+					"org1/repo": {Xxl: 2000},
+				},
+			},
+			enabledRepos: enabledRepos,
+		},
+		{
+			name: "Sizes specified",
+			config: &plugins.Configuration{
+				Size: plugins.Size{
+					S:   12,
+					M:   15,
+					L:   17,
+					Xl:  21,
+					Xxl: 51,
+				},
+			},
+			enabledRepos: enabledRepos,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := helpProvider(c.config, c.enabledRepos)
+			if err != nil && !c.err {
+				t.Fatalf("helpProvider error: %v", err)
+			}
+		})
+	}
+}
+
+// backfillGHC tracks the labels of several PRs.
+type backfillGHC struct {
+	*ghc
+	changes map[int][]github.PullRequestChange
+	labels  map[int][]string
+	added   map[int][]string
+	found   []github.Issue
+	queries []string
+}
+
+func (c *backfillGHC) GetPullRequestChanges(_, _ string, number int) ([]github.PullRequestChange, error) {
+	return c.changes[number], nil
+}
+
+func (c *backfillGHC) GetIssueLabels(_, _ string, number int) ([]github.Label, error) {
+	var labels []github.Label
+	for _, label := range c.labels[number] {
+		labels = append(labels, github.Label{Name: label})
+	}
+	return labels, nil
+}
+
+func (c *backfillGHC) AddLabel(_, _ string, number int, label string) error {
+	c.added[number] = append(c.added[number], label)
+	return nil
+}
+
+func (c *backfillGHC) FindIssuesWithOrg(_, query, _ string, _ bool) ([]github.Issue, error) {
+	c.queries = append(c.queries, query)
+	return c.found, nil
+}
+
+func TestBackfill(t *testing.T) {
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name     string
+		opts     BackfillOptions
+		labels   map[int][]string
+		found    []github.Issue
+		expected map[int][]string
+		queries  []string
+		waited   time.Duration
+	}{
+		{
+			name:     "PRs with the given numbers are labeled",
+			opts:     BackfillOptions{Numbers: []int{1, 3}, Interval: time.Second},
+			expected: map[int][]string{1: {"size/XS"}, 3: {"size/L"}},
+			waited:   time.Second,
+		},
+		{
+			name:     "PRs created in the time range are labeled",
+			opts:     BackfillOptions{Since: since, Until: since.Add(24 * time.Hour)},
+			found:    []github.Issue{{Number: 2}},
+			expected: map[int][]string{2: {"size/M"}},
+			queries:  []string{"repo:kubernetes/kubernetes is:pr created:2024-01-01T00:00:00Z..2024-01-02T00:00:00Z"},
+		},
+		{
+			name:     "PRs created since a time are labeled",
+			opts:     BackfillOptions{Since: since},
+			found:    []github.Issue{{Number: 1}, {Number: 2}},
+			expected: map[int][]string{1: {"size/XS"}, 2: {"size/M"}},
+			queries:  []string{"repo:kubernetes/kubernetes is:pr created:2024-01-01T00:00:00Z..*"},
+		},
+		{
+			name:     "already labeled PRs are not relabeled",
+			opts:     BackfillOptions{Numbers: []int{1, 2}},
+			labels:   map[int][]string{1: {"size/XS"}},
+			expected: map[int][]string{2: {"size/M"}},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			client := &backfillGHC{
+				ghc: &ghc{T: t, labels: map[github.Label]bool{}},
+				changes: map[int][]github.PullRequestChange{
+					1: {{Filename: "a.go", Additions: 5}},
+					2: {{Filename: "a.go", Additions: 50}},
+					3: {{Filename: "a.go", Additions: 200}},
+				},
+				labels: c.labels,
+				added:  map[int][]string{},
+				found:  c.found,
+			}
+			clk := clocktesting.NewFakeClock(since)
+			if err := Backfill(client, defaultSizes, clk, logrus.NewEntry(logrus.New()), "kubernetes", "kubernetes", c.opts); err != nil {
+				t.Fatalf("Backfill error: %v", err)
+			}
+			if diff := cmp.Diff(c.expected, client.added); diff != "" {
+				t.Errorf("unexpected labels (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(c.queries, client.queries); diff != "" {
+				t.Errorf("unexpected search queries (-want +got):\n%s", diff)
+			}
+			if waited := clk.Since(since); waited != c.waited {
+				t.Errorf("expected to wait %s between PRs, waited %s", c.waited, waited)
+			}
+		})
+	}
+}
+
+func TestCountPRSummary(t *testing.T) {
+	client := &ghc{
+		T:      t,
+		labels: map[github.Label]bool{},
+		files: map[string][]byte{
+			".generated_files": []byte("path-prefix generated"),
+		},
+		prChanges: []github.PullRequestChange{
+			{Filename: "main.go", Additions: 40, Deletions: 10},
+			{Filename: "util.go", Additions: 5},
+			{Filename: "generated/zz_generated.go", Additions: 100},
+		},
+	}
+	sizes := defaultSizes
+	sizes.MaxFileLines = 30
+	pr := &github.PullRequest{Number: 101, Base: github.PullRequestBranch{SHA: "abcd"}, Head: github.PullRequestBranch{SHA: "efgh"}}
+	count, err := countPR(client, sizes, logrus.NewEntry(logrus.New()), "kubernetes", "kubernetes", pr)
+	if err != nil {
+		t.Fatalf("countPR error: %v", err)
+	}
+	summary, err := count.summary("kubernetes", "kubernetes", pr.Number, pr.Head.SHA, sizes)
+	if err != nil {
+		t.Fatalf("summary error: %v", err)
+	}
+
+	expected := `{
+		"org": "kubernetes",
+		"repo": "kubernetes",
+		"number": 101,
+		"sha": "efgh",
+		"files": [
+			{"filename": "main.go", "changes": 50, "lines": 30, "capped": true},
+			{"filename": "util.go", "changes": 5, "lines": 5},
+			{"filename": "generated/zz_generated.go", "changes": 100, "lines": 0, "skipped": "listed in .generated_files"}
+		],
+		"skipped": {"listed in .generated_files": 1},
+		"lines": 35,
+		"capped": 1,
+		"label": "size/M"
+	}`
+	var got, want interface{}
+	if err := json.Unmarshal(summary, &got); err != nil {
+		t.Fatalf("summary is not valid JSON: %v", err)
+	}
+	if err := json.Unmarshal([]byte(expected), &want); err != nil {
+		t.Fatalf("expected summary is not valid JSON: %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("unexpected summary (-want +got):\n%s", diff)
+	}
+}
+
+func TestHandlePRSummarySink(t *testing.T) {
+	cases := []struct {
+		name     string
+		summary  *bool
+		expected []string
+	}{
+		{
+			name: "no summary unless enabled",
+		},
+		{
+			name:     "summary is emitted to the sink",
+			summary:  utilpointer.Bool(true),
+			expected: []string{"kubernetes/kubernetes#101"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			client := &ghc{
+				T:          t,
+				labels:     map[github.Label]bool{},
+				getFileErr: &github.FileNotFound{},
+				prChanges:  []github.PullRequestChange{{Filename: "main.go", Additions: 5}},
+			}
+			sizes := defaultSizes
+			sizes.Summary = c.summary
+			var emitted []string
+			sink := SummarySinkFunc(func(org, repo string, number int, summary []byte) error {
+				var s sizeSummary
+				if err := json.Unmarshal(summary, &s); err != nil {
+					t.Errorf("summary is not valid JSON: %v", err)
+				}
+				if s.Label != "size/XS" {
+					t.Errorf("expected the summary of a size/XS PR, got label %q", s.Label)
+				}
+				emitted = append(emitted, fmt.Sprintf("%s/%s#%d", org, repo, number))
+				return nil
+			})
+			event := github.PullRequestEvent{
+				Action: github.PullRequestActionOpened,
+				PullRequest: github.PullRequest{
+					Number: 101,
+					Base: github.PullRequestBranch{
+						SHA:  "abcd",
+						Repo: github.Repo{Owner: github.User{Login: "kubernetes"}, Name: "kubernetes"},
+					},
+					Head: github.PullRequestBranch{SHA: "efgh"},
+				},
+			}
+			if err := handlePR(client, sizes, clock.RealClock{}, nil, sink, logrus.NewEntry(logrus.New()), event); err != nil {
+				t.Fatalf("handlePR error: %v", err)
+			}
+			if diff := cmp.Diff(c.expected, emitted); diff != "" {
+				t.Errorf("unexpected summaries (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPutSummaryLogsWithoutSink(t *testing.T) {
+	logger, hook := test.NewNullLogger()
+	putSummary(nil, logrus.NewEntry(logger), "kubernetes", "kubernetes", 101, []byte(`{"label":"size/XS"}`))
+	entry := hook.LastEntry()
+	if entry == nil {
+		t.Fatal("expected the summary to be logged")
+	}
+	if got := entry.Data["size_summary"]; got != `{"label":"size/XS"}` {
+		t.Errorf("expected the summary in the size_summary field, got %v", got)
+	}
+}
+
+func TestBackfillSummarySink(t *testing.T) {
+	client := &backfillGHC{
+		ghc: &ghc{T: t, labels: map[github.Label]bool{}},
+		changes: map[int][]github.PullRequestChange{
+			1: {{Filename: "a.go", Additions: 5}},
+			2: {{Filename: "a.go", Additions: 50}},
+		},
+		added: map[int][]string{},
+	}
+	sizes := defaultSizes
+	sizes.Summary = utilpointer.Bool(true)
+	var numbers []int
+	opts := BackfillOptions{
+		Numbers: []int{1, 2},
+		SummarySink: SummarySinkFunc(func(_, _ string, number int, _ []byte) error {
+			numbers = append(numbers, number)
+			return nil
+		}),
+	}
+	if err := Backfill(client, sizes, clocktesting.NewFakeClock(time.Now()), logrus.NewEntry(logrus.New()), "kubernetes", "kubernetes", opts); err != nil {
+		t.Fatalf("Backfill error: %v", err)
+	}
+	if diff := cmp.Diff([]int{1, 2}, numbers); diff != "" {
+		t.Errorf("unexpected summarized PRs (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package size
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/prow/pkg/github"
+)
+
+// stackedOnRe matches the line of a PR description that references the PR it
+// is stacked on, e.g. 'Stacked on #123' or 'Depends on: #123'.
+var stackedOnRe = regexp.MustCompile(`(?mi)^\s*(?:stacked|depends) on:?\s+#(\d+)\s*$`)
+
+// parentPR returns the PR that the given PR is stacked on according to its
+// description. Only an open parent targeting the same branch contributes its
+// changes to the diff of the PR, so nil is returned for any other parent.
+func parentPR(gc githubClient, owner, repo string, pr *github.PullRequest) (*github.PullRequest, error) {
+	m := stackedOnRe.FindStringSubmatch(pr.Body)
+	if m == nil {
+		return nil, nil
+	}
+	number, err := strconv.Atoi(m[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid parent PR number %q: %w", m[1], err)
+	}
+	if number == pr.Number {
+		return nil, nil
+	}
+	parent, err := gc.GetPullRequest(owner, repo, number)
+	if err != nil {
+		return nil, fmt.Errorf("error getting parent PR %s/%s#%d: %w", owner, repo, number, err)
+	}
+	if parent.State != github.PullRequestStateOpen || parent.Base.Ref != pr.Base.Ref {
+		return nil, nil
+	}
+	return parent, nil
+}
+
+// incrementalChanges returns the changes of a PR relative to the head of the
+// PR it is stacked on, rather than relative to the branch both PRs target.
+// Files that the parent does not change are taken as is. Files that it does
+// change are diffed between the heads of both PRs and omitted if the PR does
+// not change them any further. Changes that cannot be diffed are taken as is.
+func incrementalChanges(gc githubClient, le *logrus.Entry, owner, repo string, pr, parent *github.PullRequest, changes []github.PullRequestChange) []github.PullRequestChange {
+	parentChanges, err := gc.GetPullRequestChanges(owner, repo, parent.Number)
+	if err != nil {
+		le.WithError(err).Infof("counting changes relative to %s instead of parent PR #%d", pr.Base.Ref, parent.Number)
+		return changes
+	}
+	changedByParent := map[string]bool{}
+	for _, change := range parentChanges {
+		changedByParent[change.Filename] = true
+	}
+
+	var incremental []github.PullRequestChange
+	for _, change := range changes {
+		if !changedByParent[change.Filename] {
+			incremental = append(incremental, change)
+			continue
+		}
+		additions, deletions, err := diffFile(gc, owner, repo, change.Filename, parent.Head.SHA, pr.Head.SHA)
+		if err != nil {
+			le.WithError(err).Infof("counting changes of %s relative to %s instead of parent PR #%d", change.Filename, pr.Base.Ref, parent.Number)
+			incremental = append(incremental, change)
+			continue
+		}
+		if additions+deletions == 0 {
+			continue
+		}
+		change.Additions, change.Deletions, change.Changes = additions, deletions, additions+deletions
+		incremental = append(incremental, change)
+	}
+	return incremental
+}
+
+// diffFile returns the number of lines added and deleted in a file between two
+// commits. A file that does not exist at a commit has no lines.
+func diffFile(gc githubClient, owner, repo, path, fromSHA, toSHA string) (additions, deletions int, err error) {
+	from, err := fileLines(gc, owner, repo, path, fromSHA)
+	if err != nil {
+		return 0, 0, err
+	}
+	to, err := fileLines(gc, owner, repo, path, toSHA)
+	if err != nil {
+		return 0, 0, err
+	}
+	// Lines like closing braces are common, but must not be treated as junk.
+	matcher := difflib.NewMatcherWithJunk(from, to, false, nil)
+	for _, op := range matcher.GetOpCodes() {
+		switch op.Tag {
+		case 'r':
+			deletions += op.I2 - op.I1
+			additions += op.J2 - op.J1
+		case 'd':
+			deletions += op.I2 - op.I1
+		case 'i':
+			additions += op.J2 - op.J1
+		}
+	}
+	return additions, deletions, nil
+}
+
+// fileLines returns the lines of a file at the given commit.
+func fileLines(gc githubClient, owner, repo, path, sha string) ([]string, error) {
+	content, err := gc.GetFile(owner, repo, path, sha)
+	if err != nil {
+		var notFound *github.FileNotFound
+		if errors.As(err, &notFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error getting %s at %s: %w", path, sha, err)
+	}
+	if len(content) == 0 {
+		return nil, nil
+	}
+	return difflib.SplitLines(string(content)), nil
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package size
+
+import (
+	"encoding/json"
+
+	"github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/prow/pkg/plugins"
+)
+
+// SummarySink receives the JSON summaries of the size computations of PRs if
+// Summary is enabled, e.g. to upload them for data pipelines to ingest.
+type SummarySink interface {
+	PutSummary(org, repo string, number int, summary []byte) error
+}
+
+// SummarySinkFunc adapts a function to a SummarySink.
+type SummarySinkFunc func(org, repo string, number int, summary []byte) error
+
+func (f SummarySinkFunc) PutSummary(org, repo string, number int, summary []byte) error {
+	return f(org, repo, number, summary)
+}
+
+// summarySink is the sink of the summaries of the PRs that the plugin handles.
+// If it is nil, the summaries are logged.
+var summarySink SummarySink
+
+// SetSummarySink sets the sink that receives the summaries of the PRs that the
+// plugin handles. It must be called before the plugin handles any event. A nil
+// sink restores logging the summaries in the 'size_summary' field.
+func SetSummarySink(sink SummarySink) {
+	summarySink = sink
+}
+
+// putSummary emits the summary to the sink, or logs it if the sink is nil.
+func putSummary(sink SummarySink, le *logrus.Entry, org, repo string, number int, summary []byte) {
+	if sink == nil {
+		le.WithField("size_summary", string(summary)).Info("Computed the size of the PR.")
+		return
+	}
+	if err := sink.PutSummary(org, repo, number, summary); err != nil {
+		le.WithError(err).Warn("error while emitting the summary of the size computation")
+	}
+}
+
+// fileCount describes how a changed file of a PR was counted.
+type fileCount struct {
+	Filename string `json:"filename"`
+	// Changes is the number of lines added and deleted in the file.
+	Changes int `json:"changes"`
+	// Lines is the number of changed lines that were counted, which is zero
+	// for skipped files.
+	Lines   int        `json:"lines"`
+	Skipped skipReason `json:"skipped,omitempty"`
+	Capped  bool       `json:"capped,omitempty"`
+}
+
+// sizeSummary is the JSON summary of the size computation of a PR, which data
+// pipelines can ingest to analyze size decisions.
+type sizeSummary struct {
+	Org    string      `json:"org"`
+	Repo   string      `json:"repo"`
+	Number int         `json:"number"`
+	SHA    string      `json:"sha"`
+	Files  []fileCount `json:"files"`
+	// Skipped is the number of skipped files by reason.
+	Skipped   map[skipReason]int `json:"skipped"`
+	Lines     int                `json:"lines"`
+	Capped    int                `json:"capped"`
+	ForcedXXL []string           `json:"forced_xxl,omitempty"`
+	Bump      int                `json:"bump,omitempty"`
+	Untested  bool               `json:"untested,omitempty"`
+	Median    int                `json:"median,omitempty"`
+	Label     string             `json:"label"`
+}
+
+// summary returns the JSON summary of the count of the PR at the given head SHA.
+func (c changeCount) summary(org, repo string, number int, sha string, sizes plugins.Size) ([]byte, error) {
+	s := sizeSummary{
+		Org:       org,
+		Repo:      repo,
+		Number:    number,
+		SHA:       sha,
+		Files:     c.files,
+		Skipped:   c.skipped,
+		Lines:     c.lines,
+		Capped:    c.capped,
+		ForcedXXL: c.forcedXXL,
+		Bump:      c.bump,
+		Untested:  c.untested,
+		Label:     c.label(sizes),
+	}
+	if s.Files == nil {
+		s.Files = []fileCount{}
+	}
+	if c.relative {
+		s.Median = c.median
+	}
+	return json.Marshal(s)
+}
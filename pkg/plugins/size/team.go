@@ -0,0 +1,100 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package size
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/prow/pkg/github"
+	"sigs.k8s.io/prow/pkg/plugins"
+)
+
+// teamLabelPrefix prefixes the labels sizing the changes of a PR within the
+// paths owned by the teams of its author. It must not start with labelPrefix,
+// so that the size label and the team label do not replace each other.
+const teamLabelPrefix = "team-size/"
+
+// ownedGlobs returns the globs of the paths owned by the teams of the TeamPaths
+// that the author is a member of.
+func ownedGlobs(gc githubClient, org, author string, teamPaths map[string][]string) ([]string, error) {
+	teams := make([]string, 0, len(teamPaths))
+	for team := range teamPaths {
+		teams = append(teams, team)
+	}
+	sort.Strings(teams)
+	var globs []string
+	for _, team := range teams {
+		member, err := gc.TeamBySlugHasMember(org, team, author)
+		if err != nil {
+			return nil, fmt.Errorf("error checking membership of %s in team %s: %w", author, team, err)
+		}
+		if member {
+			globs = append(globs, teamPaths[team]...)
+		}
+	}
+	return globs, nil
+}
+
+// ownedLines returns the number of counted lines of the changed files that
+// match any of globs.
+func ownedLines(files []fileCount, globs []string, le *logrus.Entry) int {
+	var lines int
+	for _, file := range files {
+		if file.Skipped == "" && matchesAny(file.Filename, globs, le) {
+			lines += file.Lines
+		}
+	}
+	return lines
+}
+
+// updateTeamLabel applies the label sizing the changes of the PR within the
+// paths owned by the teams of its author, and removes any other team label.
+// If the author owns no paths, all team labels are removed.
+func updateTeamLabel(gc githubClient, sizes plugins.Size, le *logrus.Entry, owner, repo string, num int, author string, labels []github.Label, files []fileCount) {
+	globs, err := ownedGlobs(gc, owner, author, sizes.TeamPaths)
+	if err != nil {
+		le.WithError(err).Warn("error while determining the paths owned by the author")
+		return
+	}
+	var newLabel string
+	if len(globs) > 0 {
+		lines := ownedLines(files, globs, le)
+		newLabel = teamLabelPrefix + strings.TrimPrefix(bucket(lines, sizes).label(), labelPrefix)
+	}
+
+	var hasLabel bool
+	for _, label := range labels {
+		if label.Name == newLabel {
+			hasLabel = true
+			continue
+		}
+		if strings.HasPrefix(label.Name, teamLabelPrefix) {
+			if err := gc.RemoveLabel(owner, repo, num, label.Name); err != nil {
+				le.Warnf("error while removing label %q: %v", label.Name, err)
+			}
+		}
+	}
+	if newLabel != "" && !hasLabel {
+		if err := gc.AddLabel(owner, repo, num, newLabel); err != nil {
+			le.Warnf("error while adding label %q: %v", newLabel, err)
+		}
+	}
+}
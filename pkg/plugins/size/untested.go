@@ -0,0 +1,60 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package size
+
+import (
+	"path"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/prow/pkg/plugins"
+)
+
+// docExtensions are the extensions of documentation files, which are not
+// source files unless SourceFilePatterns say otherwise.
+var docExtensions = map[string]bool{
+	".md":       true,
+	".markdown": true,
+	".rst":      true,
+	".txt":      true,
+	".adoc":     true,
+}
+
+// isUntested returns true if the PR changes source files but no test files
+// according to the TestFilePatterns and SourceFilePatterns. Only counted files
+// are source files, while any changed file may be a test file.
+func isUntested(files []fileCount, sizes plugins.Size, le *logrus.Entry) bool {
+	if len(sizes.TestFilePatterns) == 0 {
+		return false
+	}
+	changesSource := false
+	for _, file := range files {
+		if matchesAny(file.Filename, sizes.TestFilePatterns, le) {
+			return false
+		}
+		if file.Skipped != "" {
+			continue
+		}
+		if len(sizes.SourceFilePatterns) > 0 {
+			changesSource = changesSource || matchesAny(file.Filename, sizes.SourceFilePatterns, le)
+		} else {
+			changesSource = changesSource || !docExtensions[strings.ToLower(path.Ext(file.Filename))]
+		}
+	}
+	return changesSource
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spyglass
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/spyglass/api"
+	"sigs.k8s.io/prow/pkg/spyglass/lenses/common"
+)
+
+// BuildLogArtifactFetcher fetches the logs of a ProwJob from the source that
+// has the most accurate copy: the log uploaded to storage by the sidecar once
+// the job has completed, or else the live pod log.
+type BuildLogArtifactFetcher struct {
+	podLogs *PodLogArtifactFetcher
+	storage common.ArtifactFetcher
+	config  config.Getter
+}
+
+// NewBuildLogArtifactFetcher returns a BuildLogArtifactFetcher that reads
+// pod logs from the given pod log fetcher and uploaded logs from the given
+// storage fetcher.
+func NewBuildLogArtifactFetcher(podLogs *PodLogArtifactFetcher, storage common.ArtifactFetcher, cfg config.Getter) *BuildLogArtifactFetcher {
+	return &BuildLogArtifactFetcher{
+		podLogs: podLogs,
+		storage: storage,
+		config:  cfg,
+	}
+}
+
+// Artifact returns the uploaded log for the job build with the given prow key
+// if the job has completed and the log was uploaded, or else the pod log.
+func (af *BuildLogArtifactFetcher) Artifact(ctx context.Context, key, artifactName string, sizeLimit int64) (api.Artifact, error) {
+	jobName, buildID, err := common.KeyToJob(key)
+	if err != nil {
+		return nil, fmt.Errorf("could not derive job: %w", err)
+	}
+	job, err := af.podLogs.GetProwJob(jobName, buildID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get prow job from key %q: %w", key, err)
+	}
+	if job.Complete() {
+		art, err := af.uploadedArtifact(ctx, key, artifactName, sizeLimit)
+		if err == nil {
+			return art, nil
+		}
+		logrus.WithError(err).WithField("artifact", artifactName).Debug("Failed to fetch uploaded log, falling back to pod log")
+	}
+	return af.podLogs.Artifact(ctx, key, artifactName, sizeLimit)
+}
+
+// uploadedArtifact returns the artifact uploaded to storage for the job build
+// with the given prow key.
+func (af *BuildLogArtifactFetcher) uploadedArtifact(ctx context.Context, key, artifactName string, sizeLimit int64) (api.Artifact, error) {
+	storageProvider, storagePath, err := common.ProwToGCS(af.podLogs, af.config, key)
+	if err != nil {
+		return nil, err
+	}
+	storageKey := fmt.Sprintf("%s://%s", storageProvider, strings.TrimSuffix(storagePath, "/"))
+	art, err := af.storage.Artifact(ctx, storageKey, artifactName, sizeLimit)
+	if err != nil {
+		return nil, err
+	}
+	// Getting the artifact does no I/O, so make sure that it was uploaded.
+	if _, err := art.Size(); err != nil {
+		return nil, err
+	}
+	return art, nil
+}
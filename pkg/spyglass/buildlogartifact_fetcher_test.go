@@ -0,0 +1,120 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spyglass
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/config"
+	"sigs.k8s.io/prow/pkg/spyglass/api"
+)
+
+// fakeCompletableJAgent serves a live pod log for a job that may have completed.
+type fakeCompletableJAgent struct {
+	complete bool
+}
+
+func (j *fakeCompletableJAgent) GetProwJob(job, id string) (prowapi.ProwJob, error) {
+	pj := prowapi.ProwJob{
+		Status: prowapi.ProwJobStatus{
+			URL: fmt.Sprintf("https://prow.example.com/view/gs/bucket/logs/%s/%s", job, id),
+		},
+	}
+	if j.complete {
+		now := metav1.Now()
+		pj.Status.CompletionTime = &now
+	}
+	return pj, nil
+}
+
+func (j *fakeCompletableJAgent) GetJobLog(job, id, container string) ([]byte, error) {
+	return []byte("live log"), nil
+}
+
+// fakeUploadedArtifactFetcher serves artifacts uploaded to storage by key and name.
+type fakeUploadedArtifactFetcher struct {
+	uploaded map[string]string
+}
+
+func (f *fakeUploadedArtifactFetcher) Artifact(ctx context.Context, key, artifactName string, sizeLimit int64) (api.Artifact, error) {
+	path := fmt.Sprintf("%s/%s", key, artifactName)
+	contents, ok := f.uploaded[path]
+	if !ok {
+		return nil, fmt.Errorf("%s was not uploaded", path)
+	}
+	return NewStorageArtifact(ctx, &fakeArtifactHandle{contents: []byte(contents)}, "", artifactName, sizeLimit), nil
+}
+
+func TestBuildLogArtifactFetcher(t *testing.T) {
+	cfg := fca{
+		c: config.Config{
+			ProwConfig: config.ProwConfig{
+				Plank: config.Plank{
+					JobURLPrefixConfig: map[string]string{"*": "https://prow.example.com/view/"},
+				},
+			},
+		},
+	}
+	testCases := []struct {
+		name     string
+		complete bool
+		uploaded map[string]string
+		expected string
+	}{
+		{
+			name:     "running job serves pod log",
+			uploaded: map[string]string{"gs://bucket/logs/job/123/build-log.txt": "uploaded log"},
+			expected: "live log",
+		},
+		{
+			name:     "completed job serves uploaded log",
+			complete: true,
+			uploaded: map[string]string{"gs://bucket/logs/job/123/build-log.txt": "uploaded log"},
+			expected: "uploaded log",
+		},
+		{
+			name:     "completed job without uploaded log serves pod log",
+			complete: true,
+			expected: "live log",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			af := NewBuildLogArtifactFetcher(
+				NewPodLogArtifactFetcher(&fakeCompletableJAgent{complete: tc.complete}),
+				&fakeUploadedArtifactFetcher{uploaded: tc.uploaded},
+				cfg.Config,
+			)
+			art, err := af.Artifact(context.Background(), "job/123", singleLogName, 500e6)
+			if err != nil {
+				t.Fatalf("unexpected error getting artifact: %v", err)
+			}
+			content, err := art.ReadAll()
+			if err != nil {
+				t.Fatalf("unexpected error reading artifact: %v", err)
+			}
+			if string(content) != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, string(content))
+			}
+		})
+	}
+}
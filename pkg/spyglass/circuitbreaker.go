@@ -0,0 +1,121 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spyglass
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/utils/clock"
+)
+
+// BackendUnavailableError is returned for fetches of pod logs that are not
+// attempted because the circuit breaker of the fetcher is open.
+type BackendUnavailableError struct {
+	// Failures is the number of consecutive failures that opened the breaker.
+	Failures int
+	// RetryAfter is the time after which the backend is probed again.
+	RetryAfter time.Time
+}
+
+func (e *BackendUnavailableError) Error() string {
+	return fmt.Sprintf("pod log backend unavailable after %d consecutive failures, retry after %s", e.Failures, e.RetryAfter.UTC().Format(time.RFC3339))
+}
+
+// circuitBreaker stops calls to a failing backend. After threshold
+// consecutive failures the breaker opens and rejects all calls for the
+// cooldown. Then it is half-open and lets a single call through to probe
+// whether the backend recovered, which closes the breaker if it succeeds and
+// opens it for another cooldown otherwise.
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	clock     clock.PassiveClock
+
+	lock     sync.Mutex
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// allow returns an error if a call to the backend must not be made. A nil
+// breaker allows all calls. Every allowed call must be followed by record.
+func (b *circuitBreaker) allow() error {
+	if b == nil {
+		return nil
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.failures < b.threshold {
+		return nil
+	}
+	retryAfter := b.openedAt.Add(b.cooldown)
+	if b.probing || b.clock.Now().Before(retryAfter) {
+		return &BackendUnavailableError{Failures: b.failures, RetryAfter: retryAfter}
+	}
+	b.probing = true
+	return nil
+}
+
+// record records the result of a call to the backend. Only errors that
+// indicate the backend is unavailable count as failures. Other errors, like a
+// pod or ProwJob that no longer exists, were answered by a healthy backend and
+// count as successes.
+func (b *circuitBreaker) record(err error) {
+	if b == nil {
+		return
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.probing = false
+	if !isBackendFailure(err) {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= b.threshold {
+		b.openedAt = b.clock.Now()
+	}
+}
+
+// isBackendFailure returns whether err indicates that the backend is
+// unavailable: a transport error, a timeout or a server error.
+func isBackendFailure(err error) bool {
+	if err == nil || isNotFound(err) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	if apierrors.IsServerTimeout(err) || apierrors.IsTimeout(err) || apierrors.IsTooManyRequests(err) {
+		return true
+	}
+	var status apierrors.APIStatus
+	if errors.As(err, &status) {
+		return status.Status().Code >= 500
+	}
+	return false
+}
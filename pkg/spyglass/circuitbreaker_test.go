@@ -0,0 +1,176 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spyglass
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"sigs.k8s.io/prow/pkg/deck/jobs"
+)
+
+// fakeFailingJAgent fails to get pod logs until it recovers. It fails with err,
+// or with an unavailable apiserver if err is unset.
+type fakeFailingJAgent struct {
+	fakePodLogJAgent
+	failing bool
+	err     error
+	calls   int
+}
+
+func (j *fakeFailingJAgent) GetJobLog(job, id, container string) ([]byte, error) {
+	j.calls++
+	if j.failing {
+		if j.err != nil {
+			return nil, j.err
+		}
+		return nil, apierrors.NewServiceUnavailable("apiserver unavailable")
+	}
+	return []byte("frobscottle"), nil
+}
+
+func TestPodLogArtifactFetcherCircuitBreaker(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clk := clocktesting.NewFakePassiveClock(start)
+	agent := &fakeFailingJAgent{failing: true}
+	fetcher := NewPodLogArtifactFetcher(agent, WithCircuitBreaker(2, time.Minute))
+	fetcher.opts.breaker.clock = clk
+
+	steps := []struct {
+		name            string
+		elapsed         time.Duration
+		recovered       bool
+		expectedErr     bool
+		expectedBreaker bool
+		expectedCalls   int
+	}{
+		{
+			name:          "first failure is returned",
+			expectedErr:   true,
+			expectedCalls: 1,
+		},
+		{
+			name:          "failure reaching the threshold is returned",
+			expectedErr:   true,
+			expectedCalls: 2,
+		},
+		{
+			name:            "open breaker short-circuits fetches",
+			expectedErr:     true,
+			expectedBreaker: true,
+			expectedCalls:   2,
+		},
+		{
+			name:            "breaker stays open during the cooldown",
+			elapsed:         30 * time.Second,
+			expectedErr:     true,
+			expectedBreaker: true,
+			expectedCalls:   2,
+		},
+		{
+			name:          "failed probe after the cooldown is returned",
+			elapsed:       time.Minute,
+			expectedErr:   true,
+			expectedCalls: 3,
+		},
+		{
+			name:            "failed probe reopens the breaker",
+			elapsed:         time.Minute + 30*time.Second,
+			expectedErr:     true,
+			expectedBreaker: true,
+			expectedCalls:   3,
+		},
+		{
+			name:          "successful probe after the cooldown closes the breaker",
+			elapsed:       2 * time.Minute,
+			recovered:     true,
+			expectedCalls: 4,
+		},
+		{
+			name:          "closed breaker allows fetches",
+			elapsed:       2 * time.Minute,
+			recovered:     true,
+			expectedCalls: 5,
+		},
+	}
+	for _, step := range steps {
+		clk.SetTime(start.Add(step.elapsed))
+		agent.failing = !step.recovered
+		art, err := fetcher.Artifact(context.Background(), "BFG/435", singleLogName, 500e6)
+		if err != nil {
+			t.Fatalf("%s: unexpected error getting artifact: %v", step.name, err)
+		}
+		_, err = art.ReadAtMost(5)
+		if (err != nil) != step.expectedErr {
+			t.Errorf("%s: expected error: %t, got: %v", step.name, step.expectedErr, err)
+		}
+		var unavailable *BackendUnavailableError
+		if errors.As(err, &unavailable) != step.expectedBreaker {
+			t.Errorf("%s: expected short-circuited fetch: %t, got: %v", step.name, step.expectedBreaker, err)
+		}
+		if agent.calls != step.expectedCalls {
+			t.Errorf("%s: expected %d calls to the job agent, got %d", step.name, step.expectedCalls, agent.calls)
+		}
+	}
+}
+
+func TestPodLogArtifactFetcherCircuitBreakerIgnoresNotFound(t *testing.T) {
+	_, prowJobNotFound := (&jobs.JobAgent{}).GetProwJob("BFG", "435")
+	testCases := []struct {
+		name string
+		err  error
+	}{
+		{
+			name: "missing pod",
+			err:  apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, "frobscottle"),
+		},
+		{
+			name: "missing ProwJob",
+			err:  fmt.Errorf("error getting prowjob: %w", prowJobNotFound),
+		},
+		{
+			name: "other error answered by the backend",
+			err:  errors.New("cannot get logs for prowjob: unknown cluster alias"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			agent := &fakeFailingJAgent{failing: true, err: tc.err}
+			fetcher := NewPodLogArtifactFetcher(agent, WithCircuitBreaker(2, time.Minute))
+			for i := 1; i <= 3; i++ {
+				art, err := fetcher.Artifact(context.Background(), "BFG/435", singleLogName, 500e6)
+				if err != nil {
+					t.Fatalf("unexpected error getting artifact: %v", err)
+				}
+				_, err = art.ReadAtMost(5)
+				if !errors.Is(err, tc.err) {
+					t.Errorf("fetch %d: expected error %v, got: %v", i, tc.err, err)
+				}
+				if agent.calls != i {
+					t.Errorf("fetch %d: expected %d calls to the job agent, got %d", i, i, agent.calls)
+				}
+			}
+		})
+	}
+}
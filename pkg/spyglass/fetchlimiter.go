@@ -0,0 +1,74 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spyglass
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// fetchLimiter bounds the number of fetches of pod logs from the job agent
+// that are in flight at a time, so that heavy traffic does not overload the
+// apiserver.
+type fetchLimiter struct {
+	slots chan struct{}
+}
+
+func newFetchLimiter(limit int) *fetchLimiter {
+	return &fetchLimiter{slots: make(chan struct{}, limit)}
+}
+
+// acquire blocks until a slot is free and takes it, or returns the error of
+// the context if it is done first. A nil limiter never blocks. Every
+// successful acquire must be followed by release.
+func (l *fetchLimiter) acquire(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// release frees a slot taken by acquire.
+func (l *fetchLimiter) release() {
+	if l == nil {
+		return
+	}
+	<-l.slots
+}
+
+// releasingReadCloser holds a slot of the limiter while a pod log is streamed
+// and releases it once the stream is closed.
+type releasingReadCloser struct {
+	io.ReadCloser
+	limiter *fetchLimiter
+	once    sync.Once
+}
+
+func (r *releasingReadCloser) Close() error {
+	err := r.ReadCloser.Close()
+	r.once.Do(r.limiter.release)
+	return err
+}
@@ -0,0 +1,194 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spyglass
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeBlockingJAgent blocks fetches of pod logs until unblocked, keeping track
+// of the fetches in flight.
+type fakeBlockingJAgent struct {
+	fakePodLogJAgent
+	unblock chan struct{}
+
+	lock        sync.Mutex
+	inFlight    int
+	maxInFlight int
+	calls       int
+}
+
+func (j *fakeBlockingJAgent) GetJobLog(job, id, container string) ([]byte, error) {
+	j.lock.Lock()
+	j.calls++
+	j.inFlight++
+	if j.inFlight > j.maxInFlight {
+		j.maxInFlight = j.inFlight
+	}
+	j.lock.Unlock()
+	<-j.unblock
+	j.lock.Lock()
+	j.inFlight--
+	j.lock.Unlock()
+	return []byte("frobscottle"), nil
+}
+
+func (j *fakeBlockingJAgent) counts() (inFlight, maxInFlight, calls int) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	return j.inFlight, j.maxInFlight, j.calls
+}
+
+// waitForInFlight waits until the given number of fetches is in flight.
+func waitForInFlight(t *testing.T, agent *fakeBlockingJAgent, n int) {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		if inFlight, _, _ := agent.counts(); inFlight == n {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %d fetches in flight", n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestPodLogArtifactFetcherMaxConcurrentFetches(t *testing.T) {
+	agent := &fakeBlockingJAgent{unblock: make(chan struct{})}
+	fetcher := NewPodLogArtifactFetcher(agent, WithMaxConcurrentFetches(2))
+
+	const fetches = 5
+	errs := make(chan error, fetches)
+	for i := 0; i < fetches; i++ {
+		go func() {
+			art, err := fetcher.Artifact(context.Background(), "BFG/435", singleLogName, 500e6)
+			if err != nil {
+				errs <- err
+				return
+			}
+			_, err = art.ReadAtMost(5)
+			errs <- err
+		}()
+	}
+	waitForInFlight(t, agent, 2)
+	// Give the waiting fetches the chance to exceed the limit.
+	time.Sleep(50 * time.Millisecond)
+	if inFlight, _, _ := agent.counts(); inFlight != 2 {
+		t.Errorf("expected 2 fetches in flight, got %d", inFlight)
+	}
+	close(agent.unblock)
+	for i := 0; i < fetches; i++ {
+		if err := <-errs; err != nil {
+			t.Errorf("unexpected error reading artifact: %v", err)
+		}
+	}
+	if _, maxInFlight, calls := agent.counts(); maxInFlight != 2 || calls != fetches {
+		t.Errorf("expected %d calls with at most 2 in flight, got %d calls with at most %d in flight", fetches, calls, maxInFlight)
+	}
+}
+
+func TestPodLogArtifactFetcherMaxConcurrentFetchesCanceled(t *testing.T) {
+	agent := &fakeBlockingJAgent{unblock: make(chan struct{})}
+	defer close(agent.unblock)
+	fetcher := NewPodLogArtifactFetcher(agent, WithMaxConcurrentFetches(1))
+
+	go func() {
+		art, err := fetcher.Artifact(context.Background(), "BFG/435", singleLogName, 500e6)
+		if err == nil {
+			_, _ = art.ReadAtMost(5)
+		}
+	}()
+	waitForInFlight(t, agent, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	art, err := fetcher.Artifact(ctx, "BFG/435", singleLogName, 500e6)
+	if err != nil {
+		t.Fatalf("unexpected error getting artifact: %v", err)
+	}
+	errs := make(chan error, 1)
+	go func() {
+		_, err := art.ReadAtMost(5)
+		errs <- err
+	}()
+	cancel()
+	select {
+	case err := <-errs:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected the canceled wait to fail with %v, got: %v", context.Canceled, err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("canceled wait for a free slot did not return")
+	}
+	if _, _, calls := agent.counts(); calls != 1 {
+		t.Errorf("expected the canceled fetch not to reach the job agent, got %d calls", calls)
+	}
+}
+
+// fakeBlockingSizingJAgent blocks fetches of pod logs and reports their size.
+type fakeBlockingSizingJAgent struct {
+	*fakeBlockingJAgent
+	sizeCalls int
+}
+
+func (j *fakeBlockingSizingJAgent) GetJobLogSize(job, id, container string) (int64, error) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	j.sizeCalls++
+	return int64(len("frobscottle")), nil
+}
+
+func TestPodLogArtifactFetcherMaxConcurrentFetchesSize(t *testing.T) {
+	agent := &fakeBlockingSizingJAgent{fakeBlockingJAgent: &fakeBlockingJAgent{unblock: make(chan struct{})}}
+	fetcher := NewPodLogArtifactFetcher(agent, WithMaxConcurrentFetches(1))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		art, err := fetcher.Artifact(context.Background(), "BFG/435", singleLogName, 500e6)
+		if err == nil {
+			_, _ = art.ReadAtMost(5)
+		}
+	}()
+	waitForInFlight(t, agent.fakeBlockingJAgent, 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := fetcher.Size(ctx, "BFG/435", singleLogName); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the size lookup to wait for a free slot and fail with %v, got: %v", context.DeadlineExceeded, err)
+	}
+	agent.lock.Lock()
+	sizeCalls := agent.sizeCalls
+	agent.lock.Unlock()
+	if sizeCalls != 0 {
+		t.Errorf("expected the size lookup not to reach the job agent, got %d calls", sizeCalls)
+	}
+
+	close(agent.unblock)
+	<-done
+	size, err := fetcher.Size(context.Background(), "BFG/435", singleLogName)
+	if err != nil {
+		t.Fatalf("failed to get size: %v", err)
+	}
+	if size != int64(len("frobscottle")) {
+		t.Errorf("expected size %d, got %d", len("frobscottle"), size)
+	}
+}
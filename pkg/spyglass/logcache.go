@@ -0,0 +1,112 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spyglass
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/golang-lru/simplelru"
+	"k8s.io/utils/clock"
+)
+
+// logCacheKey identifies a pod log.
+type logCacheKey struct {
+	job       string
+	buildID   string
+	container string
+	previous  bool
+	stream    LogStream
+}
+
+type logCacheEntry struct {
+	log     []byte
+	expires time.Time
+}
+
+// logCache holds the pod logs of completed jobs, which never change, so that
+// they are not fetched from the job agent again for the ttl. Logs of running
+// jobs are never cached. The total size of the cached logs is bounded by
+// maxBytes, beyond which the least recently used logs are dropped. The cached
+// logs must not be modified.
+type logCache struct {
+	ttl      time.Duration
+	maxBytes int64
+	clock    clock.PassiveClock
+
+	lock    sync.Mutex
+	entries *simplelru.LRU
+	bytes   int64
+}
+
+func newLogCache(ttl time.Duration, maxBytes int64) *logCache {
+	c := &logCache{
+		ttl:      ttl,
+		maxBytes: maxBytes,
+		clock:    clock.RealClock{},
+	}
+	// The number of entries is only bounded by their total size. NewLRU only
+	// fails for non-positive sizes.
+	c.entries, _ = simplelru.NewLRU(math.MaxInt32, func(_, value interface{}) {
+		c.bytes -= int64(len(value.(logCacheEntry).log))
+	})
+	return c
+}
+
+// get returns the cached log, if it has not expired. A nil cache is empty.
+func (c *logCache) get(key logCacheKey) ([]byte, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	value, ok := c.entries.Get(key)
+	if !ok {
+		return nil, false
+	}
+	entry := value.(logCacheEntry)
+	if !c.clock.Now().Before(entry.expires) {
+		c.entries.Remove(key)
+		return nil, false
+	}
+	return entry.log, true
+}
+
+// put caches the log for the ttl, drops all expired logs and then the least
+// recently used logs until the cache fits into maxBytes. Logs larger than
+// maxBytes are not cached.
+func (c *logCache) put(key logCacheKey, log []byte) {
+	if c == nil || int64(len(log)) > c.maxBytes {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	now := c.clock.Now()
+	for _, k := range c.entries.Keys() {
+		if value, ok := c.entries.Peek(k); ok && !now.Before(value.(logCacheEntry).expires) {
+			c.entries.Remove(k)
+		}
+	}
+	// Replace any previous entry through Remove, which accounts for its size.
+	c.entries.Remove(key)
+	c.entries.Add(key, logCacheEntry{log: log, expires: now.Add(c.ttl)})
+	c.bytes += int64(len(log))
+	for c.bytes > c.maxBytes {
+		c.entries.RemoveOldest()
+	}
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2024 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spyglass
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+)
+
+// fakeCompletingJAgent serves the pod log of a job that completes at some
+// point, until which its log keeps growing.
+type fakeCompletingJAgent struct {
+	fakePodLogJAgent
+	complete bool
+	calls    int
+}
+
+func (j *fakeCompletingJAgent) GetProwJob(job, id string) (prowapi.ProwJob, error) {
+	pj := prowapi.ProwJob{Status: prowapi.ProwJobStatus{State: prowapi.PendingState}}
+	if j.complete {
+		pj.Status.State = prowapi.SuccessState
+		pj.Status.CompletionTime = &metav1.Time{}
+	}
+	return pj, nil
+}
+
+func (j *fakeCompletingJAgent) GetJobLog(job, id, container string) ([]byte, error) {
+	j.calls++
+	return []byte(fmt.Sprintf("line %d", j.calls)), nil
+}
+
+func TestPodLogArtifactFetcherCompletedLogCache(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clk := clocktesting.NewFakePassiveClock(start)
+	agent := &fakeCompletingJAgent{}
+	fetcher := NewPodLogArtifactFetcher(agent, WithCompletedLogCache(time.Hour, 1<<20))
+	fetcher.opts.cache.clock = clk
+
+	steps := []struct {
+		name          string
+		elapsed       time.Duration
+		complete      bool
+		expectedLog   string
+		expectedCalls int
+	}{
+		{
+			name:          "log of a running job is fetched",
+			expectedLog:   "line 1",
+			expectedCalls: 1,
+		},
+		{
+			name:          "log of a running job is not cached",
+			expectedLog:   "line 2",
+			expectedCalls: 2,
+		},
+		{
+			name:          "log of a job that completed is fetched again",
+			complete:      true,
+			expectedLog:   "line 3",
+			expectedCalls: 3,
+		},
+		{
+			name:          "log of a completed job is cached",
+			elapsed:       30 * time.Minute,
+			complete:      true,
+			expectedLog:   "line 3",
+			expectedCalls: 3,
+		},
+		{
+			name:          "expired log of a completed job is fetched again",
+			elapsed:       time.Hour,
+			complete:      true,
+			expectedLog:   "line 4",
+			expectedCalls: 4,
+		},
+		{
+			name:          "refetched log of a completed job is cached",
+			elapsed:       90 * time.Minute,
+			complete:      true,
+			expectedLog:   "line 4",
+			expectedCalls: 4,
+		},
+	}
+	for _, step := range steps {
+		clk.SetTime(start.Add(step.elapsed))
+		agent.complete = step.complete
+		art, err := fetcher.Artifact(context.Background(), "BFG/435", singleLogName, 500e6)
+		if err != nil {
+			t.Fatalf("%s: unexpected error getting artifact: %v", step.name, err)
+		}
+		// The log is shorter than 100 bytes, so it is read up to io.EOF.
+		log, err := art.ReadAtMost(100)
+		if err != nil && err != io.EOF {
+			t.Fatalf("%s: unexpected error reading artifact: %v", step.name, err)
+		}
+		if string(log) != step.expectedLog {
+			t.Errorf("%s: expected log %q, got %q", step.name, step.expectedLog, string(log))
+		}
+		if agent.calls != step.expectedCalls {
+			t.Errorf("%s: expected %d calls to the job agent, got %d", step.name, step.expectedCalls, agent.calls)
+		}
+	}
+}
+
+func TestLogCacheMaxBytes(t *testing.T) {
+	cache := newLogCache(time.Hour, 10)
+	first := logCacheKey{job: "job", buildID: "1"}
+	second := logCacheKey{job: "job", buildID: "2"}
+	third := logCacheKey{job: "job", buildID: "3"}
+
+	cache.put(first, []byte("0123"))
+	cache.put(second, []byte("0123"))
+	// Using the first log makes the second one the least recently used.
+	if _, ok := cache.get(first); !ok {
+		t.Fatal("expected the first log to be cached")
+	}
+	cache.put(third, []byte("0123"))
+	if _, ok := cache.get(second); ok {
+		t.Error("expected the least recently used log to be dropped")
+	}
+	for _, key := range []logCacheKey{first, third} {
+		if _, ok := cache.get(key); !ok {
+			t.Errorf("expected the log of build %s to be cached", key.buildID)
+		}
+	}
+	if cache.bytes != 8 {
+		t.Errorf("expected 8 cached bytes, got %d", cache.bytes)
+	}
+
+	cache.put(second, []byte("0123456789a"))
+	if _, ok := cache.get(second); ok {
+		t.Error("expected a log larger than the cache not to be cached")
+	}
+	if cache.bytes != 8 {
+		t.Errorf("expected 8 cached bytes, got %d", cache.bytes)
+	}
+}
+
+func TestPodLogArtifactFetcherStatus(t *testing.T) {
+	testCases := []struct {
+		name          string
+		key           string
+		complete      bool
+		expectedState prowapi.ProwJobState
+		expectErr     bool
+	}{
+		{
+			name:          "running job",
+			key:           "BFG/435",
+			expectedState: prowapi.PendingState,
+		},
+		{
+			name:          "completed job",
+			key:           "BFG/435",
+			complete:      true,
+			expectedState: prowapi.SuccessState,
+		},
+		{
+			name:      "invalid key",
+			key:       "BFG",
+			expectErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fetcher := NewPodLogArtifactFetcher(&fakeCompletingJAgent{complete: tc.complete})
+			state, err := fetcher.Status(context.Background(), tc.key)
+			if (err != nil) != tc.expectErr {
+				t.Fatalf("expected error: %t, got: %v", tc.expectErr, err)
+			}
+			if state != tc.expectedState {
+				t.Errorf("expected state %q, got %q", tc.expectedState, state)
+			}
+		})
+	}
+}
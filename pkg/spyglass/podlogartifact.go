@@ -17,13 +17,20 @@ limitations under the License.
 package spyglass
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io"
 	"net/url"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
 
 	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/deck/jobs"
 	"sigs.k8s.io/prow/pkg/spyglass/lenses"
 )
 
@@ -32,6 +39,56 @@ type jobAgent interface {
 	GetJobLog(job string, id string, container string) ([]byte, error)
 }
 
+// previousJobLogGetter is implemented by job agents that can return the log of
+// the previous instance of a container, e.g. after the container restarted.
+type previousJobLogGetter interface {
+	GetPreviousJobLog(job string, id string, container string) ([]byte, error)
+}
+
+// jobLogStreamer is implemented by job agents that can stream a pod log
+// instead of returning it in a single buffer.
+type jobLogStreamer interface {
+	GetJobLogStream(ctx context.Context, job string, id string, container string) (io.ReadCloser, error)
+}
+
+// The job agent of deck streams pod logs and gets the logs of previous
+// containers. It cannot split the output streams of containers or report the
+// size of pod logs, as the kubernetes API does not support either.
+var (
+	_ jobLogStreamer       = &jobs.JobAgent{}
+	_ previousJobLogGetter = &jobs.JobAgent{}
+)
+
+// jobLogSizer is implemented by job agents that can report the size of a pod
+// log without returning its content.
+type jobLogSizer interface {
+	GetJobLogSize(job string, id string, container string) (int64, error)
+}
+
+// jobLogRangeGetter is implemented by job agents that can return a range of
+// bytes of a pod log without returning the rest of it.
+type jobLogRangeGetter interface {
+	GetJobLogRange(job string, id string, container string, start, end int64) ([]byte, error)
+}
+
+// LogStream selects the output streams of a container that its log contains.
+type LogStream string
+
+const (
+	// LogStreamAll selects the combined output of a container.
+	LogStreamAll LogStream = ""
+	// LogStreamStdout selects the standard output of a container.
+	LogStreamStdout LogStream = "stdout"
+	// LogStreamStderr selects the standard error of a container.
+	LogStreamStderr LogStream = "stderr"
+)
+
+// splitStreamJobLogGetter is implemented by job agents that can return the log
+// of a single output stream of a container.
+type splitStreamJobLogGetter interface {
+	GetJobLogOfStream(job string, id string, container string, stream LogStream) ([]byte, error)
+}
+
 // PodLogArtifact holds data for reading from a specific pod log
 type PodLogArtifact struct {
 	name         string
@@ -39,9 +96,36 @@ type PodLogArtifact struct {
 	artifactName string
 	container    string
 	sizeLimit    int64
+	opts         podLogOptions
+	// previous is true if this is the log of the previous instance of the container.
+	previous bool
+	// stream selects a single output stream of the container, if the job agent
+	// is able to separate them.
+	stream LogStream
+	// rawLog is the pod log if it was already fetched from the job agent, so
+	// that it is not fetched again.
+	rawLog []byte
+	// bytesRead is the number of bytes read from the job agent. It must be
+	// accessed atomically.
+	bytesRead int64
+	// ctx provides context for cancelling the wait for a slot of the limiter.
+	// Embedded in struct to preserve conformance with api.Artifact
+	ctx context.Context
 	jobAgent
 }
 
+// podLogBytesRead counts the bytes of pod logs read from the job agent.
+var podLogBytesRead = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "spyglass_pod_log_bytes_read_total",
+	Help: "Count of bytes of pod logs read from the job agent by container.",
+}, []string{
+	"container",
+})
+
+func init() {
+	prometheus.MustRegister(podLogBytesRead)
+}
+
 var (
 	errInsufficientJobInfo = errors.New("insufficient job information provided")
 	errInvalidSizeLimit    = errors.New("sizeLimit must be a 64-bit integer greater than 0")
@@ -67,6 +151,8 @@ func NewPodLogArtifact(jobName string, buildID string, artifactName string, cont
 		artifactName: artifactName,
 		container:    container,
 		sizeLimit:    sizeLimit,
+		opts:         podLogOptions{readBufferSize: defaultPodLogReadBufferSize},
+		ctx:          context.Background(),
 		jobAgent:     ja,
 	}, nil
 }
@@ -93,12 +179,281 @@ func (a *PodLogArtifact) JobPath() string {
 	return a.artifactName
 }
 
+// getLog returns the pod log from the job agent with the enabled
+// normalizations applied, preceded by the header line if enabled.
+func (a *PodLogArtifact) getLog() ([]byte, error) {
+	logs, err := a.getRawLog()
+	if err != nil {
+		return nil, err
+	}
+	logs = a.opts.normalize(logs)
+	if !a.opts.header {
+		return logs, nil
+	}
+	return append(a.header(), logs...), nil
+}
+
+// getRawLog returns the pod log from the job agent, or from the cache if the
+// job has completed.
+func (a *PodLogArtifact) getRawLog() ([]byte, error) {
+	if a.rawLog != nil {
+		return a.rawLog, nil
+	}
+	cached := a.cachesLog()
+	if cached {
+		if logs, ok := a.opts.cache.get(a.cacheKey()); ok {
+			return logs, nil
+		}
+	}
+	var fetch func(job, id, container string) ([]byte, error)
+	if a.previous {
+		getter, ok := a.jobAgent.(previousJobLogGetter)
+		if !ok {
+			return nil, errors.New("job agent cannot get the logs of previous containers")
+		}
+		fetch = getter.GetPreviousJobLog
+	} else if a.splitsStream() {
+		fetch = func(job, id, container string) ([]byte, error) {
+			return a.jobAgent.(splitStreamJobLogGetter).GetJobLogOfStream(job, id, container, a.stream)
+		}
+	} else {
+		fetch = a.jobAgent.GetJobLog
+	}
+	if err := a.opts.limiter.acquire(a.ctx); err != nil {
+		return nil, fmt.Errorf("error waiting to fetch pod log: %w", err)
+	}
+	if err := a.opts.breaker.allow(); err != nil {
+		a.opts.limiter.release()
+		return nil, err
+	}
+	logs, err := fetch(a.name, a.buildID, a.container)
+	a.opts.limiter.release()
+	a.opts.breaker.record(err)
+	a.recordBytesRead(len(logs))
+	if err == nil && cached {
+		a.opts.cache.put(a.cacheKey(), logs)
+	}
+	return logs, err
+}
+
+// rawSize returns the size of the pod log from the cache or the job agent
+// without fetching the log, or UnknownSize if neither can report it.
+func (a *PodLogArtifact) rawSize() (int64, error) {
+	if a.cachesLog() {
+		if logs, ok := a.opts.cache.get(a.cacheKey()); ok {
+			return int64(len(logs)), nil
+		}
+	}
+	sizer, ok := a.jobAgent.(jobLogSizer)
+	if !ok || a.previous || a.splitsStream() {
+		return UnknownSize, nil
+	}
+	if err := a.opts.limiter.acquire(a.ctx); err != nil {
+		return 0, fmt.Errorf("error waiting to get pod log size: %w", err)
+	}
+	if err := a.opts.breaker.allow(); err != nil {
+		a.opts.limiter.release()
+		return 0, err
+	}
+	size, err := sizer.GetJobLogSize(a.name, a.buildID, a.container)
+	a.opts.limiter.release()
+	a.opts.breaker.record(err)
+	if err != nil {
+		return 0, fmt.Errorf("error getting pod log size: %w", err)
+	}
+	return size, nil
+}
+
+// getRawLogRange returns the bytes from start up to end of the pod log from
+// the job agent, without fetching the rest of the log.
+func (a *PodLogArtifact) getRawLogRange(start, end int64) ([]byte, error) {
+	getter, ok := a.jobAgent.(jobLogRangeGetter)
+	if !ok {
+		return nil, errors.New("job agent cannot get ranges of pod logs")
+	}
+	if err := a.opts.limiter.acquire(a.ctx); err != nil {
+		return nil, fmt.Errorf("error waiting to fetch pod log: %w", err)
+	}
+	if err := a.opts.breaker.allow(); err != nil {
+		a.opts.limiter.release()
+		return nil, err
+	}
+	logs, err := getter.GetJobLogRange(a.name, a.buildID, a.container, start, end)
+	a.opts.limiter.release()
+	a.opts.breaker.record(err)
+	a.recordBytesRead(len(logs))
+	return logs, err
+}
+
+// readsRanges returns true if ranges of the pod log can be read from the job
+// agent without fetching the whole log. This requires the job agent to report
+// the size of pod logs, and the log must not be normalized, as that shifts
+// the offsets.
+func (a *PodLogArtifact) readsRanges() bool {
+	if a.previous || a.splitsStream() || a.opts.normalizes() {
+		return false
+	}
+	_, sizes := a.jobAgent.(jobLogSizer)
+	_, ranges := a.jobAgent.(jobLogRangeGetter)
+	return sizes && ranges
+}
+
+// cachesLog returns true if the pod log is cached, which is only the case once
+// the job has completed, as the logs of running jobs change.
+func (a *PodLogArtifact) cachesLog() bool {
+	if a.opts.cache == nil {
+		return false
+	}
+	pj, err := a.jobAgent.GetProwJob(a.name, a.buildID)
+	return err == nil && pj.Complete()
+}
+
+func (a *PodLogArtifact) cacheKey() logCacheKey {
+	return logCacheKey{
+		job:       a.name,
+		buildID:   a.buildID,
+		container: a.container,
+		previous:  a.previous,
+		stream:    a.stream,
+	}
+}
+
+// splitsStream returns true if a single output stream of the container is read.
+// If the job agent is not able to separate the streams, the combined output is
+// read instead.
+func (a *PodLogArtifact) splitsStream() bool {
+	if a.stream == LogStreamAll || a.previous {
+		return false
+	}
+	_, ok := a.jobAgent.(splitStreamJobLogGetter)
+	return ok
+}
+
+// recordBytesRead accounts for n bytes read from the job agent.
+func (a *PodLogArtifact) recordBytesRead(n int) {
+	if n <= 0 {
+		return
+	}
+	atomic.AddInt64(&a.bytesRead, int64(n))
+	podLogBytesRead.WithLabelValues(a.container).Add(float64(n))
+}
+
+// BytesRead returns the total number of bytes read from the job agent for
+// this artifact so far. Every read that is not streamed transfers the whole
+// log, regardless of the range or size that is returned to the caller.
+func (a *PodLogArtifact) BytesRead() int64 {
+	return atomic.LoadInt64(&a.bytesRead)
+}
+
+// header returns the synthetic header line describing the container, the pod
+// and the time of the fetch.
+func (a *PodLogArtifact) header() []byte {
+	pod := "unknown"
+	if pj, err := a.jobAgent.GetProwJob(a.name, a.buildID); err == nil && pj.Status.PodName != "" {
+		pod = pj.Status.PodName
+	}
+	return []byte(fmt.Sprintf("# container %q of pod %q, fetched at %s\n", a.container, pod, time.Now().UTC().Format(time.RFC3339)))
+}
+
+// NewReader returns a reader over the pod log. If the job agent supports
+// streaming, the log is read from the backend in chunks of at most the
+// configured read buffer size rather than being loaded into memory at once,
+// unless it is cached.
+// The caller must close the returned reader.
+func (a *PodLogArtifact) NewReader() (io.ReadCloser, error) {
+	var rc io.ReadCloser
+	if streamer, ok := a.jobAgent.(jobLogStreamer); ok && !a.previous && !a.splitsStream() && !a.cachesLog() {
+		if err := a.opts.limiter.acquire(a.ctx); err != nil {
+			return nil, fmt.Errorf("error waiting to stream pod log: %w", err)
+		}
+		if err := a.opts.breaker.allow(); err != nil {
+			a.opts.limiter.release()
+			return nil, err
+		}
+		stream, err := streamer.GetJobLogStream(a.ctx, a.name, a.buildID, a.container)
+		a.opts.breaker.record(err)
+		if err != nil {
+			a.opts.limiter.release()
+			return nil, fmt.Errorf("error streaming pod log: %w", err)
+		}
+		rc = &countingReader{ReadCloser: &releasingReadCloser{ReadCloser: stream, limiter: a.opts.limiter}, artifact: a}
+		if a.opts.normalizes() {
+			rc = &normalizingReader{br: bufio.NewReader(rc), Closer: rc, opts: a.opts}
+		}
+		if a.opts.header {
+			rc = &multiReadCloser{Reader: io.MultiReader(bytes.NewReader(a.header()), rc), Closer: rc}
+		}
+	} else {
+		logs, err := a.getLog()
+		if err != nil {
+			return nil, fmt.Errorf("error getting pod log: %w", err)
+		}
+		rc = io.NopCloser(bytes.NewReader(logs))
+	}
+	return &chunkedReader{ReadCloser: rc, chunkSize: a.opts.readBufferSize}, nil
+}
+
+// countingReader records the bytes read from the underlying reader for artifact.
+type countingReader struct {
+	io.ReadCloser
+	artifact *PodLogArtifact
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.artifact.recordBytesRead(n)
+	return n, err
+}
+
+// multiReadCloser reads from Reader and closes Closer.
+type multiReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// normalizingReader applies the normalizations of opts to every line read
+// from br and closes Closer.
+type normalizingReader struct {
+	br *bufio.Reader
+	io.Closer
+	opts    podLogOptions
+	pending []byte
+	err     error
+}
+
+func (r *normalizingReader) Read(p []byte) (int, error) {
+	for len(r.pending) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		var line []byte
+		line, r.err = r.br.ReadBytes('\n')
+		r.pending = r.opts.normalize(line)
+	}
+	n := copy(p, r.pending)
+	r.pending = r.pending[n:]
+	return n, nil
+}
+
+// chunkedReader limits every read from the underlying reader to chunkSize bytes.
+type chunkedReader struct {
+	io.ReadCloser
+	chunkSize int
+}
+
+func (r *chunkedReader) Read(p []byte) (int, error) {
+	if r.chunkSize > 0 && len(p) > r.chunkSize {
+		p = p[:r.chunkSize]
+	}
+	return r.ReadCloser.Read(p)
+}
+
 // ReadAt implements reading a range of bytes from the pod logs endpoint
 func (a *PodLogArtifact) ReadAt(p []byte, off int64) (n int, err error) {
 	if int64(len(p)) > a.sizeLimit {
 		return 0, lenses.ErrRequestSizeTooLarge
 	}
-	logs, err := a.jobAgent.GetJobLog(a.name, a.buildID, a.container)
+	logs, err := a.getLog()
 	if err != nil {
 		return 0, fmt.Errorf("error getting pod log: %w", err)
 	}
@@ -122,7 +477,7 @@ func (a *PodLogArtifact) ReadAll() ([]byte, error) {
 	if size > a.sizeLimit {
 		return nil, lenses.ErrFileTooLarge
 	}
-	logs, err := a.jobAgent.GetJobLog(a.name, a.buildID, a.container)
+	logs, err := a.getLog()
 	if err != nil {
 		return nil, fmt.Errorf("error getting pod log: %w", err)
 	}
@@ -134,7 +489,7 @@ func (a *PodLogArtifact) ReadAtMost(n int64) ([]byte, error) {
 	if n > a.sizeLimit {
 		return nil, lenses.ErrRequestSizeTooLarge
 	}
-	logs, err := a.jobAgent.GetJobLog(a.name, a.buildID, a.container)
+	logs, err := a.getLog()
 	if err != nil {
 		return nil, fmt.Errorf("error getting pod log: %w", err)
 	}
@@ -160,7 +515,7 @@ func (a *PodLogArtifact) ReadTail(n int64) ([]byte, error) {
 	if n > a.sizeLimit {
 		return nil, lenses.ErrRequestSizeTooLarge
 	}
-	logs, err := a.jobAgent.GetJobLog(a.name, a.buildID, a.container)
+	logs, err := a.getLog()
 	if err != nil {
 		return nil, fmt.Errorf("error getting pod log tail: %w", err)
 	}
@@ -181,7 +536,7 @@ func (a *PodLogArtifact) ReadTail(n int64) ([]byte, error) {
 
 // Size gets the size of the pod log. Note: this function makes the same network call as reading the entire file.
 func (a *PodLogArtifact) Size() (int64, error) {
-	logs, err := a.jobAgent.GetJobLog(a.name, a.buildID, a.container)
+	logs, err := a.getLog()
 	if err != nil {
 		return 0, fmt.Errorf("error getting size of pod log: %w", err)
 	}
@@ -17,38 +17,238 @@ limitations under the License.
 package spyglass
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"errors"
 	"fmt"
+	"io"
+	"regexp"
 	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
 
+	"github.com/sirupsen/logrus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/utils/clock"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/deck/jobs"
 	"sigs.k8s.io/prow/pkg/kube"
 	"sigs.k8s.io/prow/pkg/spyglass/api"
 	"sigs.k8s.io/prow/pkg/spyglass/lenses/common"
 )
 
-const singleLogName = "build-log.txt"
+const (
+	singleLogName = "build-log.txt"
+	// previousLogPrefix prefixes the names of the logs of previous containers.
+	previousLogPrefix = "previous-"
+
+	// defaultPodLogReadBufferSize is the default number of bytes read from
+	// the backend at a time when streaming a pod log.
+	defaultPodLogReadBufferSize = 32 * 1024
+
+	// lineNumberWidth is the minimum width of the line numbers in the gutter
+	// of numbered pod logs.
+	lineNumberWidth = 6
+)
 
 // PodLogArtifactFetcher is used to fetch artifacts from k8s apiserver
 type PodLogArtifactFetcher struct {
 	jobAgent
+	opts podLogOptions
+}
+
+// podLogOptions holds the optional settings of a PodLogArtifactFetcher,
+// which are passed on to every PodLogArtifact it constructs.
+type podLogOptions struct {
+	// readBufferSize bounds the number of bytes read from the backend at a time.
+	readBufferSize int
+	// header enables prepending a synthetic header line to pod logs.
+	header bool
+	// containerSizeLimits overrides the size limit of the logs of the
+	// containers with the given names.
+	containerSizeLimits map[string]int64
+	// normalizeLineEndings enables replacing CRLF and stray CR line endings with LF.
+	normalizeLineEndings bool
+	// stripANSI enables removing ANSI escape sequences from pod logs.
+	stripANSI bool
+	// breaker is shared by all artifacts of a fetcher to stop fetching pod
+	// logs while the job agent is failing. It is nil if disabled.
+	breaker *circuitBreaker
+	// cache is shared by all artifacts of a fetcher to hold the pod logs of
+	// completed jobs. It is nil if disabled.
+	cache *logCache
+	// limiter is shared by all artifacts of a fetcher to bound the number of
+	// concurrent fetches from the job agent. It is nil if unbounded.
+	limiter *fetchLimiter
+}
+
+// ansiEscapeRe matches ANSI control sequences, e.g. color codes.
+var ansiEscapeRe = regexp.MustCompile(`\x1b\[[0-9;?]*[ -/]*[@-~]`)
+
+// normalizes returns true if the logs are modified by normalize.
+func (o podLogOptions) normalizes() bool {
+	return o.normalizeLineEndings || o.stripANSI
+}
+
+// normalize applies the enabled normalizations to the given log content.
+func (o podLogOptions) normalize(b []byte) []byte {
+	if o.normalizeLineEndings {
+		b = bytes.ReplaceAll(b, []byte("\r\n"), []byte("\n"))
+		b = bytes.ReplaceAll(b, []byte("\r"), []byte("\n"))
+	}
+	if o.stripANSI {
+		b = ansiEscapeRe.ReplaceAll(b, nil)
+	}
+	return b
+}
+
+// PodLogArtifactFetcherOpt configures a PodLogArtifactFetcher.
+type PodLogArtifactFetcherOpt func(*podLogOptions)
+
+// WithReadBufferSize sets the number of bytes read from the backend at a time
+// when streaming pod logs. Non-positive sizes are ignored.
+func WithReadBufferSize(size int) PodLogArtifactFetcherOpt {
+	return func(o *podLogOptions) {
+		if size > 0 {
+			o.readBufferSize = size
+		}
+	}
+}
+
+// WithHeader prepends a header line describing the container, the pod and the
+// time of the fetch to every pod log. The header counts towards the size of
+// the log, including for the size limit of the artifact.
+func WithHeader() PodLogArtifactFetcherOpt {
+	return func(o *podLogOptions) {
+		o.header = true
+	}
+}
+
+// WithNormalizedLineEndings replaces CRLF and stray CR line endings in pod
+// logs with LF as they are read.
+func WithNormalizedLineEndings() PodLogArtifactFetcherOpt {
+	return func(o *podLogOptions) {
+		o.normalizeLineEndings = true
+	}
+}
+
+// WithoutANSIEscapes removes ANSI escape sequences, e.g. color codes, from pod
+// logs as they are read.
+func WithoutANSIEscapes() PodLogArtifactFetcherOpt {
+	return func(o *podLogOptions) {
+		o.stripANSI = true
+	}
+}
+
+// WithContainerSizeLimits sets the size limits of the logs of the containers
+// with the given names, overriding the size limit requested for the artifact.
+// Non-positive size limits are ignored.
+func WithContainerSizeLimits(limits map[string]int64) PodLogArtifactFetcherOpt {
+	return func(o *podLogOptions) {
+		for container, limit := range limits {
+			if limit <= 0 {
+				continue
+			}
+			if o.containerSizeLimits == nil {
+				o.containerSizeLimits = map[string]int64{}
+			}
+			o.containerSizeLimits[container] = limit
+		}
+	}
+}
+
+// WithCircuitBreaker stops fetching pod logs from the job agent for the
+// cooldown once threshold consecutive fetches failed because the job agent was
+// unavailable, failing them with a BackendUnavailableError instead. Errors like
+// a missing pod do not count as failures. After the cooldown a single fetch
+// probes whether the job agent recovered. Non-positive thresholds are ignored.
+func WithCircuitBreaker(threshold int, cooldown time.Duration) PodLogArtifactFetcherOpt {
+	return func(o *podLogOptions) {
+		if threshold > 0 {
+			o.breaker = &circuitBreaker{threshold: threshold, cooldown: cooldown, clock: clock.RealClock{}}
+		}
+	}
+}
+
+// WithCompletedLogCache caches the pod logs of completed jobs for the ttl, as
+// they never change. The pod logs of running jobs are never cached, so that
+// they are fetched from the job agent again once the job completed. Cached
+// logs are not streamed. At most maxBytes of logs are cached, dropping the
+// least recently used logs first. Non-positive ttls and sizes are ignored.
+func WithCompletedLogCache(ttl time.Duration, maxBytes int64) PodLogArtifactFetcherOpt {
+	return func(o *podLogOptions) {
+		if ttl > 0 && maxBytes > 0 {
+			o.cache = newLogCache(ttl, maxBytes)
+		}
+	}
+}
+
+// WithMaxConcurrentFetches bounds the number of fetches of pod logs from the
+// job agent that are in flight at a time. Further fetches block until a fetch
+// completes or their context is done. Streamed pod logs count as in flight
+// until their reader is closed, and size lookups count like fetches. Non-positive limits are ignored.
+func WithMaxConcurrentFetches(limit int) PodLogArtifactFetcherOpt {
+	return func(o *podLogOptions) {
+		if limit > 0 {
+			o.limiter = newFetchLimiter(limit)
+		}
+	}
 }
 
 // NewPodLogArtifactFetcher returns a PodLogArtifactFetcher using the given job agent as storage
-func NewPodLogArtifactFetcher(ja jobAgent) *PodLogArtifactFetcher {
-	return &PodLogArtifactFetcher{jobAgent: ja}
+func NewPodLogArtifactFetcher(ja jobAgent, opts ...PodLogArtifactFetcherOpt) *PodLogArtifactFetcher {
+	o := podLogOptions{
+		readBufferSize: defaultPodLogReadBufferSize,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return &PodLogArtifactFetcher{jobAgent: ja, opts: o}
 }
 
 // artifact constructs an artifact handle for the given job build
-func (af *PodLogArtifactFetcher) Artifact(_ context.Context, key, artifactName string, sizeLimit int64) (api.Artifact, error) {
+func (af *PodLogArtifactFetcher) Artifact(ctx context.Context, key, artifactName string, sizeLimit int64) (api.Artifact, error) {
+	podLog, err := af.podLogArtifact(ctx, key, artifactName, sizeLimit)
+	if err != nil {
+		// Return an untyped nil, as a nil *PodLogArtifact is a non-nil api.Artifact.
+		return nil, err
+	}
+	return podLog, nil
+}
+
+// Status returns the state of the ProwJob of the job build with the given key.
+func (af *PodLogArtifactFetcher) Status(_ context.Context, key string) (prowapi.ProwJobState, error) {
+	jobName, buildID, err := common.KeyToJob(key)
+	if err != nil {
+		return "", fmt.Errorf("could not derive job: %w", err)
+	}
+	pj, err := af.GetProwJob(jobName, buildID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get prow job from key %q: %w", key, err)
+	}
+	return pj.Status.State, nil
+}
+
+// podLogArtifact constructs a pod log artifact for the given job build
+func (af *PodLogArtifactFetcher) podLogArtifact(ctx context.Context, key, artifactName string, sizeLimit int64) (*PodLogArtifact, error) {
 	jobName, buildID, err := common.KeyToJob(key)
 	if err != nil {
 		return nil, fmt.Errorf("could not derive job: %w", err)
 	}
 	containerName := containerName(artifactName)
+	if limit, ok := af.opts.containerSizeLimits[containerName]; ok {
+		sizeLimit = limit
+	}
 	podLog, err := NewPodLogArtifact(jobName, buildID, artifactName, containerName, sizeLimit, af.jobAgent)
 	if err != nil {
 		return nil, fmt.Errorf("error accessing pod log from given source: %w", err)
 	}
+	podLog.opts = af.opts
+	podLog.ctx = ctx
 	return podLog, nil
 }
 
@@ -58,3 +258,592 @@ func containerName(artifactName string) string {
 	}
 	return strings.TrimSuffix(artifactName, fmt.Sprintf("-%s", singleLogName))
 }
+
+// ArtifactForStream returns the given pod log artifact restricted to a single
+// output stream of the container, e.g. stderr, which is often where the errors
+// are, or to the combined output for LogStreamAll. If the job agent is not
+// able to separate the streams, the artifact holds the combined output.
+func (af *PodLogArtifactFetcher) ArtifactForStream(ctx context.Context, key, artifactName string, stream LogStream, sizeLimit int64) (api.Artifact, error) {
+	switch stream {
+	case LogStreamAll, LogStreamStdout, LogStreamStderr:
+	default:
+		return nil, fmt.Errorf("unknown log stream %q", stream)
+	}
+	podLog, err := af.podLogArtifact(ctx, key, artifactName, sizeLimit)
+	if err != nil {
+		return nil, err
+	}
+	podLog.stream = stream
+	return podLog, nil
+}
+
+// RestartArtifacts returns the given pod log artifact followed by the log of
+// the previous instance of its container, if the container restarted and the
+// job agent is able to provide that log. The previous log is named after the
+// given artifact with a "previous-" prefix.
+func (af *PodLogArtifactFetcher) RestartArtifacts(ctx context.Context, key, artifactName string, sizeLimit int64) ([]api.Artifact, error) {
+	current, err := af.podLogArtifact(ctx, key, artifactName, sizeLimit)
+	if err != nil {
+		return nil, err
+	}
+	artifacts := []api.Artifact{current}
+
+	if _, ok := af.jobAgent.(previousJobLogGetter); !ok {
+		return artifacts, nil
+	}
+	previous := *current
+	previous.artifactName = previousLogPrefix + artifactName
+	previous.previous = true
+	// There is no cheap way to find out whether a previous instance exists,
+	// so we try to get its log and omit it if that fails. The log is kept so
+	// that reading the artifact does not fetch it again.
+	logs, err := previous.getRawLog()
+	if err != nil {
+		logrus.WithError(err).WithField("artifact", artifactName).Debug("No previous pod log available")
+		return artifacts, nil
+	}
+	previous.rawLog = logs
+	return append(artifacts, &previous), nil
+}
+
+// PodLogBatch holds the contents of several pod log artifacts of a job build.
+type PodLogBatch struct {
+	// Contents maps the names of the artifacts to their contents.
+	Contents map[string][]byte
+	// Duplicates maps the names of artifacts that are omitted from Contents
+	// because of deduplication to the name of the artifact with identical
+	// contents.
+	Duplicates map[string]string
+}
+
+// ReadAllArtifacts reads all of the given pod log artifacts of a job build,
+// failing if any of them is too large. If dedupe is true, artifacts whose
+// contents are identical to those of an earlier artifact are only listed in
+// the Duplicates of the result, so that their contents are transferred once.
+func (af *PodLogArtifactFetcher) ReadAllArtifacts(ctx context.Context, key string, artifactNames []string, sizeLimit int64, dedupe bool) (*PodLogBatch, error) {
+	batch := &PodLogBatch{
+		Contents:   map[string][]byte{},
+		Duplicates: map[string]string{},
+	}
+	seen := map[[sha256.Size]byte]string{}
+	for _, artifactName := range artifactNames {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		podLog, err := af.podLogArtifact(ctx, key, artifactName, sizeLimit)
+		if err != nil {
+			return nil, err
+		}
+		contents, err := podLog.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", artifactName, err)
+		}
+		if dedupe {
+			sum := sha256.Sum256(contents)
+			if original, ok := seen[sum]; ok {
+				batch.Duplicates[artifactName] = original
+				continue
+			}
+			seen[sum] = artifactName
+		}
+		batch.Contents[artifactName] = contents
+	}
+	return batch, nil
+}
+
+// MissingAttemptArtifactError is returned if an attempt of a job lacks the
+// requested pod log artifact, e.g. because its pod was deleted.
+type MissingAttemptArtifactError struct {
+	// Key is the key of the build of the attempt.
+	Key          string
+	ArtifactName string
+	Err          error
+}
+
+func (e *MissingAttemptArtifactError) Error() string {
+	return fmt.Sprintf("attempt %s lacks artifact %s: %v", e.Key, e.ArtifactName, e.Err)
+}
+
+func (e *MissingAttemptArtifactError) Unwrap() error {
+	return e.Err
+}
+
+// isNotFound returns whether err reports that the pod or ProwJob of a pod log
+// does not exist.
+func isNotFound(err error) bool {
+	if apierrors.IsNotFound(err) {
+		return true
+	}
+	for ; err != nil; err = errors.Unwrap(err) {
+		if jobs.IsErrProwJobNotFound(err) {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadAttempts reads the given pod log artifact of two attempts of the same
+// job, e.g. a failed and a passed build of a flaky job, so that the caller can
+// diff them. The attempts are identified by the keys of their builds. If the
+// pod or ProwJob of an attempt does not exist, a MissingAttemptArtifactError is
+// returned. Other errors are returned as they are.
+func (af *PodLogArtifactFetcher) ReadAttempts(ctx context.Context, key, otherKey, artifactName string, sizeLimit int64) (log, otherLog []byte, err error) {
+	jobName, _, err := common.KeyToJob(key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not derive job: %w", err)
+	}
+	otherJobName, _, err := common.KeyToJob(otherKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not derive job: %w", err)
+	}
+	if jobName != otherJobName {
+		return nil, nil, fmt.Errorf("builds %s and %s are not attempts of the same job", key, otherKey)
+	}
+	logs := make([][]byte, 2)
+	for i, k := range []string{key, otherKey} {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+		podLog, err := af.podLogArtifact(ctx, k, artifactName, sizeLimit)
+		if err != nil {
+			return nil, nil, err
+		}
+		contents, err := podLog.ReadAll()
+		if isNotFound(err) {
+			return nil, nil, &MissingAttemptArtifactError{Key: k, ArtifactName: artifactName, Err: err}
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("error reading %s of attempt %s: %w", artifactName, k, err)
+		}
+		logs[i] = contents
+	}
+	return logs[0], logs[1], nil
+}
+
+// Grep returns the lines of the given pod log artifact that match pattern,
+// streaming the log rather than loading it into memory at once. At most
+// maxMatches lines are returned, unless maxMatches is not positive.
+func (af *PodLogArtifactFetcher) Grep(ctx context.Context, key, artifactName, pattern string, maxMatches int) ([]byte, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+	podLog, err := af.podLogArtifact(ctx, key, artifactName, 0)
+	if err != nil {
+		return nil, err
+	}
+	// Only the lines of the log itself are matched.
+	podLog.opts.header = false
+	r, err := podLog.NewReader()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	var matches bytes.Buffer
+	var matched int
+	err = forEachLine(ctx, r, func(line []byte) bool {
+		if !re.Match(bytes.TrimSuffix(line, []byte("\n"))) {
+			return true
+		}
+		matches.Write(line)
+		matched++
+		return maxMatches <= 0 || matched < maxMatches
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error reading pod log: %w", err)
+	}
+	return matches.Bytes(), nil
+}
+
+// defaultErrorPatterns match the lines of pod logs that typically mark the
+// first error, i.e. Go panics, failed tests and error messages.
+var defaultErrorPatterns = []string{`\bpanic\b`, `\bFAIL\b`, `Error:`}
+
+// FirstErrorContext returns the first line of the given pod log artifact that
+// matches any of patterns, along with up to before lines preceding it and up to
+// after lines following it, streaming the log rather than loading it into
+// memory at once. If patterns is empty, lines containing "panic", "FAIL" or
+// "Error:" are matched. If no line matches, nil is returned.
+func (af *PodLogArtifactFetcher) FirstErrorContext(ctx context.Context, key, artifactName string, before, after int, patterns []string) ([]byte, error) {
+	if len(patterns) == 0 {
+		patterns = defaultErrorPatterns
+	}
+	res := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		res = append(res, re)
+	}
+	podLog, err := af.podLogArtifact(ctx, key, artifactName, 0)
+	if err != nil {
+		return nil, err
+	}
+	// Only the lines of the log itself are matched.
+	podLog.opts.header = false
+	r, err := podLog.NewReader()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	// preceding holds the last before lines until a line matches, after which
+	// the window is collected in it.
+	var preceding [][]byte
+	matched, following := false, 0
+	err = forEachLine(ctx, r, func(line []byte) bool {
+		if matched {
+			preceding = append(preceding, line)
+			following++
+			return following < after
+		}
+		text := bytes.TrimSuffix(line, []byte("\n"))
+		for _, re := range res {
+			if re.Match(text) {
+				matched = true
+				preceding = append(preceding, line)
+				return after > 0
+			}
+		}
+		if before > 0 {
+			if len(preceding) == before {
+				preceding = preceding[1:]
+			}
+			preceding = append(preceding, line)
+		}
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error reading pod log: %w", err)
+	}
+	if !matched {
+		return nil, nil
+	}
+	return bytes.Join(preceding, nil), nil
+}
+
+// ReadTruncated reads at most the first n bytes of the given pod log artifact.
+// If the log is longer, it is truncated at the last UTF-8 character boundary
+// before n bytes, so that a multi-byte character is not split.
+func (af *PodLogArtifactFetcher) ReadTruncated(ctx context.Context, key, artifactName string, n int64) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	podLog, err := af.podLogArtifact(ctx, key, artifactName, 0)
+	if err != nil {
+		return nil, err
+	}
+	r, err := podLog.NewReader()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	// Read a byte past n to find out whether the log is truncated.
+	b, err := io.ReadAll(io.LimitReader(r, n+1))
+	if err != nil {
+		return nil, fmt.Errorf("error reading pod log: %w", err)
+	}
+	if int64(len(b)) <= n {
+		return b, nil
+	}
+	return truncateUTF8(b[:n]), nil
+}
+
+// ReadNumbered reads at most the first maxSize bytes of the given pod log
+// artifact with every line prefixed by its 1-based line number in a
+// fixed-width gutter, e.g. "     1 | ", so that lines can be linked to. The
+// gutter counts towards maxSize. If the numbered log is longer, it is
+// truncated like by ReadTruncated. The other methods return the raw log.
+func (af *PodLogArtifactFetcher) ReadNumbered(ctx context.Context, key, artifactName string, maxSize int64) ([]byte, error) {
+	podLog, err := af.podLogArtifact(ctx, key, artifactName, 0)
+	if err != nil {
+		return nil, err
+	}
+	// Only the lines of the log itself are numbered.
+	podLog.opts.header = false
+	r, err := podLog.NewReader()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	var numbered bytes.Buffer
+	var number int
+	err = forEachLine(ctx, r, func(line []byte) bool {
+		number++
+		fmt.Fprintf(&numbered, "%*d | ", lineNumberWidth, number)
+		numbered.Write(line)
+		// Stop reading once the log is known to be truncated.
+		return int64(numbered.Len()) <= maxSize
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error reading pod log: %w", err)
+	}
+	b := numbered.Bytes()
+	if int64(len(b)) <= maxSize {
+		return b, nil
+	}
+	return truncateUTF8(b[:maxSize]), nil
+}
+
+// EndCursor is the cursor of the end of a pod log, from which ReadPageBefore
+// starts paging backward.
+const EndCursor int64 = -1
+
+// ReadPageBefore reads the page of at most pageSize bytes of the given pod log
+// artifact that ends at the byte offset cursor and returns it along with the
+// cursor of the preceding page, so that a large log can be paged through
+// backward from its tail, e.g. for infinite scrolling. Paging starts at
+// EndCursor and has reached the start of the log once the returned cursor is
+// 0. A page does not start within a multi-byte UTF-8 character unless it is
+// smaller than the character.
+// The offsets exclude the header, so that they remain valid while the log of a
+// running job grows. The logs of completed jobs are served from the completed
+// log cache if enabled, and only the page is fetched if the job agent can
+// report the size of pod logs and return ranges of them. Otherwise, every page
+// transfers the whole log from the job agent.
+func (af *PodLogArtifactFetcher) ReadPageBefore(ctx context.Context, key, artifactName string, cursor, pageSize int64) (page []byte, prev int64, err error) {
+	if pageSize <= 0 {
+		return nil, 0, fmt.Errorf("page size must be positive, got %d", pageSize)
+	}
+	if cursor < EndCursor {
+		return nil, 0, fmt.Errorf("invalid cursor %d", cursor)
+	}
+	podLog, err := af.podLogArtifact(ctx, key, artifactName, 0)
+	if err != nil {
+		return nil, 0, err
+	}
+	podLog.opts.header = false
+
+	var size int64
+	var readRange func(start, end int64) ([]byte, error)
+	if !podLog.cachesLog() && podLog.readsRanges() {
+		if size, err = podLog.rawSize(); err != nil {
+			return nil, 0, err
+		}
+		readRange = podLog.getRawLogRange
+	} else {
+		logs, err := podLog.getLog()
+		if err != nil {
+			return nil, 0, fmt.Errorf("error getting pod log: %w", err)
+		}
+		size = int64(len(logs))
+		readRange = func(start, end int64) ([]byte, error) {
+			return logs[start:end], nil
+		}
+	}
+	if cursor == EndCursor {
+		cursor = size
+	}
+	if cursor > size {
+		return nil, 0, fmt.Errorf("cursor %d is past the end of the pod log of %d bytes", cursor, size)
+	}
+	start := max(cursor-pageSize, 0)
+	if page, err = readRange(start, cursor); err != nil {
+		return nil, 0, fmt.Errorf("error getting pod log: %w", err)
+	}
+	for start > 0 && len(page) > 1 && !utf8.RuneStart(page[0]) {
+		page = page[1:]
+		start++
+	}
+	return page, start, nil
+}
+
+// UnknownSize is the size returned for artifacts whose size cannot be
+// reported without fetching their content.
+const UnknownSize int64 = -1
+
+// Size returns the size in bytes of the given pod log artifact without
+// fetching the log, e.g. to warn before a huge log is downloaded. The size is
+// only known if the log of the completed job is cached or the job agent
+// reports the sizes of pod logs, and UnknownSize is returned otherwise. The
+// size excludes the header and is that of the log before normalization.
+func (af *PodLogArtifactFetcher) Size(ctx context.Context, key, artifactName string) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	podLog, err := af.podLogArtifact(ctx, key, artifactName, 0)
+	if err != nil {
+		return 0, err
+	}
+	return podLog.rawSize()
+}
+
+// truncateUTF8 returns b without a trailing incomplete UTF-8 character.
+func truncateUTF8(b []byte) []byte {
+	for i := len(b) - 1; i >= 0 && i >= len(b)-utf8.UTFMax; i-- {
+		if !utf8.RuneStart(b[i]) {
+			continue
+		}
+		if !utf8.FullRune(b[i:]) {
+			return b[:i]
+		}
+		break
+	}
+	return b
+}
+
+// Stream returns a reader over the given pod log that is fed from the backend
+// by a separate goroutine through an io.Pipe. The goroutine only reads from the
+// backend as fast as the returned reader is consumed, so a slow consumer
+// applies backpressure rather than the log being buffered. The goroutine stops
+// once the log is exhausted, ctx is done or the returned reader is closed, in
+// which case pending reads fail with the error of ctx. The backend is closed
+// right away in the latter cases, so that a read of the goroutine that is
+// blocked on the backend returns. The caller must close the returned reader.
+func (af *PodLogArtifactFetcher) Stream(ctx context.Context, key, artifactName string) (io.ReadCloser, error) {
+	podLog, err := af.podLogArtifact(ctx, key, artifactName, 0)
+	if err != nil {
+		return nil, err
+	}
+	src, err := podLog.NewReader()
+	if err != nil {
+		return nil, err
+	}
+	closeSrc := sync.OnceValue(src.Close)
+
+	pr, pw := io.Pipe()
+	stop := context.AfterFunc(ctx, func() {
+		// The pipe is closed first, so that pending reads fail with the error
+		// of ctx rather than the error of the closed backend.
+		pw.CloseWithError(ctx.Err())
+		closeSrc()
+	})
+	go func() {
+		defer stop()
+		defer closeSrc()
+		_, err := io.CopyBuffer(pw, src, make([]byte, af.opts.readBufferSize))
+		// A nil error makes the reader return io.EOF.
+		pw.CloseWithError(err)
+	}()
+	return &streamReader{PipeReader: pr, closeSrc: closeSrc}, nil
+}
+
+// streamReader is the reader returned by Stream, which closes the backend
+// along with the pipe.
+type streamReader struct {
+	*io.PipeReader
+	closeSrc func() error
+}
+
+func (r *streamReader) Close() error {
+	r.PipeReader.Close()
+	return r.closeSrc()
+}
+
+// LogLine is a line of a pod log along with the metadata parsed from it.
+type LogLine struct {
+	// Number is the 1-based number of the line within the log.
+	Number int
+	// Container is the name of the container that wrote the line.
+	Container string
+	// Timestamp is the time at which the line was written, if the line is
+	// prefixed with an RFC 3339 timestamp. It is zero otherwise.
+	Timestamp time.Time
+	// Stream is "stdout" or "stderr" if the line is in the CRI log format,
+	// which distinguishes the stream that the line was written to. It is
+	// empty otherwise.
+	Stream string
+	// Text is the content of the line without the parsed metadata and the
+	// trailing newline.
+	Text string
+}
+
+// Lines streams the lines of the given pod log along with the metadata parsed
+// from them. The returned line channel is closed once the log is exhausted or
+// ctx is done, after which the returned error channel yields the error that
+// stopped the stream, if any.
+func (af *PodLogArtifactFetcher) Lines(ctx context.Context, key, artifactName string) (<-chan LogLine, <-chan error) {
+	lines := make(chan LogLine)
+	errs := make(chan error, 1)
+	go func() {
+		defer close(errs)
+		defer close(lines)
+		podLog, err := af.podLogArtifact(ctx, key, artifactName, 0)
+		if err != nil {
+			errs <- err
+			return
+		}
+		// Only the lines of the log itself are parsed.
+		podLog.opts.header = false
+		r, err := podLog.NewReader()
+		if err != nil {
+			errs <- err
+			return
+		}
+		defer r.Close()
+
+		var number int
+		err = forEachLine(ctx, r, func(line []byte) bool {
+			number++
+			select {
+			case lines <- parseLogLine(number, podLog.container, line):
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		})
+		if err == nil {
+			err = ctx.Err()
+		}
+		if err != nil {
+			errs <- fmt.Errorf("error reading pod log: %w", err)
+		}
+	}()
+	return lines, errs
+}
+
+// parseLogLine parses a line of a pod log. A line may be prefixed with the
+// timestamp added by the apiserver, e.g. "2024-01-01T00:00:00.123Z text", or
+// be in the CRI log format, e.g. "2024-01-01T00:00:00.123Z stderr F text".
+func parseLogLine(number int, container string, line []byte) LogLine {
+	l := LogLine{
+		Number:    number,
+		Container: container,
+		Text:      strings.TrimSuffix(string(line), "\n"),
+	}
+	timestamp, rest, ok := strings.Cut(l.Text, " ")
+	if !ok {
+		return l
+	}
+	t, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err != nil {
+		return l
+	}
+	l.Timestamp, l.Text = t, rest
+	stream, rest, ok := strings.Cut(rest, " ")
+	if !ok || (stream != "stdout" && stream != "stderr") {
+		return l
+	}
+	// The tag marks whether the line is full or partial.
+	tag, text, _ := strings.Cut(rest, " ")
+	if tag != "F" && tag != "P" {
+		return l
+	}
+	l.Stream, l.Text = stream, text
+	return l
+}
+
+// forEachLine calls fn with every line read from r, including its trailing
+// newline if any, until fn returns false, r is exhausted or ctx is done.
+func forEachLine(ctx context.Context, r io.Reader, fn func(line []byte) bool) error {
+	br := bufio.NewReader(r)
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		line, err := br.ReadBytes('\n')
+		if len(line) > 0 && !fn(line) {
+			return nil
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
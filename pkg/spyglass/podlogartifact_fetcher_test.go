@@ -19,10 +19,24 @@ package spyglass
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"regexp"
+	"sync"
 	"testing"
+	"time"
+	"unicode/utf8"
 
+	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	prowapi "sigs.k8s.io/prow/pkg/apis/prowjobs/v1"
+	"sigs.k8s.io/prow/pkg/deck/jobs"
 	"sigs.k8s.io/prow/pkg/kube"
+	"sigs.k8s.io/prow/pkg/spyglass/lenses"
 )
 
 // Tests getting handles to objects associated with the current Prow job
@@ -102,3 +116,1333 @@ func TestFetchArtifacts_Prow(t *testing.T) {
 
 	}
 }
+
+// recordingReader records the size of every read request it receives.
+type recordingReader struct {
+	io.Reader
+	readSizes []int
+}
+
+func (r *recordingReader) Read(p []byte) (int, error) {
+	r.readSizes = append(r.readSizes, len(p))
+	return r.Reader.Read(p)
+}
+
+func (r *recordingReader) Close() error {
+	return nil
+}
+
+// fakeStreamingJAgent serves pod logs through a recordingReader.
+type fakeStreamingJAgent struct {
+	fakePodLogJAgent
+	log    []byte
+	reader *recordingReader
+}
+
+func (j *fakeStreamingJAgent) GetJobLogStream(_ context.Context, job, id, container string) (io.ReadCloser, error) {
+	j.reader = &recordingReader{Reader: bytes.NewReader(j.log)}
+	return j.reader, nil
+}
+
+func TestPodLogArtifactNewReader(t *testing.T) {
+	log := bytes.Repeat([]byte("0123456789"), 100)
+	testCases := []struct {
+		name          string
+		opts          []PodLogArtifactFetcherOpt
+		expectedChunk int
+	}{
+		{
+			name:          "default buffer size",
+			expectedChunk: defaultPodLogReadBufferSize,
+		},
+		{
+			name:          "configured buffer size",
+			opts:          []PodLogArtifactFetcherOpt{WithReadBufferSize(64)},
+			expectedChunk: 64,
+		},
+		{
+			name:          "non-positive buffer size is ignored",
+			opts:          []PodLogArtifactFetcherOpt{WithReadBufferSize(0)},
+			expectedChunk: defaultPodLogReadBufferSize,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			agent := &fakeStreamingJAgent{log: log}
+			artifact, err := NewPodLogArtifactFetcher(agent, tc.opts...).Artifact(context.Background(), "BFG/435", singleLogName, 500e6)
+			if err != nil {
+				t.Fatalf("failed to get artifact: %v", err)
+			}
+			r, err := artifact.(*PodLogArtifact).NewReader()
+			if err != nil {
+				t.Fatalf("failed to get reader: %v", err)
+			}
+			defer r.Close()
+			res, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("failed to read pod log: %v", err)
+			}
+			if !bytes.Equal(log, res) {
+				t.Errorf("unexpected pod log, expected %q, got %q", log, res)
+			}
+			for _, size := range agent.reader.readSizes {
+				if size > tc.expectedChunk {
+					t.Errorf("read of %d bytes exceeds the buffer size of %d", size, tc.expectedChunk)
+				}
+			}
+		})
+	}
+}
+
+func TestPodLogArtifactFetcherGrep(t *testing.T) {
+	log := []byte("=== RUN TestA\n--- FAIL: TestA\n=== RUN TestB\npanic: boom\n--- FAIL: TestC")
+	testCases := []struct {
+		name       string
+		pattern    string
+		maxMatches int
+		expected   []byte
+		expectErr  bool
+	}{
+		{
+			name:     "matching lines are returned",
+			pattern:  "FAIL|panic",
+			expected: []byte("--- FAIL: TestA\npanic: boom\n--- FAIL: TestC"),
+		},
+		{
+			name:    "no matching lines",
+			pattern: "PASS",
+		},
+		{
+			name:       "matches are capped",
+			pattern:    "FAIL|panic",
+			maxMatches: 2,
+			expected:   []byte("--- FAIL: TestA\npanic: boom\n"),
+		},
+		{
+			name:      "invalid pattern",
+			pattern:   "(",
+			expectErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fetcher := NewPodLogArtifactFetcher(&fakeStreamingJAgent{log: log})
+			res, err := fetcher.Grep(context.Background(), "BFG/435", singleLogName, tc.pattern, tc.maxMatches)
+			if (err != nil) != tc.expectErr {
+				t.Fatalf("expected error: %t, got: %v", tc.expectErr, err)
+			}
+			if !bytes.Equal(tc.expected, res) {
+				t.Errorf("unexpected matches, expected %q, got %q", tc.expected, res)
+			}
+		})
+	}
+}
+
+func TestPodLogArtifactFetcherFirstErrorContext(t *testing.T) {
+	log := []byte("=== RUN TestA\n--- PASS: TestA\n=== RUN TestB\n    b_test.go:12: unexpected result\n--- FAIL: TestB\n=== RUN TestC\npanic: boom\ngoroutine 1 [running]:")
+	testCases := []struct {
+		name          string
+		log           []byte
+		before, after int
+		patterns      []string
+		expected      []byte
+		expectErr     bool
+	}{
+		{
+			name:     "window around the first match of the default patterns",
+			log:      log,
+			before:   2,
+			after:    1,
+			expected: []byte("=== RUN TestB\n    b_test.go:12: unexpected result\n--- FAIL: TestB\n=== RUN TestC\n"),
+		},
+		{
+			name:     "window is cut at the boundaries of the log",
+			log:      log,
+			before:   10,
+			after:    10,
+			patterns: []string{"^panic:"},
+			expected: log,
+		},
+		{
+			name:     "only the matching line without context",
+			log:      log,
+			patterns: []string{"^panic:"},
+			expected: []byte("panic: boom\n"),
+		},
+		{
+			name:     "match on the last line without a newline",
+			log:      log,
+			before:   1,
+			after:    3,
+			patterns: []string{"running"},
+			expected: []byte("panic: boom\ngoroutine 1 [running]:"),
+		},
+		{
+			name:   "no matching line",
+			log:    []byte("=== RUN TestA\n--- PASS: TestA\nPASS\n"),
+			before: 2,
+			after:  2,
+		},
+		{
+			name:      "invalid pattern",
+			log:       log,
+			patterns:  []string{"("},
+			expectErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fetcher := NewPodLogArtifactFetcher(&fakeStreamingJAgent{log: tc.log})
+			res, err := fetcher.FirstErrorContext(context.Background(), "BFG/435", singleLogName, tc.before, tc.after, tc.patterns)
+			if (err != nil) != tc.expectErr {
+				t.Fatalf("expected error: %t, got: %v", tc.expectErr, err)
+			}
+			if !bytes.Equal(tc.expected, res) {
+				t.Errorf("unexpected context, expected %q, got %q", tc.expected, res)
+			}
+		})
+	}
+}
+
+func TestPodLogArtifactFetcherLines(t *testing.T) {
+	log := []byte("plain line\n" +
+		"2024-01-01T00:00:00.123456789Z timestamped line\n" +
+		"2024-01-01T00:00:01Z stderr F full line\n" +
+		"2024-01-01T00:00:02Z stdout P partial line\n" +
+		"2024-01-01T00:00:03Z stdout F\n" +
+		"not-a-timestamp stderr F text\n" +
+		"2024-01-01T00:00:04Z other F text")
+	expected := []LogLine{
+		{Number: 1, Container: "test", Text: "plain line"},
+		{Number: 2, Container: "test", Timestamp: time.Date(2024, 1, 1, 0, 0, 0, 123456789, time.UTC), Text: "timestamped line"},
+		{Number: 3, Container: "test", Timestamp: time.Date(2024, 1, 1, 0, 0, 1, 0, time.UTC), Stream: "stderr", Text: "full line"},
+		{Number: 4, Container: "test", Timestamp: time.Date(2024, 1, 1, 0, 0, 2, 0, time.UTC), Stream: "stdout", Text: "partial line"},
+		{Number: 5, Container: "test", Timestamp: time.Date(2024, 1, 1, 0, 0, 3, 0, time.UTC), Stream: "stdout"},
+		{Number: 6, Container: "test", Text: "not-a-timestamp stderr F text"},
+		{Number: 7, Container: "test", Timestamp: time.Date(2024, 1, 1, 0, 0, 4, 0, time.UTC), Text: "other F text"},
+	}
+
+	fetcher := NewPodLogArtifactFetcher(&fakeStreamingJAgent{log: log})
+	lines, errs := fetcher.Lines(context.Background(), "BFG/435", singleLogName)
+	var actual []LogLine
+	for line := range lines {
+		actual = append(actual, line)
+	}
+	if err := <-errs; err != nil {
+		t.Fatalf("unexpected error streaming lines: %v", err)
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Errorf("unexpected lines (-expected +actual):\n%s", diff)
+	}
+}
+
+func TestPodLogArtifactFetcherLinesCanceled(t *testing.T) {
+	fetcher := NewPodLogArtifactFetcher(&fakeStreamingJAgent{log: []byte("a\nb\nc\n")})
+	ctx, cancel := context.WithCancel(context.Background())
+	lines, errs := fetcher.Lines(ctx, "BFG/435", singleLogName)
+	if line := <-lines; line.Text != "a" {
+		t.Errorf("expected the first line, got %q", line.Text)
+	}
+	cancel()
+	for range lines {
+	}
+	if err := <-errs; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the stream to be canceled, got: %v", err)
+	}
+}
+
+func TestPodLogArtifactFetcherReadTruncated(t *testing.T) {
+	log := []byte("héllo wörld ✓")
+	testCases := []struct {
+		name     string
+		n        int64
+		expected []byte
+	}{
+		{
+			name:     "truncated exactly at a character boundary",
+			n:        3,
+			expected: []byte("hé"),
+		},
+		{
+			name:     "two byte character is not split",
+			n:        2,
+			expected: []byte("h"),
+		},
+		{
+			name:     "three byte character is not split",
+			n:        16,
+			expected: []byte("héllo wörld "),
+		},
+		{
+			name:     "log of exactly n bytes is not truncated",
+			n:        int64(len(log)),
+			expected: log,
+		},
+		{
+			name:     "short log is not truncated",
+			n:        100,
+			expected: log,
+		},
+		{
+			name:     "nothing is read",
+			expected: []byte{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fetcher := NewPodLogArtifactFetcher(&fakeStreamingJAgent{log: log})
+			res, err := fetcher.ReadTruncated(context.Background(), "BFG/435", singleLogName, tc.n)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !bytes.Equal(tc.expected, res) {
+				t.Errorf("unexpected log, expected %q, got %q", tc.expected, res)
+			}
+			if !utf8.Valid(res) {
+				t.Errorf("truncated log %q is not valid UTF-8", res)
+			}
+		})
+	}
+}
+
+func TestPodLogArtifactFetcherReadNumbered(t *testing.T) {
+	log := []byte("first\nsecond\nthird\n")
+	testCases := []struct {
+		name     string
+		log      []byte
+		maxSize  int64
+		expected []byte
+	}{
+		{
+			name:     "every line is numbered",
+			log:      log,
+			maxSize:  100,
+			expected: []byte("     1 | first\n     2 | second\n     3 | third\n"),
+		},
+		{
+			name:     "last line without newline is numbered",
+			log:      []byte("first\nsecond"),
+			maxSize:  100,
+			expected: []byte("     1 | first\n     2 | second"),
+		},
+		{
+			name:     "gutter counts towards the size",
+			log:      log,
+			maxSize:  int64(len("     1 | first\n     2 | second\n")),
+			expected: []byte("     1 | first\n     2 | second\n"),
+		},
+		{
+			name:     "numbering continues across the truncation boundary",
+			log:      log,
+			maxSize:  int64(len("     1 | first\n     2 | second\n     3 | th")),
+			expected: []byte("     1 | first\n     2 | second\n     3 | th"),
+		},
+		{
+			name:     "truncation within the gutter",
+			log:      log,
+			maxSize:  int64(len("     1 | first\n    ")),
+			expected: []byte("     1 | first\n    "),
+		},
+		{
+			name:     "empty log",
+			maxSize:  100,
+			expected: []byte{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fetcher := NewPodLogArtifactFetcher(&fakeStreamingJAgent{log: tc.log})
+			res, err := fetcher.ReadNumbered(context.Background(), "BFG/435", singleLogName, tc.maxSize)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if int64(len(res)) > tc.maxSize {
+				t.Errorf("expected at most %d bytes, got %d", tc.maxSize, len(res))
+			}
+			if !bytes.Equal(tc.expected, res) {
+				t.Errorf("unexpected numbered log, expected %q, got %q", tc.expected, res)
+			}
+		})
+	}
+}
+
+// fakeAttemptsJAgent serves the pod logs of several builds of a job by build ID.
+type fakeAttemptsJAgent struct {
+	fakePodLogJAgent
+	logs map[string]string
+	errs map[string]error
+}
+
+func (j *fakeAttemptsJAgent) GetJobLog(job, id, container string) ([]byte, error) {
+	if err, ok := j.errs[id]; ok {
+		return nil, err
+	}
+	log, ok := j.logs[id]
+	if !ok {
+		return nil, apierrors.NewNotFound(schema.GroupResource{Resource: "pods"}, id)
+	}
+	return []byte(log), nil
+}
+
+func TestPodLogArtifactFetcherReadAttempts(t *testing.T) {
+	_, prowJobNotFound := (&jobs.JobAgent{}).GetProwJob("flaky-job", "4")
+	agent := &fakeAttemptsJAgent{
+		logs: map[string]string{
+			"1": "=== RUN TestFlaky\n--- FAIL: TestFlaky\n",
+			"2": "=== RUN TestFlaky\n--- PASS: TestFlaky\n",
+		},
+		errs: map[string]error{
+			"4": fmt.Errorf("error getting prowjob: %w", prowJobNotFound),
+			"5": apierrors.NewServiceUnavailable("apiserver unavailable"),
+		},
+	}
+	testCases := []struct {
+		name             string
+		key, otherKey    string
+		sizeLimit        int64
+		expectedLog      string
+		expectedOtherLog string
+		expectMissing    string
+		expectErr        bool
+	}{
+		{
+			name:             "differing attempts",
+			key:              "flaky-job/1",
+			otherKey:         "flaky-job/2",
+			sizeLimit:        500e6,
+			expectedLog:      "=== RUN TestFlaky\n--- FAIL: TestFlaky\n",
+			expectedOtherLog: "=== RUN TestFlaky\n--- PASS: TestFlaky\n",
+		},
+		{
+			name:          "attempt lacking the artifact",
+			key:           "flaky-job/1",
+			otherKey:      "flaky-job/3",
+			sizeLimit:     500e6,
+			expectMissing: "flaky-job/3",
+			expectErr:     true,
+		},
+		{
+			name:          "attempt lacking the ProwJob",
+			key:           "flaky-job/4",
+			otherKey:      "flaky-job/2",
+			sizeLimit:     500e6,
+			expectMissing: "flaky-job/4",
+			expectErr:     true,
+		},
+		{
+			name:      "unavailable backend",
+			key:       "flaky-job/1",
+			otherKey:  "flaky-job/5",
+			sizeLimit: 500e6,
+			expectErr: true,
+		},
+		{
+			name:      "builds of different jobs",
+			key:       "flaky-job/1",
+			otherKey:  "other-job/2",
+			sizeLimit: 500e6,
+			expectErr: true,
+		},
+		{
+			name:      "artifact too large",
+			key:       "flaky-job/1",
+			otherKey:  "flaky-job/2",
+			sizeLimit: 10,
+			expectErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fetcher := NewPodLogArtifactFetcher(agent)
+			log, otherLog, err := fetcher.ReadAttempts(context.Background(), tc.key, tc.otherKey, singleLogName, tc.sizeLimit)
+			if (err != nil) != tc.expectErr {
+				t.Fatalf("expected error: %t, got: %v", tc.expectErr, err)
+			}
+			var missing *MissingAttemptArtifactError
+			if errors.As(err, &missing) != (tc.expectMissing != "") {
+				t.Errorf("expected missing artifact error: %t, got: %v", tc.expectMissing != "", err)
+			} else if missing != nil && missing.Key != tc.expectMissing {
+				t.Errorf("expected attempt %s to lack the artifact, got %s", tc.expectMissing, missing.Key)
+			}
+			if string(log) != tc.expectedLog {
+				t.Errorf("unexpected log, expected %q, got %q", tc.expectedLog, log)
+			}
+			if string(otherLog) != tc.expectedOtherLog {
+				t.Errorf("unexpected log of the other attempt, expected %q, got %q", tc.expectedOtherLog, otherLog)
+			}
+		})
+	}
+}
+
+// fakeRestartingJAgent serves the logs of previous containers for the test container only.
+type fakeRestartingJAgent struct {
+	fakePodLogJAgent
+	previousGets int
+}
+
+func (j *fakeRestartingJAgent) GetPreviousJobLog(job, id, container string) ([]byte, error) {
+	j.previousGets++
+	if job == "BFG" && id == "435" && container == kube.TestContainerName {
+		return []byte("whizzpopper"), nil
+	}
+	return nil, fmt.Errorf("no previous container %s for job %s, id %s", container, job, id)
+}
+
+// fakeSplitStreamJAgent separates the output streams of containers.
+type fakeSplitStreamJAgent struct {
+	fakeStreamingJAgent
+}
+
+func (j *fakeSplitStreamJAgent) GetJobLogOfStream(job, id, container string, stream LogStream) ([]byte, error) {
+	return []byte(fmt.Sprintf("%s of %s", stream, container)), nil
+}
+
+func TestPodLogArtifactFetcherArtifactForStream(t *testing.T) {
+	combined := []byte("combined output")
+	testCases := []struct {
+		name      string
+		agent     jobAgent
+		stream    LogStream
+		expected  []byte
+		expectErr bool
+	}{
+		{
+			name:     "stderr only",
+			agent:    &fakeSplitStreamJAgent{fakeStreamingJAgent{log: combined}},
+			stream:   LogStreamStderr,
+			expected: []byte("stderr of test"),
+		},
+		{
+			name:     "stdout only",
+			agent:    &fakeSplitStreamJAgent{fakeStreamingJAgent{log: combined}},
+			stream:   LogStreamStdout,
+			expected: []byte("stdout of test"),
+		},
+		{
+			name:     "combined",
+			agent:    &fakeSplitStreamJAgent{fakeStreamingJAgent{log: combined}},
+			stream:   LogStreamAll,
+			expected: combined,
+		},
+		{
+			name:     "agent without support for separate streams degrades to combined",
+			agent:    &fakeStreamingJAgent{log: combined},
+			stream:   LogStreamStderr,
+			expected: combined,
+		},
+		{
+			name:      "unknown stream",
+			agent:     &fakeSplitStreamJAgent{fakeStreamingJAgent{log: combined}},
+			stream:    "stdin",
+			expectErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fetcher := NewPodLogArtifactFetcher(tc.agent)
+			art, err := fetcher.ArtifactForStream(context.Background(), "BFG/435", singleLogName, tc.stream, 500e6)
+			if (err != nil) != tc.expectErr {
+				t.Fatalf("expected error: %t, got: %v", tc.expectErr, err)
+			}
+			if err != nil {
+				return
+			}
+			r, err := art.(*PodLogArtifact).NewReader()
+			if err != nil {
+				t.Fatalf("failed to get reader: %v", err)
+			}
+			defer r.Close()
+			res, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("failed to read artifact: %v", err)
+			}
+			if !bytes.Equal(tc.expected, res) {
+				t.Errorf("unexpected content, expected %q, got %q", tc.expected, res)
+			}
+		})
+	}
+}
+
+func TestPodLogArtifactFetcherRestartArtifacts(t *testing.T) {
+	testCases := []struct {
+		name     string
+		agent    jobAgent
+		artifact string
+		expected map[string][]byte
+		// expectedPreviousGets is the number of times the previous log is
+		// fetched from a fakeRestartingJAgent.
+		expectedPreviousGets int
+	}{
+		{
+			name:     "container with a previous instance",
+			agent:    &fakeRestartingJAgent{},
+			artifact: singleLogName,
+			expected: map[string][]byte{
+				singleLogName:                     []byte("frobscottle"),
+				previousLogPrefix + singleLogName: []byte("whizzpopper"),
+			},
+			expectedPreviousGets: 1,
+		},
+		{
+			name:     "container without a previous instance",
+			agent:    &fakeRestartingJAgent{},
+			artifact: fmt.Sprintf("%s-%s", customContainerName, singleLogName),
+			expected: map[string][]byte{
+				fmt.Sprintf("%s-%s", customContainerName, singleLogName): []byte("snozzcumber"),
+			},
+			expectedPreviousGets: 1,
+		},
+		{
+			name:     "agent without support for previous instances",
+			agent:    &fakePodLogJAgent{},
+			artifact: singleLogName,
+			expected: map[string][]byte{
+				singleLogName: []byte("frobscottle"),
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			artifacts, err := NewPodLogArtifactFetcher(tc.agent).RestartArtifacts(context.Background(), "BFG/435", tc.artifact, 500e6)
+			if err != nil {
+				t.Fatalf("failed to get artifacts: %v", err)
+			}
+			if len(artifacts) != len(tc.expected) {
+				t.Fatalf("expected %d artifacts, got %d", len(tc.expected), len(artifacts))
+			}
+			if artifacts[0].JobPath() != tc.artifact {
+				t.Errorf("expected the current log %q first, got %q", tc.artifact, artifacts[0].JobPath())
+			}
+			for _, artifact := range artifacts {
+				res, err := artifact.ReadAll()
+				if err != nil {
+					t.Fatalf("failed to read %s: %v", artifact.JobPath(), err)
+				}
+				if expected := tc.expected[artifact.JobPath()]; !bytes.Equal(expected, res) {
+					t.Errorf("unexpected content of %s, expected %q, got %q", artifact.JobPath(), expected, res)
+				}
+			}
+			if agent, ok := tc.agent.(*fakeRestartingJAgent); ok && agent.previousGets != tc.expectedPreviousGets {
+				t.Errorf("expected the previous log to be fetched %d times, got %d", tc.expectedPreviousGets, agent.previousGets)
+			}
+		})
+	}
+}
+
+// fakePodNameJAgent reports the name of the pod of every ProwJob.
+type fakePodNameJAgent struct {
+	fakeStreamingJAgent
+}
+
+func (j *fakePodNameJAgent) GetProwJob(job, id string) (prowapi.ProwJob, error) {
+	return prowapi.ProwJob{Status: prowapi.ProwJobStatus{PodName: "bfg-pod"}}, nil
+}
+
+func TestPodLogArtifactFetcherHeader(t *testing.T) {
+	headerRe := regexp.MustCompile(`^# container "test" of pod "bfg-pod", fetched at \S+\n`)
+	testCases := []struct {
+		name         string
+		opts         []PodLogArtifactFetcherOpt
+		sizeLimit    int64
+		expectErr    error
+		expectHeader bool
+	}{
+		{
+			name:      "no header by default",
+			sizeLimit: 500e6,
+		},
+		{
+			name:         "header when enabled",
+			opts:         []PodLogArtifactFetcherOpt{WithHeader()},
+			sizeLimit:    500e6,
+			expectHeader: true,
+		},
+		{
+			name:      "header counts towards the size limit",
+			opts:      []PodLogArtifactFetcherOpt{WithHeader()},
+			sizeLimit: int64(len("frobscottle")),
+			expectErr: lenses.ErrFileTooLarge,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			agent := &fakePodNameJAgent{fakeStreamingJAgent{log: []byte("frobscottle")}}
+			artifact, err := NewPodLogArtifactFetcher(agent, tc.opts...).Artifact(context.Background(), "BFG/435", singleLogName, tc.sizeLimit)
+			if err != nil {
+				t.Fatalf("failed to get artifact: %v", err)
+			}
+			res, err := artifact.ReadAll()
+			if !errors.Is(err, tc.expectErr) {
+				t.Fatalf("expected error %v, got %v", tc.expectErr, err)
+			}
+			if err != nil {
+				return
+			}
+			r, err := artifact.(*PodLogArtifact).NewReader()
+			if err != nil {
+				t.Fatalf("failed to get reader: %v", err)
+			}
+			defer r.Close()
+			streamed, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("failed to read pod log: %v", err)
+			}
+			for _, log := range [][]byte{res, streamed} {
+				if headerRe.Match(log) != tc.expectHeader {
+					t.Errorf("expected header: %t, got %q", tc.expectHeader, log)
+				}
+				if body := headerRe.ReplaceAll(log, nil); string(body) != "frobscottle" {
+					t.Errorf("expected the pod log to follow the header, got %q", log)
+				}
+			}
+		})
+	}
+}
+
+func TestPodLogArtifactFetcherContainerSizeLimits(t *testing.T) {
+	fetcher := NewPodLogArtifactFetcher(&fakePodLogJAgent{}, WithContainerSizeLimits(map[string]int64{
+		kube.TestContainerName: 5,
+		customContainerName:    0,
+		"verbose":              1e9,
+	}))
+	testCases := []struct {
+		name      string
+		artifact  string
+		sizeLimit int64
+		expected  int64
+	}{
+		{
+			name:      "container limit overrides the requested limit",
+			artifact:  singleLogName,
+			sizeLimit: 500e6,
+			expected:  5,
+		},
+		{
+			name:      "non-positive container limit is ignored",
+			artifact:  fmt.Sprintf("%s-%s", customContainerName, singleLogName),
+			sizeLimit: 500e6,
+			expected:  500e6,
+		},
+		{
+			name:      "container limit may exceed the requested limit",
+			artifact:  fmt.Sprintf("verbose-%s", singleLogName),
+			sizeLimit: 500e6,
+			expected:  1e9,
+		},
+		{
+			name:      "container without a limit",
+			artifact:  fmt.Sprintf("other-%s", singleLogName),
+			sizeLimit: 100,
+			expected:  100,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			artifact, err := fetcher.Artifact(context.Background(), "BFG/435", tc.artifact, tc.sizeLimit)
+			if err != nil {
+				t.Fatalf("failed to get artifact: %v", err)
+			}
+			if sizeLimit := artifact.(*PodLogArtifact).sizeLimit; sizeLimit != tc.expected {
+				t.Errorf("expected size limit %d, got %d", tc.expected, sizeLimit)
+			}
+		})
+	}
+}
+
+// fakeRawLogJAgent serves the same pod log with and without streaming.
+type fakeRawLogJAgent struct {
+	fakeStreamingJAgent
+}
+
+func (j *fakeRawLogJAgent) GetJobLog(job, id, container string) ([]byte, error) {
+	return j.log, nil
+}
+
+func TestPodLogArtifactFetcherReadPageBefore(t *testing.T) {
+	log := []byte("first line\nsecond line\nthird line\n")
+	fetcher := NewPodLogArtifactFetcher(&fakeRawLogJAgent{fakeStreamingJAgent{log: log}}, WithHeader())
+
+	var pages [][]byte
+	cursor := EndCursor
+	for i := 0; i <= len(log); i++ {
+		page, prev, err := fetcher.ReadPageBefore(context.Background(), "BFG/435", singleLogName, cursor, 10)
+		if err != nil {
+			t.Fatalf("failed to read page before %d: %v", cursor, err)
+		}
+		pages = append([][]byte{page}, pages...)
+		if prev == 0 {
+			break
+		}
+		if prev >= cursor && cursor != EndCursor {
+			t.Fatalf("cursor did not move backward from %d to %d", cursor, prev)
+		}
+		cursor = prev
+	}
+	if len(pages) != 4 {
+		t.Errorf("expected 4 pages, got %d: %q", len(pages), pages)
+	}
+	for _, page := range pages {
+		if len(page) > 10 {
+			t.Errorf("page %q exceeds the page size", page)
+		}
+	}
+	if joined := bytes.Join(pages, nil); !bytes.Equal(joined, log) {
+		t.Errorf("expected the pages to make up the log %q, got %q", log, joined)
+	}
+
+	page, prev, err := fetcher.ReadPageBefore(context.Background(), "BFG/435", singleLogName, 0, 10)
+	if err != nil {
+		t.Fatalf("failed to read page before the start of the log: %v", err)
+	}
+	if len(page) != 0 || prev != 0 {
+		t.Errorf("expected an empty page at the start of the log, got %q with cursor %d", page, prev)
+	}
+}
+
+func TestPodLogArtifactFetcherReadPageBeforeErrors(t *testing.T) {
+	log := []byte("0123456789")
+	testCases := []struct {
+		name     string
+		cursor   int64
+		pageSize int64
+	}{
+		{
+			name:     "cursor past the end of the log",
+			cursor:   11,
+			pageSize: 5,
+		},
+		{
+			name:     "negative cursor",
+			cursor:   -2,
+			pageSize: 5,
+		},
+		{
+			name:     "non-positive page size",
+			cursor:   EndCursor,
+			pageSize: 0,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fetcher := NewPodLogArtifactFetcher(&fakeRawLogJAgent{fakeStreamingJAgent{log: log}})
+			if _, _, err := fetcher.ReadPageBefore(context.Background(), "BFG/435", singleLogName, tc.cursor, tc.pageSize); err == nil {
+				t.Error("expected an error, got none")
+			}
+		})
+	}
+}
+
+func TestPodLogArtifactFetcherReadPageBeforeUTF8(t *testing.T) {
+	log := []byte("aé€b")
+	fetcher := NewPodLogArtifactFetcher(&fakeRawLogJAgent{fakeStreamingJAgent{log: log}})
+	var pages []string
+	cursor := EndCursor
+	for cursor != 0 {
+		page, prev, err := fetcher.ReadPageBefore(context.Background(), "BFG/435", singleLogName, cursor, 3)
+		if err != nil {
+			t.Fatalf("failed to read page before %d: %v", cursor, err)
+		}
+		pages = append(pages, string(page))
+		cursor = prev
+	}
+	if diff := cmp.Diff([]string{"b", "€", "aé"}, pages); diff != "" {
+		t.Errorf("unexpected pages (-want +got):\n%s", diff)
+	}
+}
+
+// fakeRangeJAgent reports the size of pod logs and serves ranges of them. It
+// counts the fetches of whole logs and of ranges.
+type fakeRangeJAgent struct {
+	fakeRawLogJAgent
+	fullGets  int
+	rangeGets int
+}
+
+func (j *fakeRangeJAgent) GetJobLog(job, id, container string) ([]byte, error) {
+	j.fullGets++
+	return j.log, nil
+}
+
+func (j *fakeRangeJAgent) GetJobLogSize(job, id, container string) (int64, error) {
+	return int64(len(j.log)), nil
+}
+
+func (j *fakeRangeJAgent) GetJobLogRange(job, id, container string, start, end int64) ([]byte, error) {
+	j.rangeGets++
+	return j.log[start:end], nil
+}
+
+func TestPodLogArtifactFetcherReadPageBeforeRanges(t *testing.T) {
+	log := []byte("first line\nsecond line\nthird line\n")
+	agent := &fakeRangeJAgent{fakeRawLogJAgent: fakeRawLogJAgent{fakeStreamingJAgent{log: log}}}
+	fetcher := NewPodLogArtifactFetcher(agent)
+
+	var pages [][]byte
+	for cursor := EndCursor; cursor != 0; {
+		page, prev, err := fetcher.ReadPageBefore(context.Background(), "BFG/435", singleLogName, cursor, 10)
+		if err != nil {
+			t.Fatalf("failed to read page before %d: %v", cursor, err)
+		}
+		pages = append([][]byte{page}, pages...)
+		cursor = prev
+	}
+	if joined := bytes.Join(pages, nil); !bytes.Equal(joined, log) {
+		t.Errorf("expected the pages to make up the log %q, got %q", log, joined)
+	}
+	if agent.fullGets != 0 {
+		t.Errorf("expected the whole log not to be fetched, got %d fetches", agent.fullGets)
+	}
+	if agent.rangeGets != len(pages) {
+		t.Errorf("expected one range fetch per page, got %d for %d pages", agent.rangeGets, len(pages))
+	}
+}
+
+func TestPodLogArtifactFetcherReadPageBeforeCached(t *testing.T) {
+	agent := &fakeCompletingJAgent{complete: true}
+	fetcher := NewPodLogArtifactFetcher(agent, WithCompletedLogCache(time.Hour, 1<<20))
+
+	var pages []string
+	for cursor := EndCursor; cursor != 0; {
+		page, prev, err := fetcher.ReadPageBefore(context.Background(), "BFG/435", singleLogName, cursor, 2)
+		if err != nil {
+			t.Fatalf("failed to read page before %d: %v", cursor, err)
+		}
+		pages = append([]string{string(page)}, pages...)
+		cursor = prev
+	}
+	if diff := cmp.Diff([]string{"li", "ne", " 1"}, pages); diff != "" {
+		t.Errorf("unexpected pages (-want +got):\n%s", diff)
+	}
+	if agent.calls != 1 {
+		t.Errorf("expected the log to be fetched once, got %d fetches", agent.calls)
+	}
+}
+
+// fakeSizingJAgent reports the size of pod logs without serving them.
+type fakeSizingJAgent struct {
+	fakePodLogJAgent
+	size int64
+	err  error
+}
+
+func (j *fakeSizingJAgent) GetJobLogSize(job, id, container string) (int64, error) {
+	return j.size, j.err
+}
+
+func TestPodLogArtifactFetcherSize(t *testing.T) {
+	testCases := []struct {
+		name         string
+		agent        jobAgent
+		expectedSize int64
+		expectErr    bool
+	}{
+		{
+			name:         "size reported by the job agent",
+			agent:        &fakeSizingJAgent{size: 4096},
+			expectedSize: 4096,
+		},
+		{
+			name:         "unknown size if the job agent cannot report it",
+			agent:        &fakePodLogJAgent{},
+			expectedSize: UnknownSize,
+		},
+		{
+			name:      "error reporting the size",
+			agent:     &fakeSizingJAgent{err: errors.New("pod is gone")},
+			expectErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fetcher := NewPodLogArtifactFetcher(tc.agent)
+			size, err := fetcher.Size(context.Background(), "BFG/435", singleLogName)
+			if (err != nil) != tc.expectErr {
+				t.Fatalf("expected error: %t, got: %v", tc.expectErr, err)
+			}
+			if size != tc.expectedSize {
+				t.Errorf("expected size %d, got %d", tc.expectedSize, size)
+			}
+		})
+	}
+}
+
+func TestPodLogArtifactFetcherSizeCached(t *testing.T) {
+	agent := &fakeCompletingJAgent{complete: true}
+	fetcher := NewPodLogArtifactFetcher(agent, WithCompletedLogCache(time.Hour, 1<<20))
+
+	size, err := fetcher.Size(context.Background(), "BFG/435", singleLogName)
+	if err != nil {
+		t.Fatalf("failed to get size: %v", err)
+	}
+	if size != UnknownSize {
+		t.Errorf("expected an unknown size before the log is cached, got %d", size)
+	}
+
+	if _, err := fetcher.ReadTruncated(context.Background(), "BFG/435", singleLogName, 100); err != nil {
+		t.Fatalf("failed to read pod log: %v", err)
+	}
+	size, err = fetcher.Size(context.Background(), "BFG/435", singleLogName)
+	if err != nil {
+		t.Fatalf("failed to get size: %v", err)
+	}
+	if size != int64(len("line 1")) {
+		t.Errorf("expected the size %d of the cached log, got %d", len("line 1"), size)
+	}
+	if agent.calls != 1 {
+		t.Errorf("expected the log to be fetched once, got %d fetches", agent.calls)
+	}
+}
+
+func TestPodLogArtifactNormalization(t *testing.T) {
+	log := []byte("line one\r\n\x1b[1;31mred\x1b[0m\rprogress\n")
+	testCases := []struct {
+		name     string
+		opts     []PodLogArtifactFetcherOpt
+		expected []byte
+	}{
+		{
+			name:     "raw bytes by default",
+			expected: log,
+		},
+		{
+			name:     "line endings are normalized",
+			opts:     []PodLogArtifactFetcherOpt{WithNormalizedLineEndings()},
+			expected: []byte("line one\n\x1b[1;31mred\x1b[0m\nprogress\n"),
+		},
+		{
+			name:     "ANSI escapes are stripped",
+			opts:     []PodLogArtifactFetcherOpt{WithoutANSIEscapes()},
+			expected: []byte("line one\r\nred\rprogress\n"),
+		},
+		{
+			name:     "both normalizations",
+			opts:     []PodLogArtifactFetcherOpt{WithNormalizedLineEndings(), WithoutANSIEscapes()},
+			expected: []byte("line one\nred\nprogress\n"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			agent := &fakeRawLogJAgent{fakeStreamingJAgent{log: log}}
+			// A small buffer size exercises reads across line boundaries.
+			opts := append([]PodLogArtifactFetcherOpt{WithReadBufferSize(3)}, tc.opts...)
+			artifact, err := NewPodLogArtifactFetcher(agent, opts...).Artifact(context.Background(), "BFG/435", singleLogName, 500e6)
+			if err != nil {
+				t.Fatalf("failed to get artifact: %v", err)
+			}
+			res, err := artifact.ReadAll()
+			if err != nil {
+				t.Fatalf("failed to read pod log: %v", err)
+			}
+			if !bytes.Equal(tc.expected, res) {
+				t.Errorf("unexpected pod log, expected %q, got %q", tc.expected, res)
+			}
+			r, err := artifact.(*PodLogArtifact).NewReader()
+			if err != nil {
+				t.Fatalf("failed to get reader: %v", err)
+			}
+			defer r.Close()
+			streamed, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatalf("failed to stream pod log: %v", err)
+			}
+			if !bytes.Equal(tc.expected, streamed) {
+				t.Errorf("unexpected streamed pod log, expected %q, got %q", tc.expected, streamed)
+			}
+		})
+	}
+}
+
+// endlessReader serves an endless pod log and records how much of it was read.
+type endlessReader struct {
+	lock   sync.Mutex
+	read   int
+	closed chan struct{}
+}
+
+func (r *endlessReader) Read(p []byte) (int, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for i := range p {
+		p[i] = 'x'
+	}
+	r.read += len(p)
+	return len(p), nil
+}
+
+func (r *endlessReader) Close() error {
+	close(r.closed)
+	return nil
+}
+
+func (r *endlessReader) bytesRead() int {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.read
+}
+
+// fakeEndlessJAgent streams an endless pod log.
+type fakeEndlessJAgent struct {
+	fakePodLogJAgent
+	reader *endlessReader
+}
+
+func (j *fakeEndlessJAgent) GetJobLogStream(_ context.Context, job, id, container string) (io.ReadCloser, error) {
+	return j.reader, nil
+}
+
+func TestPodLogArtifactFetcherStream(t *testing.T) {
+	const bufferSize = 64
+	testCases := []struct {
+		name string
+		stop func(cancel context.CancelFunc, r io.ReadCloser)
+		// expectedErr is the error of reads after stopping, if any.
+		expectedErr error
+	}{
+		{
+			name: "closing the reader stops the producer",
+			stop: func(_ context.CancelFunc, r io.ReadCloser) {
+				r.Close()
+			},
+			expectedErr: io.ErrClosedPipe,
+		},
+		{
+			name: "cancelling the context stops the producer",
+			stop: func(cancel context.CancelFunc, _ io.ReadCloser) {
+				cancel()
+			},
+			expectedErr: context.Canceled,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			agent := &fakeEndlessJAgent{reader: &endlessReader{closed: make(chan struct{})}}
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			r, err := NewPodLogArtifactFetcher(agent, WithReadBufferSize(bufferSize)).Stream(ctx, "BFG/435", singleLogName)
+			if err != nil {
+				t.Fatalf("failed to stream pod log: %v", err)
+			}
+			defer r.Close()
+
+			// A slow reader only lets the producer read ahead by about one buffer.
+			p := make([]byte, 10)
+			var consumed int
+			for i := 0; i < 20; i++ {
+				n, err := r.Read(p)
+				if err != nil {
+					t.Fatalf("failed to read pod log: %v", err)
+				}
+				consumed += n
+				time.Sleep(time.Millisecond)
+			}
+			if read := agent.reader.bytesRead(); read > consumed+2*bufferSize {
+				t.Errorf("producer read %d bytes ahead of the %d consumed bytes", read-consumed, consumed)
+			}
+
+			tc.stop(cancel, r)
+			select {
+			case <-agent.reader.closed:
+			case <-time.After(10 * time.Second):
+				t.Fatal("producer did not stop")
+			}
+			if _, err := r.Read(p); !errors.Is(err, tc.expectedErr) {
+				t.Errorf("expected error %v after stopping, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+// blockingReader blocks reads until it is closed.
+type blockingReader struct {
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func (r *blockingReader) Read(p []byte) (int, error) {
+	<-r.closed
+	return 0, errors.New("read from closed stream")
+}
+
+func (r *blockingReader) Close() error {
+	r.closeOnce.Do(func() { close(r.closed) })
+	return nil
+}
+
+// fakeBlockingStreamJAgent streams pod logs that block until they are closed.
+type fakeBlockingStreamJAgent struct {
+	fakePodLogJAgent
+	lock    sync.Mutex
+	readers []*blockingReader
+}
+
+func (j *fakeBlockingStreamJAgent) GetJobLogStream(_ context.Context, job, id, container string) (io.ReadCloser, error) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	r := &blockingReader{closed: make(chan struct{})}
+	j.readers = append(j.readers, r)
+	return r, nil
+}
+
+func (j *fakeBlockingStreamJAgent) reader(i int) *blockingReader {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	return j.readers[i]
+}
+
+func TestPodLogArtifactFetcherStreamBlockedSource(t *testing.T) {
+	testCases := []struct {
+		name string
+		stop func(cancel context.CancelFunc, r io.ReadCloser)
+	}{
+		{
+			name: "closing the reader closes the blocked source",
+			stop: func(_ context.CancelFunc, r io.ReadCloser) {
+				r.Close()
+			},
+		},
+		{
+			name: "cancelling the context closes the blocked source",
+			stop: func(cancel context.CancelFunc, r io.ReadCloser) {
+				cancel()
+				r.Close()
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			agent := &fakeBlockingStreamJAgent{}
+			fetcher := NewPodLogArtifactFetcher(agent, WithMaxConcurrentFetches(1))
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			r, err := fetcher.Stream(ctx, "BFG/435", singleLogName)
+			if err != nil {
+				t.Fatalf("failed to stream pod log: %v", err)
+			}
+
+			tc.stop(cancel, r)
+			select {
+			case <-agent.reader(0).closed:
+			case <-time.After(10 * time.Second):
+				t.Fatal("blocked source was not closed")
+			}
+
+			// The slot of the stopped stream is released for the next one.
+			next, cancelNext := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancelNext()
+			r, err = fetcher.Stream(next, "BFG/435", singleLogName)
+			if err != nil {
+				t.Fatalf("failed to stream pod log after stopping the previous stream: %v", err)
+			}
+			r.Close()
+		})
+	}
+}
+
+// fakeDuplicateJAgent serves identical logs for the "init" and "sidecar" containers.
+type fakeDuplicateJAgent struct {
+	fakePodLogJAgent
+}
+
+func (j *fakeDuplicateJAgent) GetJobLog(job, id, container string) ([]byte, error) {
+	switch container {
+	case "init", "sidecar":
+		return []byte("cloning repos..."), nil
+	}
+	return j.fakePodLogJAgent.GetJobLog(job, id, container)
+}
+
+func TestPodLogArtifactFetcherReadAllArtifacts(t *testing.T) {
+	artifacts := []string{
+		fmt.Sprintf("init-%s", singleLogName),
+		singleLogName,
+		fmt.Sprintf("sidecar-%s", singleLogName),
+	}
+	testCases := []struct {
+		name               string
+		dedupe             bool
+		expectedContents   map[string][]byte
+		expectedDuplicates map[string]string
+	}{
+		{
+			name: "all artifacts without deduplication",
+			expectedContents: map[string][]byte{
+				fmt.Sprintf("init-%s", singleLogName):    []byte("cloning repos..."),
+				singleLogName:                            []byte("frobscottle"),
+				fmt.Sprintf("sidecar-%s", singleLogName): []byte("cloning repos..."),
+			},
+			expectedDuplicates: map[string]string{},
+		},
+		{
+			name:   "identical artifacts are deduplicated",
+			dedupe: true,
+			expectedContents: map[string][]byte{
+				fmt.Sprintf("init-%s", singleLogName): []byte("cloning repos..."),
+				singleLogName:                         []byte("frobscottle"),
+			},
+			expectedDuplicates: map[string]string{
+				fmt.Sprintf("sidecar-%s", singleLogName): fmt.Sprintf("init-%s", singleLogName),
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			batch, err := NewPodLogArtifactFetcher(&fakeDuplicateJAgent{}).ReadAllArtifacts(context.Background(), "BFG/435", artifacts, 500e6, tc.dedupe)
+			if err != nil {
+				t.Fatalf("failed to read artifacts: %v", err)
+			}
+			if diff := cmp.Diff(tc.expectedContents, batch.Contents); diff != "" {
+				t.Errorf("unexpected contents (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedDuplicates, batch.Duplicates); diff != "" {
+				t.Errorf("unexpected duplicates (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPodLogArtifactBytesRead(t *testing.T) {
+	log := bytes.Repeat([]byte("0123456789"), 10)
+	agent := &fakeRawLogJAgent{fakeStreamingJAgent{log: log}}
+	artifact, err := NewPodLogArtifactFetcher(agent).Artifact(context.Background(), "BFG/435", singleLogName, 500e6)
+	if err != nil {
+		t.Fatalf("failed to get artifact: %v", err)
+	}
+	podLog := artifact.(*PodLogArtifact)
+	metric := podLogBytesRead.WithLabelValues(kube.TestContainerName)
+	before := testutil.ToFloat64(metric)
+
+	if _, err := podLog.ReadAll(); err != nil {
+		t.Fatalf("failed to read pod log: %v", err)
+	}
+	// Size and ReadAll both transfer the whole log.
+	expected := int64(2 * len(log))
+	if podLog.BytesRead() != expected {
+		t.Errorf("expected %d bytes read after ReadAll, got %d", expected, podLog.BytesRead())
+	}
+
+	// Reading the tail still transfers the whole log.
+	if _, err := podLog.ReadTail(5); err != nil {
+		t.Fatalf("failed to read pod log tail: %v", err)
+	}
+	expected += int64(len(log))
+	if podLog.BytesRead() != expected {
+		t.Errorf("expected %d bytes read after ReadTail, got %d", expected, podLog.BytesRead())
+	}
+
+	r, err := podLog.NewReader()
+	if err != nil {
+		t.Fatalf("failed to get reader: %v", err)
+	}
+	defer r.Close()
+	if _, err := io.ReadAll(r); err != nil {
+		t.Fatalf("failed to stream pod log: %v", err)
+	}
+	expected += int64(len(log))
+	if podLog.BytesRead() != expected {
+		t.Errorf("expected %d bytes read after streaming, got %d", expected, podLog.BytesRead())
+	}
+
+	if read := testutil.ToFloat64(metric) - before; read != float64(expected) {
+		t.Errorf("expected the metric to count %d bytes, got %v", expected, read)
+	}
+}
@@ -220,6 +220,24 @@ func (af *StorageArtifactFetcher) Artifact(ctx context.Context, key string, arti
 	return NewStorageArtifact(context.Background(), obj, signedURL, artifactName, sizeLimit), nil
 }
 
+// Size returns the size in bytes of the given artifact from the attributes of
+// its object, without reading its content. The size of gzipped objects is
+// their compressed size, like that of StorageArtifact.
+func (af *StorageArtifactFetcher) Size(ctx context.Context, key, artifactName string) (int64, error) {
+	src, err := af.newStorageJobSource(key)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get GCS job source from %s: %w", key, err)
+	}
+
+	_, prefix := extractBucketPrefixPair(src.jobPath())
+	objName := path.Join(prefix, artifactName)
+	attrs, err := af.opener.Attributes(ctx, fmt.Sprintf("%s%s/%s", src.linkPrefix, src.bucket, objName))
+	if err != nil {
+		return 0, fmt.Errorf("error getting attributes of artifact %s: %w", artifactName, err)
+	}
+	return attrs.Size, nil
+}
+
 func extractBucketPrefixPair(storagePath string) (string, string) {
 	split := strings.SplitN(storagePath, "/", 2)
 	return split[0], split[1]
@@ -348,6 +348,39 @@ func TestFetchArtifacts_GCS(t *testing.T) {
 	}
 }
 
+func TestStorageArtifactFetcherSize(t *testing.T) {
+	cfg := createConfigGetter("test-bucket")
+	testAf := NewStorageArtifactFetcher(io.NewGCSOpener(fakeGCSServer.Client()), cfg, false)
+	testCases := []struct {
+		name         string
+		source       string
+		expectedSize int64
+		expectErr    bool
+	}{
+		{
+			name:         "size of build-log.txt from valid source",
+			source:       "gs://test-bucket/logs/example-ci-run/403",
+			expectedSize: 25,
+		},
+		{
+			name:      "size of build-log.txt from invalid source",
+			source:    "gs://test-bucket/logs/example-ci-run/404",
+			expectErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			size, err := testAf.Size(context.Background(), tc.source, "build-log.txt")
+			if (err != nil) != tc.expectErr {
+				t.Fatalf("expected error: %t, got: %v", tc.expectErr, err)
+			}
+			if size != tc.expectedSize {
+				t.Errorf("expected size %d, got %d", tc.expectedSize, size)
+			}
+		})
+	}
+}
+
 func TestSignURL(t *testing.T) {
 	// This fake key is revoked and thus worthless but still make its contents less obvious
 	fakeKeyBuf, err := base64.StdEncoding.DecodeString(`